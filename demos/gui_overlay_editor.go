@@ -89,6 +89,13 @@ func main() {
 		guiEditor.guiSystem.Update(mouseX, mouseY, newLeftClick)
 		guiEditor.guiSystem.SetCurrentMode(guiEditor.transformMode)
 		
+		// Apply the environment assigned in the editor
+		env := guiEditor.editor.GetEnvironment()
+		renderer.SetClearColor(env.ClearColor[0], env.ClearColor[1], env.ClearColor[2], env.ClearColor[3])
+		if err := renderer.SetSkybox(env.Skybox); err != nil {
+			fmt.Printf("Environment: %v\n", err)
+		}
+		
 		// Begin rendering
 		renderer.BeginFrame()
 		
@@ -98,11 +105,20 @@ func main() {
 			renderer.DrawGrid(lines, grid.Color)
 		}
 		
-		// Render 3D scene
+		// Render 3D scene in the view mode selected in the View menu
+		renderer.SetViewMode(guiEditor.editor.GetViewMode())
+		endScene := renderer.BeginPass("scene")
 		renderScene(renderer, guiEditor.editor)
+		endScene()
+		
+		// Debug primitives, such as the selected object's name label
+		renderer.FlushDebugDraw(0)
 		
 		// Render GUI overlay (includes stats table)
+		guiEditor.editor.SetFrameProfile(renderer.GetProfiler().Stats())
+		endGUI := renderer.BeginPass("gui")
 		guiEditor.guiSystem.Render()
+		endGUI()
 		
 		// End frame
 		renderer.EndFrame()
@@ -395,6 +411,7 @@ func selectObjectAtMousePos(editor *GUIOverlayEditor, mouseX, mouseY float64) in
 func renderScene(renderer *core.Renderer, editor *ui.Editor) {
 	objects := editor.GetSceneObjects()
 	selectedIndex := editor.GetSelectedObject()
+	drawn, culled := 0, 0
 	
 	for i, obj := range objects {
 		if !obj.Visible {
@@ -415,11 +432,24 @@ func renderScene(renderer *core.Renderer, editor *ui.Editor) {
 		model[5] = obj.Scale.Y   // Y scale
 		model[10] = obj.Scale.Z  // Z scale
 		
-		// Highlight selected object
+		// Highlight and label selected object
 		if i == selectedIndex {
 			highlightScale := bmath.NewScaleMatrix(1.05, 1.05, 1.05)
 			model = model.Multiply(highlightScale)
+			above := obj.Position.Add(bmath.NewVector3(0, obj.Scale.Y*0.5+0.25, 0))
+			renderer.GetDebugDraw().Text(above, obj.Name, [3]float32{1, 1, 0.4})
+		}
+		
+		// Skip objects outside the camera's view
+		meshType := obj.Type
+		if _, exists := renderer.GetMeshHandle(meshType); !exists {
+			meshType = "cube"
+		}
+		if !renderer.InFrustum(meshType, model) {
+			culled++
+			continue
 		}
+		drawn++
 		
 		// Render based on object type
 		switch obj.Type {
@@ -431,6 +461,10 @@ func renderScene(renderer *core.Renderer, editor *ui.Editor) {
 			renderer.DrawSphereWithTransform(model)
 		case "cylinder":
 			renderer.DrawCylinderWithTransform(model)
+		case "cone":
+			renderer.DrawConeWithTransform(model)
+		case "torus":
+			renderer.DrawTorusWithTransform(model)
 		case "plane":
 			renderer.DrawPlaneWithTransform(model)
 		case "pyramid":
@@ -439,6 +473,8 @@ func renderScene(renderer *core.Renderer, editor *ui.Editor) {
 			renderer.DrawCubeWithTransform(model)
 		}
 	}
+	
+	editor.SetRenderStats(drawn, culled)
 }
 
 
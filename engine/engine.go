@@ -6,6 +6,7 @@ import (
 
 	"github.com/javanhut/BifrostEngine/m/v2/input"
 	"github.com/javanhut/BifrostEngine/m/v2/renderer/core"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/profiler"
 	"github.com/javanhut/BifrostEngine/m/v2/scene"
 )
 
@@ -27,12 +28,24 @@ type Config struct {
 	WindowHeight int
 	WindowTitle  string
 	TargetFPS    int
+	// GraphicsDebug enables GL error reporting; it is also turned on by
+	// the BIFROST_GL_DEBUG environment variable
+	GraphicsDebug bool
+}
+
+// renderPasses maps the systems that draw, and so run between BeginFrame
+// and EndFrame instead of in the update phase, to their profiler pass
+var renderPasses = map[string]string{
+	"RenderSystem":       "scene",
+	"SpriteRenderSystem": "sprites",
 }
 
 // NewEngine creates a new engine instance
 func NewEngine(config Config) (*Engine, error) {
 	// Create renderer
-	renderer, err := core.New(config.WindowWidth, config.WindowHeight, config.WindowTitle)
+	options := core.DefaultOptions()
+	options.Debug = options.Debug || config.GraphicsDebug
+	renderer, err := core.NewWithOptions(config.WindowWidth, config.WindowHeight, config.WindowTitle, options)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create renderer: %w", err)
 	}
@@ -53,14 +66,18 @@ func NewEngine(config Config) (*Engine, error) {
 		targetFPS:    config.TargetFPS,
 	}
 	
+	// Scenes get the default systems as they load, unless they bring their own
+	sceneManager.Subscribe(func(event scene.SceneEvent) {
+		if event.Kind == scene.SceneLoaded && len(event.Scene.GetSystems()) == 0 {
+			event.Scene.AddSystem(engine.renderSystem)
+			event.Scene.AddSystem(NewScriptSystem())
+		}
+	})
+	
 	// Create default scene
-	defaultScene := sceneManager.CreateScene("default")
+	sceneManager.CreateScene("default")
 	sceneManager.SetActiveScene("default")
 	
-	// Add default systems
-	defaultScene.AddSystem(engine.renderSystem)
-	defaultScene.AddSystem(NewScriptSystem())
-	
 	return engine, nil
 }
 
@@ -81,15 +98,21 @@ func (e *Engine) Run() {
 		lastTime = currentTime
 		
 		// Update
+		endUpdate := e.renderer.GetProfiler().Begin("update")
 		e.update(e.deltaTime)
+		endUpdate()
 		
 		// Render
+		endRender := e.renderer.GetProfiler().Begin("render")
 		e.render()
+		endRender()
 		
 		// Frame rate limiting
 		frameTime := time.Since(frameStart)
 		if frameTime < targetFrameTime {
+			endIdle := e.renderer.GetProfiler().Begin("idle")
 			time.Sleep(targetFrameTime - frameTime)
+			endIdle()
 		}
 		
 		// Calculate FPS
@@ -109,49 +132,89 @@ func (e *Engine) update(deltaTime float32) {
 	// Update input
 	e.inputManager.Update()
 	
-	// Update non-render systems only
-	activeScene := e.sceneManager.GetActiveScene()
-	if activeScene != nil {
-		systems := activeScene.GetSystems()
+	// Scenes loaded in the background join between frames
+	e.sceneManager.ProcessLoads()
+	
+	// Update non-render systems only, in every loaded scene
+	for _, loaded := range e.sceneManager.LoadedScenes() {
+		systems := loaded.GetSystems()
 		for _, system := range systems {
-			// Skip render system in update phase
-			if system.GetName() != "RenderSystem" {
-				system.Update(activeScene, deltaTime)
+			// Skip render systems in update phase
+			if _, draws := renderPasses[system.GetName()]; !draws {
+				endSystem := e.renderer.GetProfiler().Begin(system.GetName())
+				system.Update(loaded, deltaTime)
+				endSystem()
 			}
 		}
 		
 		// Update entity components
-		entities := activeScene.GetEntities()
+		endComponents := e.renderer.GetProfiler().Begin("components")
+		entities := loaded.GetEntities()
 		for _, entity := range entities {
 			if entity.Active {
-				for _, component := range entity.Components {
+				for _, component := range entity.AllComponents() {
 					component.Update(deltaTime)
 				}
 			}
 		}
+		endComponents()
 	}
 }
 
 // render handles rendering
 func (e *Engine) render() {
-	e.renderer.BeginFrame()
-	
-	// Now run render system
 	activeScene := e.sceneManager.GetActiveScene()
 	if activeScene != nil {
-		systems := activeScene.GetSystems()
+		e.applyEnvironment(activeScene.GetEnvironment())
+	}
+	
+	e.renderer.BeginFrame()
+	e.renderSystem.stats = RenderStats{}
+	
+	// Now run render systems of every loaded scene, in the order they were added
+	loadedScenes := e.sceneManager.LoadedScenes()
+	for _, loaded := range loadedScenes {
+		systems := loaded.GetSystems()
 		for _, system := range systems {
-			if system.GetName() == "RenderSystem" {
-				system.Update(activeScene, 0) // deltaTime not needed for rendering
+			if pass, draws := renderPasses[system.GetName()]; draws {
+				endPass := e.renderer.BeginPass(pass)
+				system.Update(loaded, 0) // deltaTime not needed for rendering
+				endPass()
 			}
 		}
 	}
 	
+	e.renderer.FlushDebugDraw(e.deltaTime)
 	e.renderer.EndFrame()
+	
+	// Entities destroyed during the frame go once nothing is iterating them
+	for _, loaded := range loadedScenes {
+		loaded.FlushDestroyed()
+	}
+}
+
+// applyEnvironment shows the active scene's skybox and background color
+func (e *Engine) applyEnvironment(env scene.Environment) {
+	color := env.ClearColor
+	e.renderer.SetClearColor(color[0], color[1], color[2], color[3])
+	if err := e.renderer.SetSkybox(env.Skybox); err != nil {
+		fmt.Printf("Scene environment: %v\n", err)
+	}
 }
 
 // cleanup cleans up resources
 func (e *Engine) cleanup() {
+	// Systems holding GPU resources release them before the renderer goes,
+	// once each however many scenes share them
+	cleaned := make(map[scene.System]bool)
+	for _, loaded := range e.sceneManager.LoadedScenes() {
+		for _, system := range loaded.GetSystems() {
+			if cleaner, ok := system.(interface{ Cleanup() }); ok && !cleaned[system] {
+				cleaner.Cleanup()
+				cleaned[system] = true
+			}
+		}
+	}
 	e.renderer.Cleanup()
 }
 
@@ -175,6 +238,17 @@ func (e *Engine) GetFPS() float32 {
 	return e.currentFPS
 }
 
+// GetProfiler returns the frame profiler with CPU timings of the update,
+// render and system phases and GPU timings of the render passes
+func (e *Engine) GetProfiler() *profiler.Profiler {
+	return e.renderer.GetProfiler()
+}
+
+// GetRenderStats returns the render system statistics of the last frame
+func (e *Engine) GetRenderStats() RenderStats {
+	return e.renderSystem.GetStats()
+}
+
 // GetDeltaTime returns the frame delta time
 func (e *Engine) GetDeltaTime() float32 {
 	return e.deltaTime
@@ -0,0 +1,258 @@
+package engine
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+
+	"github.com/javanhut/BifrostEngine/m/v2/camera"
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/backend"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/core"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/sprite"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/text"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/tilemap"
+	. "github.com/javanhut/BifrostEngine/m/v2/scene"
+)
+
+// SpriteRenderSystem draws entities with sprite, tilemap and text
+// components through a sprite batch, seen by a 2D camera
+type SpriteRenderSystem struct {
+	batch    *sprite.SpriteBatch
+	camera   *camera.Camera2D
+	backend  backend.Backend
+	textures *sprite.TextureCache
+	maps     map[string]cachedTilemap
+	fonts    map[string]cachedFont
+	drawers  map[faceKey]*text.Drawer
+	reported map[string]bool
+	stats    sprite.Stats
+}
+
+type cachedFont struct {
+	font *text.Font
+	err  error
+}
+
+// faceKey identifies a rasterized face; each needs its own atlas
+type faceKey struct {
+	font          string
+	size          float32
+	distanceField bool
+}
+
+type cachedTilemap struct {
+	m   *tilemap.Map
+	err error
+}
+
+// NewSpriteRenderSystem creates a sprite render system drawing through the
+// renderer's backend
+func NewSpriteRenderSystem(renderer *core.Renderer, cam *camera.Camera2D) *SpriteRenderSystem {
+	return NewBackendSpriteRenderSystem(renderer.GetBackend(), cam)
+}
+
+// NewBackendSpriteRenderSystem creates a sprite render system for any
+// backend. Textures are filtered with nearest sampling, suiting pixel art.
+func NewBackendSpriteRenderSystem(b backend.Backend, cam *camera.Camera2D) *SpriteRenderSystem {
+	return &SpriteRenderSystem{
+		batch:    sprite.NewSpriteBatch(b),
+		camera:   cam,
+		backend:  b,
+		textures: sprite.NewTextureCache(b, false),
+		maps:     make(map[string]cachedTilemap),
+		fonts:    make(map[string]cachedFont),
+		drawers:  make(map[faceKey]*text.Drawer),
+		reported: make(map[string]bool),
+	}
+}
+
+// GetCamera returns the 2D camera sprites are drawn with
+func (ss *SpriteRenderSystem) GetCamera() *camera.Camera2D {
+	return ss.camera
+}
+
+// GetStats returns the sprite and draw call counts of the last update
+func (ss *SpriteRenderSystem) GetStats() sprite.Stats {
+	return ss.stats
+}
+
+// Update draws the scene's sprites. Entities are queued in ID order, so
+// sprites on the same layer and texture overlap the same way every frame.
+func (ss *SpriteRenderSystem) Update(scene *Scene, deltaTime float32) {
+	ss.batch.BeginCamera2D(ss.camera)
+
+	entities := scene.GetEntities()
+	sort.Slice(entities, func(i, j int) bool { return entities[i].ID < entities[j].ID })
+	for _, entity := range entities {
+		if !entity.Active {
+			continue
+		}
+		if tm, ok := Get[*TilemapComponent](entity); ok && tm.Visible {
+			ss.drawTilemap(entity, tm)
+		}
+		if label, ok := Get[*TextComponent](entity); ok && label.Visible && label.Text != "" {
+			ss.drawText(entity, label)
+		}
+		comp, ok := Get[*SpriteComponent](entity)
+		if !ok || !comp.Visible {
+			continue
+		}
+
+		texture, err := ss.textures.Get(comp.Texture)
+		if err != nil {
+			// Failed loads are cached, so report each path once
+			if !ss.reported[comp.Texture] {
+				fmt.Printf("Sprite %s: %v\n", entity.Name, err)
+				ss.reported[comp.Texture] = true
+			}
+			continue
+		}
+
+		// An animation replaces the sprite's region with its current frame
+		region := comp.Region
+		if anim, ok := Get[*SpriteAnimationComponent](entity); ok {
+			if frame, ok := anim.CurrentRegion(); ok {
+				region = frame
+			}
+		}
+
+		ss.batch.Draw(spriteFromComponent(comp, region, texture, entity.Transform.GetWorldMatrix()))
+	}
+
+	ss.stats = ss.batch.End()
+}
+
+func (ss *SpriteRenderSystem) drawTilemap(entity *Entity, comp *TilemapComponent) {
+	m, err := ss.GetTilemap(comp.Map)
+	if err == nil {
+		world := entity.Transform.GetWorldMatrix()
+		err = tilemap.Draw(ss.batch, m, ss.textures.Get, bmath.NewVector2(world[3], world[7]), comp.Layer)
+	}
+	if err != nil && !ss.reported[comp.Map] {
+		fmt.Printf("Tilemap %s: %v\n", entity.Name, err)
+		ss.reported[comp.Map] = true
+	}
+}
+
+// GetTilemap returns a map file drawn by tilemap components, loading it on
+// first use. Failed loads are remembered like failed textures.
+func (ss *SpriteRenderSystem) GetTilemap(path string) (*tilemap.Map, error) {
+	if cached, exists := ss.maps[path]; exists {
+		return cached.m, cached.err
+	}
+	m, err := tilemap.Load(path)
+	ss.maps[path] = cachedTilemap{m: m, err: err}
+	return m, err
+}
+
+func (ss *SpriteRenderSystem) drawText(entity *Entity, comp *TextComponent) {
+	drawer, err := ss.textDrawer(comp)
+	if err != nil {
+		if !ss.reported[comp.Font] {
+			fmt.Printf("Text %s: %v\n", entity.Name, err)
+			ss.reported[comp.Font] = true
+		}
+		return
+	}
+
+	position, rotation, scale, _ := decompose2D(entity.Transform.GetWorldMatrix())
+	options := text.DefaultDrawOptions()
+	options.Position = position
+	options.Origin = bmath.NewVector2(comp.Origin[0], comp.Origin[1])
+	options.Scale = scale
+	options.Rotation = rotation
+	options.Color = comp.Color
+	options.MaxWidth = comp.MaxWidth
+	options.Align = text.Align(comp.Align)
+	options.Layer = comp.Layer
+	drawer.Draw(ss.batch, comp.Text, options)
+}
+
+// textDrawer returns the drawer for a component's font, size and mode,
+// creating the face on first use. Faces are rasterized at the component's
+// size, so one world unit is one glyph pixel before scaling.
+func (ss *SpriteRenderSystem) textDrawer(comp *TextComponent) (*text.Drawer, error) {
+	key := faceKey{font: comp.Font, size: comp.Size, distanceField: comp.DistanceField}
+	if drawer, exists := ss.drawers[key]; exists {
+		return drawer, nil
+	}
+
+	cached, exists := ss.fonts[comp.Font]
+	if !exists {
+		if comp.Font == "" {
+			cached.font = text.DefaultFont()
+		} else {
+			cached.font, cached.err = text.LoadFont(comp.Font)
+		}
+		ss.fonts[comp.Font] = cached
+	}
+	if cached.err != nil {
+		return nil, cached.err
+	}
+
+	options := text.DefaultOptions(comp.Size)
+	options.DistanceField = comp.DistanceField
+	face, err := text.NewFace(cached.font, options)
+	if err != nil {
+		return nil, err
+	}
+	drawer := text.NewDrawer(ss.backend, face)
+	ss.drawers[key] = drawer
+	return drawer, nil
+}
+
+// decompose2D returns the translation, z rotation and x/y scale of a
+// row-major world matrix
+func decompose2D(world bmath.Matrix4) (bmath.Vector2, float32, float32, float32) {
+	position := bmath.NewVector2(world[3], world[7])
+	rotation := float32(math.Atan2(float64(world[4]), float64(world[0])))
+	scaleX := float32(math.Hypot(float64(world[0]), float64(world[4])))
+	scaleY := float32(math.Hypot(float64(world[1]), float64(world[5])))
+	return position, rotation, scaleX, scaleY
+}
+
+// spriteFromComponent places a sprite with the translation, z rotation and
+// x/y scale of a row-major world matrix
+func spriteFromComponent(comp *SpriteComponent, region [4]int, texture sprite.Texture, world bmath.Matrix4) sprite.Sprite {
+	s := sprite.NewSprite(texture)
+	if region[2] > 0 && region[3] > 0 {
+		s.Source = image.Rect(region[0], region[1], region[0]+region[2], region[1]+region[3])
+	}
+
+	size := bmath.NewVector2(comp.Size[0], comp.Size[1])
+	if size.X == 0 && size.Y == 0 {
+		source := s.Source
+		if source.Empty() {
+			source = image.Rect(0, 0, texture.Width, texture.Height)
+		}
+		size = bmath.NewVector2(float32(source.Dx()), float32(source.Dy()))
+	}
+
+	position, rotation, scaleX, scaleY := decompose2D(world)
+	s.Size = bmath.NewVector2(size.X*scaleX, size.Y*scaleY)
+	s.Position = position
+	s.Rotation = rotation
+	s.Origin = bmath.NewVector2(comp.Origin[0], comp.Origin[1])
+	s.Tint = comp.Tint
+	s.FlipX = comp.FlipX
+	s.FlipY = comp.FlipY
+	s.Layer = comp.Layer
+	return s
+}
+
+// Cleanup deletes the textures loaded for sprites, tilemaps and text
+func (ss *SpriteRenderSystem) Cleanup() {
+	ss.textures.Cleanup()
+	ss.maps = make(map[string]cachedTilemap)
+	for _, drawer := range ss.drawers {
+		drawer.Cleanup()
+	}
+	ss.drawers = make(map[faceKey]*text.Drawer)
+}
+
+// GetName returns the system name
+func (ss *SpriteRenderSystem) GetName() string {
+	return "SpriteRenderSystem"
+}
@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/javanhut/BifrostEngine/m/v2/camera"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/software"
+	. "github.com/javanhut/BifrostEngine/m/v2/scene"
+)
+
+// whiteTexture writes a small white PNG for sprites to be tinted
+func whiteTexture(t *testing.T) string {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	path := filepath.Join(t.TempDir(), "white.png")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSpriteDrawOrder(t *testing.T) {
+	red := [4]float32{1, 0, 0, 1}
+	green := [4]float32{0, 1, 0, 1}
+	blue := [4]float32{0, 0, 1, 1}
+	type sprite struct {
+		tint  [4]float32
+		layer int
+	}
+	tests := []struct {
+		name    string
+		sprites []sprite
+		// remove is the index of a sprite destroyed before the last one is
+		// created, so the last one reuses its slot; -1 removes none
+		remove int
+		want   color.RGBA
+	}{
+		{"creation order", []sprite{{red, 0}, {green, 0}, {blue, 0}}, -1, color.RGBA{0, 0, 255, 255}},
+		{"layer before creation order", []sprite{{red, 1}, {green, 0}, {blue, 0}}, -1, color.RGBA{255, 0, 0, 255}},
+		{"reused slot drawn last", []sprite{{red, 0}, {green, 0}, {blue, 0}}, 0, color.RGBA{0, 0, 255, 255}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			texture := whiteTexture(t)
+			r := software.NewRasterizer(8, 8)
+			system := NewBackendSpriteRenderSystem(r, camera.NewCamera2D(8, 8))
+			defer system.Cleanup()
+
+			s := NewScene("sprites")
+			var created []*Entity
+			for i, sp := range tt.sprites {
+				if i == len(tt.sprites)-1 && tt.remove >= 0 {
+					s.RemoveEntity(created[tt.remove].ID)
+				}
+				comp := NewSpriteComponent(texture)
+				comp.Size = [2]float32{4, 4}
+				comp.Tint = sp.tint
+				comp.Layer = sp.layer
+				entity := s.CreateEntity("sprite")
+				entity.AddComponent(comp)
+				created = append(created, entity)
+			}
+			if tt.remove >= 0 && created[len(created)-1].ID.Index() != created[tt.remove].ID.Index() {
+				t.Fatal("last sprite did not reuse the removed slot")
+			}
+
+			// Map iteration would shuffle equal sprites between frames
+			for frame := 0; frame < 20; frame++ {
+				r.BeginFrame()
+				system.Update(s, 0)
+				if got := r.Image().RGBAAt(4, 4); got != tt.want {
+					t.Fatalf("frame %d: top sprite %v, want %v", frame, got, tt.want)
+				}
+			}
+		})
+	}
+}
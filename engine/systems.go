@@ -1,44 +1,110 @@
 package engine
 
 import (
+	"github.com/javanhut/BifrostEngine/m/v2/camera"
 	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/backend"
 	"github.com/javanhut/BifrostEngine/m/v2/renderer/core"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/queue"
 	. "github.com/javanhut/BifrostEngine/m/v2/scene"
 )
 
+// RenderStats counts the objects processed by the last render system update
+type RenderStats struct {
+	Drawn       int
+	Culled      int
+	Transparent int
+	DrawCalls   int
+}
+
 // RenderSystem handles rendering of entities with mesh components
 type RenderSystem struct {
-	renderer *core.Renderer
+	backend        backend.Backend
+	camera         camera.Camera
+	meshes         map[string]backend.MeshHandle
+	cullingEnabled bool
+	stats          RenderStats
+	queue          *queue.RenderQueue
+	viewMode       backend.ViewMode
 }
 
-// NewRenderSystem creates a new render system
+// NewRenderSystem creates a new render system drawing through the renderer's backend
 func NewRenderSystem(renderer *core.Renderer) *RenderSystem {
+	rs := NewBackendRenderSystem(renderer.GetBackend(), renderer.GetCamera())
+	for _, meshType := range []string{"cube", "triangle", "sphere", "cylinder", "cone", "torus", "plane", "pyramid"} {
+		if handle, exists := renderer.GetMeshHandle(meshType); exists {
+			rs.SetMesh(meshType, handle)
+		}
+	}
+	return rs
+}
+
+// NewBackendRenderSystem creates a render system for any backend and camera
+func NewBackendRenderSystem(b backend.Backend, cam camera.Camera) *RenderSystem {
 	return &RenderSystem{
-		renderer: renderer,
+		backend:        b,
+		camera:         cam,
+		meshes:         make(map[string]backend.MeshHandle),
+		cullingEnabled: true,
+		queue:          queue.New(),
 	}
 }
 
-// Update processes all entities with mesh components
+// SetMesh assigns the backend mesh drawn for a mesh type
+func (rs *RenderSystem) SetMesh(meshType string, mesh backend.MeshHandle) {
+	rs.meshes[meshType] = mesh
+}
+
+// SetCullingEnabled toggles frustum culling
+func (rs *RenderSystem) SetCullingEnabled(enabled bool) {
+	rs.cullingEnabled = enabled
+}
+
+// SetViewMode sets how this system's viewport shades meshes
+func (rs *RenderSystem) SetViewMode(mode backend.ViewMode) {
+	rs.viewMode = mode
+}
+
+// GetViewMode returns the view mode of this system's viewport
+func (rs *RenderSystem) GetViewMode() backend.ViewMode {
+	return rs.viewMode
+}
+
+// GetQueue returns the render queue, e.g. to toggle render layers
+func (rs *RenderSystem) GetQueue() *queue.RenderQueue {
+	return rs.queue
+}
+
+// GetStats returns the object and draw call counts of the last frame,
+// summed over the loaded scenes
+func (rs *RenderSystem) GetStats() RenderStats {
+	return rs.stats
+}
+
+// Update queues all visible mesh entities and submits them through the
+// render queue, which sorts them into opaque and transparent passes and
+// batches entities sharing a mesh into instanced draws. Every mesh uses the
+// default material; per-entity colors are instance data.
 func (rs *RenderSystem) Update(scene *Scene, deltaTime float32) {
-	entities := scene.GetEntities()
+	view := rs.camera.GetViewMatrix()
+	projection := rs.camera.GetProjectionMatrix()
+	rs.backend.SetCamera(view, projection)
+	rs.backend.SetViewMode(rs.viewMode)
 	
-	for _, entity := range entities {
-		if !entity.Active {
+	// Camera matrices are in the GPU's column-major layout; transposing their
+	// product gives the clip transform the backend applies in our convention
+	frustum := bmath.NewFrustum(view.Multiply(projection).Transpose())
+	viewMatrix := view.Transpose()
+	
+	meshes := Query[*MeshComponent](scene)
+	for meshes.Next() {
+		entity, mesh := meshes.Entity(), meshes.Get()
+		if !entity.Active || !mesh.Visible {
 			continue
 		}
 		
-		// Check if entity has mesh component
-		meshComp := entity.GetComponent("Mesh")
-		if meshComp == nil {
-			continue
-		}
-		
-		// Type assert to MeshComponent
-		mesh, ok := meshComp.(*MeshComponent)
-		if !ok {
-			continue
-		}
-		if !mesh.Visible {
+		handle, exists := rs.meshes[mesh.MeshType]
+		if !exists {
 			continue
 		}
 		
@@ -46,14 +112,29 @@ func (rs *RenderSystem) Update(scene *Scene, deltaTime float32) {
 		transform := entity.Transform
 		worldMatrix := transform.GetWorldMatrix()
 		
-		// Render based on mesh type
-		switch mesh.MeshType {
-		case "cube":
-			rs.renderer.DrawCubeWithTransform(worldMatrix)
-		case "triangle":
-			rs.renderer.DrawTriangleWithTransform(worldMatrix)
+		bounds := rs.backend.MeshBounds(handle).Transform(worldMatrix)
+		if rs.cullingEnabled && !frustum.IntersectsAABB(bounds) {
+			rs.stats.Culled++
+			continue
 		}
+		
+		// Transforms are row-major, the backend expects the GPU layout
+		rs.queue.Push(queue.Command{
+			Mesh:        handle,
+			Layer:       queue.Layer(mesh.Layer),
+			Transparent: mesh.IsTransparent(),
+			Depth:       -viewMatrix.MultiplyVector3(bounds.Center(), 1).Z,
+			Instance: backend.Instance{
+				Model: worldMatrix.Transpose(),
+				Color: [4]float32{mesh.Color[0], mesh.Color[1], mesh.Color[2], mesh.Opacity},
+			},
+		})
 	}
+	
+	queueStats := rs.queue.Flush(rs.backend)
+	rs.stats.Drawn += queueStats.Opaque + queueStats.Transparent
+	rs.stats.Transparent += queueStats.Transparent
+	rs.stats.DrawCalls += queueStats.DrawCalls
 }
 
 // GetName returns the system name
@@ -71,33 +152,27 @@ func NewScriptSystem() *ScriptSystem {
 
 // Update processes all entities with script components
 func (ss *ScriptSystem) Update(scene *Scene, deltaTime float32) {
-	entities := scene.GetEntities()
-	
-	for _, entity := range entities {
+	scripts := Query[*ScriptComponent](scene)
+	for scripts.Next() {
+		entity := scripts.Entity()
 		if !entity.Active {
 			continue
 		}
 		
-		// Check if entity has script component
-		scriptComp := entity.GetComponent("Script")
-		if scriptComp == nil {
-			continue
-		}
-		
-		script, ok := scriptComp.(*ScriptComponent)
-		if !ok {
-			continue
-		}
-		
-		// Run OnStart if not started
-		if !script.Started && script.OnStart != nil {
-			script.OnStart(entity)
-			script.Started = true
-		}
-		
-		// Run OnUpdate
-		if script.OnUpdate != nil {
-			script.OnUpdate(entity, deltaTime)
+		// Scripts run in the order they were added
+		for i := 0; i < scripts.Len(); i++ {
+			script := scripts.At(i)
+			
+			// Run OnStart if not started
+			if !script.Started && script.OnStart != nil {
+				script.OnStart(entity)
+				script.Started = true
+			}
+			
+			// Run OnUpdate
+			if script.OnUpdate != nil {
+				script.OnUpdate(entity, deltaTime)
+			}
 		}
 	}
 }
@@ -121,24 +196,10 @@ func NewCameraSystem(renderer *core.Renderer) *CameraSystem {
 
 // Update finds the active camera and updates the renderer
 func (cs *CameraSystem) Update(scene *Scene, deltaTime float32) {
-	entities := scene.GetEntities()
-	
-	for _, entity := range entities {
-		if !entity.Active {
-			continue
-		}
-		
-		// Check if entity has camera component
-		cameraComp := entity.GetComponent("Camera")
-		if cameraComp == nil {
-			continue
-		}
-		
-		cam, ok := cameraComp.(*CameraComponent)
-		if !ok {
-			continue
-		}
-		if !cam.Active {
+	cameras := Query[*CameraComponent](scene)
+	for cameras.Next() {
+		entity, cam := cameras.Entity(), cameras.Get()
+		if !entity.Active || !cam.Active {
 			continue
 		}
 		
@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/tilemap"
+	. "github.com/javanhut/BifrostEngine/m/v2/scene"
+)
+
+// LoadTilemap loads a Tiled map into a scene. The map becomes an entity
+// with a tilemap component drawing its tile layers from the given sprite
+// layer up, and every object of its object layers becomes a child entity
+// carrying a tile object component. Tile objects also get a sprite on the
+// sprite layer of their object layer, so they sort between the tiles.
+func LoadTilemap(scene *Scene, path string, layer int) (*Entity, *tilemap.Map, error) {
+	m, err := tilemap.Load(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root := scene.CreateEntity(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	comp := NewTilemapComponent(path)
+	comp.Layer = layer
+	root.AddComponent(comp)
+
+	for index, l := range m.Layers {
+		if l.Kind != tilemap.ObjectLayer {
+			continue
+		}
+		for _, obj := range l.Objects {
+			entity := scene.CreateEntity(objectName(l, obj))
+			position := l.ObjectPosition(obj)
+			entity.Transform.SetPosition(bmath.NewVector3(position.X, position.Y, 0))
+			// Tiled rotates clockwise with y down, which is clockwise on
+			// screen and so negative about z
+			entity.Transform.SetRotation(bmath.NewVector3(0, 0, -obj.Rotation))
+			if err := scene.SetParentKeepLocal(entity, root); err != nil {
+				return nil, nil, err
+			}
+
+			entity.AddComponent(&TileObjectComponent{
+				ObjectID:   obj.ID,
+				Type:       obj.Type,
+				Layer:      l.Name,
+				Size:       [2]float32{obj.Width, obj.Height},
+				Properties: obj.Properties,
+			})
+			if obj.GID != 0 {
+				if s, ok := tileObjectSprite(m, l, obj); ok {
+					s.Layer = layer + index
+					entity.AddComponent(s)
+				}
+			}
+		}
+	}
+	return root, m, nil
+}
+
+func objectName(l *tilemap.Layer, obj tilemap.Object) string {
+	if obj.Name != "" {
+		return obj.Name
+	}
+	return fmt.Sprintf("%s %d", l.Name, obj.ID)
+}
+
+// tileObjectSprite shows a tile object's tile stretched over the object,
+// anchored at its bottom-left corner as Tiled places it
+func tileObjectSprite(m *tilemap.Map, l *tilemap.Layer, obj tilemap.Object) (*SpriteComponent, bool) {
+	tile, ok := m.Tile(obj.GID)
+	if !ok {
+		return nil, false
+	}
+
+	path, region := tile.Image()
+	s := NewSpriteComponent(path)
+	s.SetRegion(region.Min.X, region.Min.Y, region.Dx(), region.Dy())
+	s.Size = [2]float32{obj.Width, obj.Height}
+	s.Origin = [2]float32{0, 0}
+	s.Tint[3] = l.Opacity
+	s.FlipX = tile.FlipH
+	s.FlipY = tile.FlipV
+	s.Visible = obj.Visible && l.Visible
+	return s, true
+}
+
+// TilemapSolidAt reports whether a world point lies on a solid tile of a
+// map placed by an entity loaded with LoadTilemap
+func TilemapSolidAt(entity *Entity, m *tilemap.Map, x, y float32) bool {
+	origin := entity.Transform.GetWorldPosition()
+	return m.SolidAt(x-origin.X, y-origin.Y)
+}
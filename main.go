@@ -1,9 +1,15 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/atlas"
+	"github.com/javanhut/BifrostEngine/m/v2/scene"
 )
 
 func main() {
@@ -42,6 +48,18 @@ func main() {
 	case "demos":
 		listDemos()
 		
+	case "atlas":
+		if err := packAtlas(os.Args[2:]); err != nil {
+			fmt.Printf("Error packing atlas: %v\n", err)
+			os.Exit(1)
+		}
+		
+	case "scene":
+		if err := convertScene(os.Args[2:]); err != nil {
+			fmt.Printf("Error converting scene: %v\n", err)
+			os.Exit(1)
+		}
+		
 	case "demo":
 		if len(os.Args) < 3 {
 			fmt.Println("Please specify a demo name")
@@ -72,6 +90,8 @@ func printUsage() {
 	fmt.Println("  run          Run the current project")
 	fmt.Println("  demos        List available demos")
 	fmt.Println("  demo <name>  Run a specific demo")
+	fmt.Println("  atlas <dir>  Pack the PNGs in a directory into an atlas")
+	fmt.Println("  scene <file> Convert a scene between JSON and binary")
 	fmt.Println("  --version    Show version information")
 	fmt.Println("  --help       Show this help message")
 	fmt.Println()
@@ -79,6 +99,81 @@ func printUsage() {
 	fmt.Println("  bifrost editor")
 	fmt.Println("  bifrost demo ui_editor")
 	fmt.Println("  bifrost demos")
+	fmt.Println("  bifrost atlas -out sprites/hero sprites/hero_frames")
+	fmt.Println("  bifrost scene projects/Game/Game.bifrost")
+}
+
+// packAtlas builds <out>.png and <out>.json from a directory of PNGs
+func packAtlas(args []string) error {
+	defaults := atlas.DefaultOptions()
+	flags := flag.NewFlagSet("atlas", flag.ContinueOnError)
+	out := flags.String("out", "atlas", "output path without extension")
+	maxSize := flags.Int("max", defaults.MaxSize, "maximum atlas width and height")
+	padding := flags.Int("padding", defaults.Padding, "pixels between images")
+	pot := flags.Bool("pot", defaults.PowerOfTwo, "restrict the atlas size to powers of two")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: bifrost atlas [-out path] [-max size] [-padding px] [-pot=false] <dir>")
+	}
+
+	packed, err := atlas.BuildFromDir(flags.Arg(0), atlas.Options{
+		MaxSize:    *maxSize,
+		Padding:    *padding,
+		PowerOfTwo: *pot,
+	})
+	if err != nil {
+		return err
+	}
+	if err := packed.Save(*out+".png", *out+".json"); err != nil {
+		return err
+	}
+	fmt.Printf("Packed %d images into %s.png (%dx%d)\n", len(packed.Frames), *out, packed.Width, packed.Height)
+	return nil
+}
+
+// convertScene writes a JSON scene file in the binary format, or a binary
+// one back as JSON
+func convertScene(args []string) error {
+	flags := flag.NewFlagSet("scene", flag.ContinueOnError)
+	out := flags.String("out", "", "output path; defaults to the input with the other format's extension")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: bifrost scene [-out path] <file>")
+	}
+	
+	in := flags.Arg(0)
+	toBinary := filepath.Ext(in) != scene.BinaryExtension
+	convert, ext := scene.ConvertToBinary, scene.BinaryExtension
+	if !toBinary {
+		convert, ext = scene.ConvertToJSON, ".bifrost"
+	}
+	if *out == "" {
+		*out = strings.TrimSuffix(in, filepath.Ext(in)) + ext
+	}
+	
+	input, err := os.Open(in)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	output, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := convert(input, output); err != nil {
+		output.Close()
+		os.Remove(*out)
+		return err
+	}
+	if err := output.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Converted %s to %s\n", in, *out)
+	return nil
 }
 
 func listDemos() {
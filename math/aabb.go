@@ -0,0 +1,70 @@
+package math
+
+type AABB struct {
+	Min, Max Vector3
+}
+
+func NewAABB(min, max Vector3) AABB {
+	return AABB{Min: min, Max: max}
+}
+
+// NewAABBFromVertices computes the bounds of interleaved vertex data whose
+// first three components of every stride floats are a position
+func NewAABBFromVertices(vertices []float32, stride int) AABB {
+	if stride < 3 || len(vertices) < 3 {
+		return AABB{}
+	}
+
+	bounds := AABB{
+		Min: Vector3{vertices[0], vertices[1], vertices[2]},
+		Max: Vector3{vertices[0], vertices[1], vertices[2]},
+	}
+	for i := stride; i+2 < len(vertices); i += stride {
+		bounds = bounds.Expand(Vector3{vertices[i], vertices[i+1], vertices[i+2]})
+	}
+	return bounds
+}
+
+func (b AABB) Center() Vector3 {
+	return b.Min.Add(b.Max).Mul(0.5)
+}
+
+func (b AABB) Extents() Vector3 {
+	return b.Max.Sub(b.Min).Mul(0.5)
+}
+
+func (b AABB) Expand(point Vector3) AABB {
+	return AABB{
+		Min: Vector3{Min(b.Min.X, point.X), Min(b.Min.Y, point.Y), Min(b.Min.Z, point.Z)},
+		Max: Vector3{Max(b.Max.X, point.X), Max(b.Max.Y, point.Y), Max(b.Max.Z, point.Z)},
+	}
+}
+
+func (b AABB) Merge(other AABB) AABB {
+	return b.Expand(other.Min).Expand(other.Max)
+}
+
+func (b AABB) Contains(point Vector3) bool {
+	return point.X >= b.Min.X && point.X <= b.Max.X &&
+		point.Y >= b.Min.Y && point.Y <= b.Max.Y &&
+		point.Z >= b.Min.Z && point.Z <= b.Max.Z
+}
+
+// Transform returns the axis-aligned bounds of the box after transformation
+// by m, using the same convention as MultiplyVector3
+func (b AABB) Transform(m Matrix4) AABB {
+	center := m.MultiplyVector3(b.Center(), 1)
+	extents := b.Extents()
+
+	// Project the extents onto each world axis using the absolute basis
+	worldExtents := Vector3{
+		X: Abs(m[0])*extents.X + Abs(m[1])*extents.Y + Abs(m[2])*extents.Z,
+		Y: Abs(m[4])*extents.X + Abs(m[5])*extents.Y + Abs(m[6])*extents.Z,
+		Z: Abs(m[8])*extents.X + Abs(m[9])*extents.Y + Abs(m[10])*extents.Z,
+	}
+
+	return AABB{
+		Min: center.Sub(worldExtents),
+		Max: center.Add(worldExtents),
+	}
+}
@@ -0,0 +1,118 @@
+package math
+
+type Plane struct {
+	Normal   Vector3
+	Distance float32
+}
+
+func (p Plane) normalize() Plane {
+	length := p.Normal.Length()
+	if length == 0 {
+		return p
+	}
+	return Plane{Normal: p.Normal.Div(length), Distance: p.Distance / length}
+}
+
+// SignedDistance is positive on the side the normal points to
+func (p Plane) SignedDistance(point Vector3) float32 {
+	return p.Normal.Dot(point) + p.Distance
+}
+
+// Frustum planes point inwards: left, right, bottom, top, near, far
+type Frustum struct {
+	Planes [6]Plane
+}
+
+// NewFrustum extracts the clip planes of a combined view-projection matrix
+// in the row-major convention used by Multiply and MultiplyVector3
+func NewFrustum(m Matrix4) Frustum {
+	row := func(i int) [4]float32 {
+		return [4]float32{m[i*4], m[i*4+1], m[i*4+2], m[i*4+3]}
+	}
+	plane := func(a, b [4]float32, sign float32) Plane {
+		return Plane{
+			Normal:   Vector3{a[0] + sign*b[0], a[1] + sign*b[1], a[2] + sign*b[2]},
+			Distance: a[3] + sign*b[3],
+		}.normalize()
+	}
+
+	w := row(3)
+	return Frustum{Planes: [6]Plane{
+		plane(w, row(0), 1),
+		plane(w, row(0), -1),
+		plane(w, row(1), 1),
+		plane(w, row(1), -1),
+		plane(w, row(2), 1),
+		plane(w, row(2), -1),
+	}}
+}
+
+func (f Frustum) ContainsPoint(point Vector3) bool {
+	for _, p := range f.Planes {
+		if p.SignedDistance(point) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (f Frustum) IntersectsSphere(center Vector3, radius float32) bool {
+	for _, p := range f.Planes {
+		if p.SignedDistance(center) < -radius {
+			return false
+		}
+	}
+	return true
+}
+
+// IntersectsAABB is conservative: boxes near frustum corners may be
+// reported as intersecting when they are not
+func (f Frustum) IntersectsAABB(box AABB) bool {
+	for _, p := range f.Planes {
+		// Test the corner furthest along the plane normal
+		corner := box.Min
+		if p.Normal.X >= 0 {
+			corner.X = box.Max.X
+		}
+		if p.Normal.Y >= 0 {
+			corner.Y = box.Max.Y
+		}
+		if p.Normal.Z >= 0 {
+			corner.Z = box.Max.Z
+		}
+		if p.SignedDistance(corner) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Corners returns the frustum's corner points: the near plane's
+// bottom-left, bottom-right, top-right and top-left, then the same for
+// the far plane
+func (f Frustum) Corners() [8]Vector3 {
+	left, right, bottom, top, near, far := f.Planes[0], f.Planes[1], f.Planes[2], f.Planes[3], f.Planes[4], f.Planes[5]
+	return [8]Vector3{
+		intersectPlanes(near, bottom, left),
+		intersectPlanes(near, bottom, right),
+		intersectPlanes(near, top, right),
+		intersectPlanes(near, top, left),
+		intersectPlanes(far, bottom, left),
+		intersectPlanes(far, bottom, right),
+		intersectPlanes(far, top, right),
+		intersectPlanes(far, top, left),
+	}
+}
+
+func intersectPlanes(a, b, c Plane) Vector3 {
+	bc := b.Normal.Cross(c.Normal)
+	denominator := a.Normal.Dot(bc)
+	if denominator == 0 {
+		return Vector3Zero
+	}
+
+	point := bc.Mul(-a.Distance).
+		Add(c.Normal.Cross(a.Normal).Mul(-b.Distance)).
+		Add(a.Normal.Cross(b.Normal).Mul(-c.Distance))
+	return point.Div(denominator)
+}
@@ -0,0 +1,130 @@
+package math
+
+import "testing"
+
+func approx(a, b Vector3) bool {
+	const epsilon = 1e-3
+	return Abs(a.X-b.X) < epsilon && Abs(a.Y-b.Y) < epsilon && Abs(a.Z-b.Z) < epsilon
+}
+
+// testFrustum looks down -Z from the origin with a 90 degree field of view,
+// so at depth d the frustum spans -d to d on X and Y, from 1 to 10 deep
+func testFrustum() Frustum {
+	view := NewLookAt(Vector3{0, 0, 0}, Vector3{0, 0, -1}, Vector3{0, 1, 0})
+	projection := NewPerspective(Radians(90), 1, 1, 10)
+	// NewLookAt is laid out column-major and NewPerspective row-major
+	return NewFrustum(projection.Multiply(view.Transpose()))
+}
+
+func TestFrustumIntersectsAABB(t *testing.T) {
+	box := func(x, y, z, halfSize float32) AABB {
+		center := Vector3{x, y, z}
+		half := Vector3{halfSize, halfSize, halfSize}
+		return NewAABB(center.Sub(half), center.Add(half))
+	}
+	tests := []struct {
+		name string
+		box  AABB
+		want bool
+	}{
+		{"inside", box(0, 0, -5, 0.5), true},
+		{"encloses the frustum", box(0, 0, 0, 100), true},
+		{"outside left", box(-8, 0, -5, 0.5), false},
+		{"outside right", box(8, 0, -5, 0.5), false},
+		{"outside bottom", box(0, -8, -5, 0.5), false},
+		{"outside top", box(0, 8, -5, 0.5), false},
+		{"outside near", box(0, 0, -0.25, 0.25), false},
+		{"outside far", box(0, 0, -12, 0.5), false},
+		{"behind the camera", box(0, 0, 5, 0.5), false},
+		{"straddling left", box(-5, 0, -5, 0.5), true},
+		{"straddling top", box(0, 5, -5, 0.5), true},
+		{"straddling near", box(0, 0, -1, 0.5), true},
+		{"straddling far", box(0, 0, -10, 0.5), true},
+	}
+
+	frustum := testFrustum()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := frustum.IntersectsAABB(tt.box); got != tt.want {
+				t.Errorf("IntersectsAABB(%v) = %v, want %v", tt.box, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFrustumContainsPoint(t *testing.T) {
+	tests := []struct {
+		point Vector3
+		want  bool
+	}{
+		{Vector3{0, 0, -5}, true},
+		{Vector3{4.9, -4.9, -5}, true},
+		{Vector3{5.1, 0, -5}, false},
+		{Vector3{0, -5.1, -5}, false},
+		{Vector3{0, 0, -0.9}, false},
+		{Vector3{0, 0, -10.1}, false},
+	}
+
+	frustum := testFrustum()
+	for _, tt := range tests {
+		if got := frustum.ContainsPoint(tt.point); got != tt.want {
+			t.Errorf("ContainsPoint(%v) = %v, want %v", tt.point, got, tt.want)
+		}
+	}
+}
+
+func TestFrustumCorners(t *testing.T) {
+	want := [8]Vector3{
+		{-1, -1, -1}, {1, -1, -1}, {1, 1, -1}, {-1, 1, -1},
+		{-10, -10, -10}, {10, -10, -10}, {10, 10, -10}, {-10, 10, -10},
+	}
+	got := testFrustum().Corners()
+	for i := range want {
+		if !approx(got[i], want[i]) {
+			t.Errorf("corner %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestAABBTransform(t *testing.T) {
+	const sqrt2 = 1.4142135
+	unit := NewAABB(Vector3{-1, -1, -1}, Vector3{1, 1, 1})
+	tests := []struct {
+		name     string
+		box      AABB
+		m        Matrix4
+		min, max Vector3
+	}{
+		{
+			name: "identity",
+			box:  unit,
+			m:    NewMatrix4Identity(),
+			min:  Vector3{-1, -1, -1},
+			max:  Vector3{1, 1, 1},
+		},
+		{
+			name: "translated and scaled",
+			box:  NewAABB(Vector3{0, 0, 0}, Vector3{1, 2, 3}),
+			m:    NewTranslationMatrix(5, 0, -5).Multiply(NewScaleMatrix(2, 1, -1)),
+			min:  Vector3{5, 0, -8},
+			max:  Vector3{7, 2, -5},
+		},
+		{
+			// A 45 degree turn about Y puts the box's corners on the X and Z axes
+			name: "rotated",
+			box:  unit,
+			m:    NewTranslationMatrix(2, 0, 0).Multiply(NewRotationY(Radians(45))),
+			min:  Vector3{2 - sqrt2, -1, -sqrt2},
+			max:  Vector3{2 + sqrt2, 1, sqrt2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.box.Transform(tt.m)
+			if !approx(got.Min, tt.min) || !approx(got.Max, tt.max) {
+				t.Errorf("Transform = %v, want {%v %v}", got, tt.min, tt.max)
+			}
+		})
+	}
+}
@@ -48,6 +48,71 @@ func (m Matrix4) Transpose() Matrix4 {
 	}
 }
 
+func (m Matrix4) Inverse() (Matrix4, bool) {
+	var inv Matrix4
+	
+	inv[0] = m[5]*m[10]*m[15] - m[5]*m[11]*m[14] - m[9]*m[6]*m[15] + m[9]*m[7]*m[14] + m[13]*m[6]*m[11] - m[13]*m[7]*m[10]
+	inv[4] = -m[4]*m[10]*m[15] + m[4]*m[11]*m[14] + m[8]*m[6]*m[15] - m[8]*m[7]*m[14] - m[12]*m[6]*m[11] + m[12]*m[7]*m[10]
+	inv[8] = m[4]*m[9]*m[15] - m[4]*m[11]*m[13] - m[8]*m[5]*m[15] + m[8]*m[7]*m[13] + m[12]*m[5]*m[11] - m[12]*m[7]*m[9]
+	inv[12] = -m[4]*m[9]*m[14] + m[4]*m[10]*m[13] + m[8]*m[5]*m[14] - m[8]*m[6]*m[13] - m[12]*m[5]*m[10] + m[12]*m[6]*m[9]
+	inv[1] = -m[1]*m[10]*m[15] + m[1]*m[11]*m[14] + m[9]*m[2]*m[15] - m[9]*m[3]*m[14] - m[13]*m[2]*m[11] + m[13]*m[3]*m[10]
+	inv[5] = m[0]*m[10]*m[15] - m[0]*m[11]*m[14] - m[8]*m[2]*m[15] + m[8]*m[3]*m[14] + m[12]*m[2]*m[11] - m[12]*m[3]*m[10]
+	inv[9] = -m[0]*m[9]*m[15] + m[0]*m[11]*m[13] + m[8]*m[1]*m[15] - m[8]*m[3]*m[13] - m[12]*m[1]*m[11] + m[12]*m[3]*m[9]
+	inv[13] = m[0]*m[9]*m[14] - m[0]*m[10]*m[13] - m[8]*m[1]*m[14] + m[8]*m[2]*m[13] + m[12]*m[1]*m[10] - m[12]*m[2]*m[9]
+	inv[2] = m[1]*m[6]*m[15] - m[1]*m[7]*m[14] - m[5]*m[2]*m[15] + m[5]*m[3]*m[14] + m[13]*m[2]*m[7] - m[13]*m[3]*m[6]
+	inv[6] = -m[0]*m[6]*m[15] + m[0]*m[7]*m[14] + m[4]*m[2]*m[15] - m[4]*m[3]*m[14] - m[12]*m[2]*m[7] + m[12]*m[3]*m[6]
+	inv[10] = m[0]*m[5]*m[15] - m[0]*m[7]*m[13] - m[4]*m[1]*m[15] + m[4]*m[3]*m[13] + m[12]*m[1]*m[7] - m[12]*m[3]*m[5]
+	inv[14] = -m[0]*m[5]*m[14] + m[0]*m[6]*m[13] + m[4]*m[1]*m[14] - m[4]*m[2]*m[13] - m[12]*m[1]*m[6] + m[12]*m[2]*m[5]
+	inv[3] = -m[1]*m[6]*m[11] + m[1]*m[7]*m[10] + m[5]*m[2]*m[11] - m[5]*m[3]*m[10] - m[9]*m[2]*m[7] + m[9]*m[3]*m[6]
+	inv[7] = m[0]*m[6]*m[11] - m[0]*m[7]*m[10] - m[4]*m[2]*m[11] + m[4]*m[3]*m[10] + m[8]*m[2]*m[7] - m[8]*m[3]*m[6]
+	inv[11] = -m[0]*m[5]*m[11] + m[0]*m[7]*m[9] + m[4]*m[1]*m[11] - m[4]*m[3]*m[9] - m[8]*m[1]*m[7] + m[8]*m[3]*m[5]
+	inv[15] = m[0]*m[5]*m[10] - m[0]*m[6]*m[9] - m[4]*m[1]*m[10] + m[4]*m[2]*m[9] + m[8]*m[1]*m[6] - m[8]*m[2]*m[5]
+	
+	det := m[0]*inv[0] + m[1]*inv[4] + m[2]*inv[8] + m[3]*inv[12]
+	if det == 0 {
+		return NewMatrix4Identity(), false
+	}
+	
+	for i := range inv {
+		inv[i] /= det
+	}
+	return inv, true
+}
+
+// Decompose splits a translation * rotation * scale matrix, with rotation
+// as Euler angles in radians applied X then Y then Z. Shear from
+// non-uniform scaling under rotation is lost.
+func (m Matrix4) Decompose() (translation, rotation, scale Vector3) {
+	translation = Vector3{X: m[3], Y: m[7], Z: m[11]}
+	
+	axisX := Vector3{X: m[0], Y: m[4], Z: m[8]}
+	axisY := Vector3{X: m[1], Y: m[5], Z: m[9]}
+	axisZ := Vector3{X: m[2], Y: m[6], Z: m[10]}
+	scale = Vector3{X: axisX.Length(), Y: axisY.Length(), Z: axisZ.Length()}
+	// A mirrored basis is put down to a negative X scale
+	if axisX.Cross(axisY).Dot(axisZ) < 0 {
+		scale.X = -scale.X
+	}
+	if scale.X == 0 || scale.Y == 0 || scale.Z == 0 {
+		return translation, Vector3{}, scale
+	}
+	axisX = axisX.Div(scale.X)
+	axisY = axisY.Div(scale.Y)
+	axisZ = axisZ.Div(scale.Z)
+	
+	// Rz * Ry * Rx has -sin(y) in its bottom-left corner
+	sinY := Clamp(-axisX.Z, -1, 1)
+	rotation.Y = float32(math.Asin(float64(sinY)))
+	if Abs(sinY) < 0.99999 {
+		rotation.X = float32(math.Atan2(float64(axisY.Z), float64(axisZ.Z)))
+		rotation.Z = float32(math.Atan2(float64(axisX.Y), float64(axisX.X)))
+	} else {
+		// Gimbal lock: X and Z turn about the same axis, so Z takes it all
+		rotation.Z = float32(math.Atan2(float64(-axisY.X), float64(axisY.Y)))
+	}
+	return translation, rotation, scale
+}
+
 func NewTranslationMatrix(x, y, z float32) Matrix4 {
 	return Matrix4{
 		1, 0, 0, x,
@@ -0,0 +1,121 @@
+package math
+
+import "testing"
+
+func approxMatrix(a, b Matrix4) bool {
+	for i := range a {
+		if Abs(a[i]-b[i]) > 1e-4 {
+			return false
+		}
+	}
+	return true
+}
+
+// compose builds translation * rotation * scale with the rotation applied
+// X then Y then Z, the form Decompose takes apart
+func compose(translation, rotation, scale Vector3) Matrix4 {
+	r := NewRotationZ(rotation.Z).Multiply(NewRotationY(rotation.Y)).Multiply(NewRotationX(rotation.X))
+	return NewTranslationMatrix(translation.X, translation.Y, translation.Z).
+		Multiply(r).
+		Multiply(NewScaleMatrix(scale.X, scale.Y, scale.Z))
+}
+
+func TestInverse(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Matrix4
+	}{
+		{"identity", NewMatrix4Identity()},
+		{"translation", NewTranslationMatrix(3, -4, 5)},
+		{"non-uniform scale", NewScaleMatrix(2, 0.5, -3)},
+		{"rotation, scale and translation", compose(Vector3{1, 2, 3}, Vector3{0.3, -1.1, 2}, Vector3{2, 3, 0.25})},
+		{"perspective", NewPerspective(Radians(60), 16.0/9.0, 0.1, 100)},
+		{"look-at", NewLookAt(Vector3{4, 3, 2}, Vector3{0, 1, 0}, Vector3{0, 1, 0})},
+	}
+
+	identity := NewMatrix4Identity()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inverse, ok := tt.m.Inverse()
+			if !ok {
+				t.Fatal("reported singular")
+			}
+			if got := inverse.Multiply(tt.m); !approxMatrix(got, identity) {
+				t.Errorf("inverse * m = %v", got)
+			}
+			if got := tt.m.Multiply(inverse); !approxMatrix(got, identity) {
+				t.Errorf("m * inverse = %v", got)
+			}
+		})
+	}
+}
+
+func TestInverseSingular(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Matrix4
+	}{
+		{"zero", Matrix4{}},
+		{"flattened", NewScaleMatrix(1, 0, 1)},
+		{"flattened and rotated", NewRotationY(0.5).Multiply(NewScaleMatrix(2, 2, 0)).Multiply(NewTranslationMatrix(1, 2, 3))},
+		{"repeated row", Matrix4{1, 2, 3, 4, 1, 2, 3, 4, 0, 0, 1, 0, 0, 0, 0, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inverse, ok := tt.m.Inverse()
+			if ok {
+				t.Errorf("inverted a singular matrix to %v", inverse)
+			}
+			if inverse != NewMatrix4Identity() {
+				t.Errorf("singular inverse = %v, want the identity", inverse)
+			}
+		})
+	}
+}
+
+func TestDecompose(t *testing.T) {
+	tests := []struct {
+		name                         string
+		translation, rotation, scale Vector3
+	}{
+		{"identity", Vector3{}, Vector3{}, Vector3{1, 1, 1}},
+		{"translation", Vector3{1, -2, 3}, Vector3{}, Vector3{1, 1, 1}},
+		{"rotation about each axis", Vector3{}, Vector3{0.4, -0.7, 1.2}, Vector3{1, 1, 1}},
+		{"non-uniform scale", Vector3{}, Vector3{}, Vector3{2, 0.5, 4}},
+		{"all together", Vector3{5, 0, -1}, Vector3{-2.5, 1.1, 0.3}, Vector3{0.5, 3, 1.5}},
+		{"mirrored", Vector3{0, 1, 0}, Vector3{0.2, 0.3, -0.4}, Vector3{-2, 1, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := compose(tt.translation, tt.rotation, tt.scale)
+			translation, rotation, scale := m.Decompose()
+			if !approx(translation, tt.translation) || !approx(rotation, tt.rotation) || !approx(scale, tt.scale) {
+				t.Errorf("Decompose = %v, %v, %v; want %v, %v, %v",
+					translation, rotation, scale, tt.translation, tt.rotation, tt.scale)
+			}
+			if got := compose(translation, rotation, scale); !approxMatrix(got, m) {
+				t.Errorf("recomposed to %v, want %v", got, m)
+			}
+		})
+	}
+}
+
+func TestDecomposeGimbalLock(t *testing.T) {
+	// Turned a quarter about Y, X and Z turn about the same axis, so the
+	// angles are not unique but the matrix must come back the same
+	m := compose(Vector3{1, 2, 3}, Vector3{0.5, HalfPi, 0.25}, Vector3{2, 1, 3})
+	translation, rotation, scale := m.Decompose()
+	if got := compose(translation, rotation, scale); !approxMatrix(got, m) {
+		t.Errorf("recomposed to %v, want %v", got, m)
+	}
+}
+
+func TestDecomposeZeroScale(t *testing.T) {
+	m := compose(Vector3{1, 2, 3}, Vector3{0.5, 0, 0}, Vector3{1, 0, 2})
+	translation, rotation, scale := m.Decompose()
+	if !approx(translation, Vector3{1, 2, 3}) || rotation != (Vector3{}) || scale.Y != 0 {
+		t.Errorf("Decompose = %v, %v, %v", translation, rotation, scale)
+	}
+}
@@ -0,0 +1,337 @@
+// Package atlas packs images into a single texture atlas and describes
+// where each one went in JSON metadata. It only depends on the standard
+// library, so atlases can be built and inspected without a GPU.
+package atlas
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Options controls atlas building
+type Options struct {
+	// MaxSize is the largest width and height the atlas may grow to
+	MaxSize int
+	// Padding is the number of transparent pixels between images, which
+	// keeps filtering from bleeding neighbours into each other
+	Padding int
+	// PowerOfTwo restricts the atlas size to powers of two
+	PowerOfTwo bool
+}
+
+// DefaultOptions returns options suiting most sprite sheets
+func DefaultOptions() Options {
+	return Options{MaxSize: 4096, Padding: 2, PowerOfTwo: true}
+}
+
+// Frame is the place of one source image in the atlas
+type Frame struct {
+	Name string `json:"name"`
+	X    int    `json:"x"`
+	Y    int    `json:"y"`
+	W    int    `json:"w"`
+	H    int    `json:"h"`
+}
+
+// Region returns the frame as x, y, width, height
+func (f Frame) Region() [4]int {
+	return [4]int{f.X, f.Y, f.W, f.H}
+}
+
+// Metadata is the JSON description written next to an atlas image
+type Metadata struct {
+	// Image is the atlas image path, relative to the metadata file when
+	// saved and resolved against it by LoadMetadata
+	Image  string  `json:"image"`
+	Width  int     `json:"width"`
+	Height int     `json:"height"`
+	Frames []Frame `json:"frames"`
+}
+
+// Atlas is a packed image with the frames placed in it
+type Atlas struct {
+	Image *image.RGBA
+	Metadata
+}
+
+// Source is an image to pack under a name
+type Source struct {
+	Name  string
+	Image image.Image
+}
+
+// Build packs the images into the smallest atlas that fits, trying
+// growing sizes up to MaxSize
+func Build(sources []Source, options Options) (*Atlas, error) {
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no images to pack")
+	}
+	if options.MaxSize <= 0 {
+		options.MaxSize = DefaultOptions().MaxSize
+	}
+
+	// Large images first pack tighter; names break ties so the result
+	// does not depend on input order
+	order := make([]int, len(sources))
+	area := 0
+	for i, src := range sources {
+		order[i] = i
+		size := src.Image.Bounds().Size()
+		if size.X > options.MaxSize || size.Y > options.MaxSize {
+			return nil, fmt.Errorf("image %s (%dx%d) does not fit in %d pixels", src.Name, size.X, size.Y, options.MaxSize)
+		}
+		area += (size.X + options.Padding) * (size.Y + options.Padding)
+	}
+	sort.Slice(order, func(i, j int) bool {
+		a, b := sources[order[i]], sources[order[j]]
+		sa, sb := a.Image.Bounds().Size(), b.Image.Bounds().Size()
+		if max(sa.X, sa.Y) != max(sb.X, sb.Y) {
+			return max(sa.X, sa.Y) > max(sb.X, sb.Y)
+		}
+		return a.Name < b.Name
+	})
+
+	width, height := initialSize(area, options)
+	for {
+		if placed, ok := pack(sources, order, width, height, options.Padding); ok {
+			return compose(sources, placed, width, height), nil
+		}
+		if width >= options.MaxSize && height >= options.MaxSize {
+			return nil, fmt.Errorf("%d images do not fit in %dx%d", len(sources), options.MaxSize, options.MaxSize)
+		}
+		width, height = grow(width, height, options)
+	}
+}
+
+func initialSize(area int, options Options) (int, int) {
+	side := 1
+	for side*side < area {
+		side++
+	}
+	if options.PowerOfTwo {
+		side = nextPowerOfTwo(side)
+	}
+	side = min(side, options.MaxSize)
+	return side, side
+}
+
+// grow enlarges the shorter side, doubling for power-of-two atlases and
+// by a quarter otherwise
+func grow(width, height int, options Options) (int, int) {
+	step := func(v int) int {
+		if options.PowerOfTwo {
+			return min(v*2, options.MaxSize)
+		}
+		return min(v+max(v/4, 1), options.MaxSize)
+	}
+	if width <= height && width < options.MaxSize {
+		return step(width), height
+	}
+	return width, step(height)
+}
+
+func nextPowerOfTwo(v int) int {
+	p := 1
+	for p < v {
+		p *= 2
+	}
+	return p
+}
+
+func pack(sources []Source, order []int, width, height, padding int) ([]image.Rectangle, bool) {
+	// Padding goes after each image, so the bin is one padding larger
+	// than the atlas for the last row and column to overhang
+	packer := NewPacker(width+padding, height+padding)
+	placed := make([]image.Rectangle, len(sources))
+	for _, i := range order {
+		size := sources[i].Image.Bounds().Size()
+		rect, ok := packer.Insert(size.X+padding, size.Y+padding)
+		if !ok {
+			return nil, false
+		}
+		placed[i] = image.Rectangle{Min: rect.Min, Max: rect.Min.Add(size)}
+	}
+	return placed, true
+}
+
+func compose(sources []Source, placed []image.Rectangle, width, height int) *Atlas {
+	a := &Atlas{
+		Image: image.NewRGBA(image.Rect(0, 0, width, height)),
+		Metadata: Metadata{
+			Width:  width,
+			Height: height,
+			Frames: make([]Frame, len(sources)),
+		},
+	}
+	for i, src := range sources {
+		rect := placed[i]
+		draw.Draw(a.Image, rect, src.Image, src.Image.Bounds().Min, draw.Src)
+		a.Frames[i] = Frame{Name: src.Name, X: rect.Min.X, Y: rect.Min.Y, W: rect.Dx(), H: rect.Dy()}
+	}
+	sort.Slice(a.Frames, func(i, j int) bool { return a.Frames[i].Name < a.Frames[j].Name })
+	return a
+}
+
+// BuildFromDir packs every PNG under dir. Frames are named by their path
+// relative to dir, with forward slashes and without the extension.
+func BuildFromDir(dir string, options Options) (*Atlas, error) {
+	var sources []Source
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(path), ".png") {
+			return nil
+		}
+
+		img, err := loadPNG(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(filepath.ToSlash(rel), filepath.Ext(rel))
+		sources = append(sources, Source{Name: name, Image: img})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read images: %w", err)
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no PNG images in %s", dir)
+	}
+	return Build(sources, options)
+}
+
+func loadPNG(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return img, nil
+}
+
+// Save writes the atlas image as PNG and its metadata as JSON, recording
+// the image path relative to the metadata file
+func (a *Atlas) Save(imagePath, metadataPath string) error {
+	file, err := os.Create(imagePath)
+	if err != nil {
+		return fmt.Errorf("failed to create atlas image: %w", err)
+	}
+	if err := png.Encode(file, a.Image); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to encode atlas image: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write atlas image: %w", err)
+	}
+
+	meta := a.Metadata
+	meta.Image = filepath.Base(imagePath)
+	if rel, err := filepath.Rel(filepath.Dir(metadataPath), imagePath); err == nil {
+		meta.Image = filepath.ToSlash(rel)
+	}
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode atlas metadata: %w", err)
+	}
+	if err := os.WriteFile(metadataPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write atlas metadata: %w", err)
+	}
+	return nil
+}
+
+// LoadMetadata reads atlas metadata, resolving the image path against the
+// metadata file's directory
+func LoadMetadata(path string) (*Metadata, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read atlas metadata: %w", err)
+	}
+
+	var meta Metadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse atlas metadata %s: %w", path, err)
+	}
+	if meta.Image != "" && !filepath.IsAbs(meta.Image) {
+		meta.Image = filepath.Join(filepath.Dir(path), filepath.FromSlash(meta.Image))
+	}
+	return &meta, nil
+}
+
+// Frame returns a frame by name
+func (m *Metadata) Frame(name string) (Frame, bool) {
+	for _, frame := range m.Frames {
+		if frame.Name == name {
+			return frame, true
+		}
+	}
+	return Frame{}, false
+}
+
+// Sequence returns the frames of an animation named by a common prefix,
+// ordered by their trailing number: "walk" matches walk_0, walk-1, walk2
+// and so on, but not walking_0
+func (m *Metadata) Sequence(prefix string) []Frame {
+	type numbered struct {
+		frame  Frame
+		number int
+	}
+	var matches []numbered
+	for _, frame := range m.Frames {
+		base, number, ok := splitNumber(frame.Name)
+		if ok && base == prefix {
+			matches = append(matches, numbered{frame: frame, number: number})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].number < matches[j].number })
+
+	frames := make([]Frame, len(matches))
+	for i, match := range matches {
+		frames[i] = match.frame
+	}
+	return frames
+}
+
+// Regions returns the regions of frames, as animation components use them
+func Regions(frames []Frame) [][4]int {
+	regions := make([][4]int, len(frames))
+	for i, frame := range frames {
+		regions[i] = frame.Region()
+	}
+	return regions
+}
+
+// splitNumber splits "walk_12" into "walk" and 12
+func splitNumber(name string) (string, int, bool) {
+	end := len(name)
+	start := end
+	for start > 0 && name[start-1] >= '0' && name[start-1] <= '9' {
+		start--
+	}
+	if start == end {
+		return "", 0, false
+	}
+	number, err := strconv.Atoi(name[start:end])
+	if err != nil {
+		return "", 0, false
+	}
+	return strings.TrimRight(name[:start], "_-. "), number, true
+}
@@ -0,0 +1,247 @@
+package atlas
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// solid is a w x h image filled with one color, so a frame's pixels tell
+// which source landed there
+func solid(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+// sources builds n images of pseudo-random sizes with distinct colors
+func sources(n int, seed int64) []Source {
+	rng := rand.New(rand.NewSource(seed))
+	list := make([]Source, n)
+	for i := range list {
+		c := color.RGBA{uint8(i + 1), uint8(255 - i), uint8(i * 7), 255}
+		list[i] = Source{Name: fmt.Sprintf("sprite_%d", i), Image: solid(1+rng.Intn(40), 1+rng.Intn(40), c)}
+	}
+	return list
+}
+
+func TestPackerInsert(t *testing.T) {
+	tests := []struct {
+		name   string
+		bin    [2]int
+		rects  [][2]int
+		placed int
+	}{
+		{"exact fit", [2]int{8, 8}, [][2]int{{8, 8}}, 1},
+		{"four quarters", [2]int{8, 8}, [][2]int{{4, 4}, {4, 4}, {4, 4}, {4, 4}}, 4},
+		{"too wide", [2]int{8, 8}, [][2]int{{9, 1}}, 0},
+		{"full bin", [2]int{8, 8}, [][2]int{{8, 4}, {8, 4}, {1, 1}}, 2},
+		{"empty", [2]int{8, 8}, [][2]int{{0, 4}}, 0},
+		{"mixed", [2]int{16, 16}, [][2]int{{10, 6}, {6, 10}, {6, 6}, {4, 4}, {10, 4}}, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPacker(tt.bin[0], tt.bin[1])
+			bounds := image.Rect(0, 0, tt.bin[0], tt.bin[1])
+			var placed []image.Rectangle
+			for _, size := range tt.rects {
+				rect, ok := p.Insert(size[0], size[1])
+				if !ok {
+					continue
+				}
+				if rect.Dx() != size[0] || rect.Dy() != size[1] {
+					t.Errorf("placed %v for %dx%d", rect, size[0], size[1])
+				}
+				if !rect.In(bounds) {
+					t.Errorf("%v outside the %v bin", rect, bounds)
+				}
+				for _, other := range placed {
+					if rect.Overlaps(other) {
+						t.Errorf("%v overlaps %v", rect, other)
+					}
+				}
+				placed = append(placed, rect)
+			}
+			if len(placed) != tt.placed {
+				t.Errorf("placed %d rectangles, want %d", len(placed), tt.placed)
+			}
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		count   int
+		options Options
+	}{
+		{"defaults", 30, DefaultOptions()},
+		{"no padding", 30, Options{MaxSize: 1024}},
+		{"any size", 30, Options{MaxSize: 1024, Padding: 1}},
+		{"single", 1, DefaultOptions()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := sources(tt.count, 1)
+			a, err := Build(list, tt.options)
+			if err != nil {
+				t.Fatalf("Build: %v", err)
+			}
+			if len(a.Frames) != len(list) {
+				t.Fatalf("got %d frames, want %d", len(a.Frames), len(list))
+			}
+			if tt.options.PowerOfTwo && (a.Width&(a.Width-1) != 0 || a.Height&(a.Height-1) != 0) {
+				t.Errorf("atlas %dx%d is not a power of two", a.Width, a.Height)
+			}
+
+			bounds := image.Rect(0, 0, a.Width, a.Height)
+			byName := make(map[string]Source)
+			for _, src := range list {
+				byName[src.Name] = src
+			}
+			for i, frame := range a.Frames {
+				rect := image.Rect(frame.X, frame.Y, frame.X+frame.W, frame.Y+frame.H)
+				if !rect.In(bounds) {
+					t.Errorf("frame %s %v outside the atlas %v", frame.Name, rect, bounds)
+				}
+				// Padding keeps frames apart as well as not overlapping
+				padded := image.Rect(rect.Min.X, rect.Min.Y, rect.Max.X+tt.options.Padding, rect.Max.Y+tt.options.Padding)
+				for _, other := range a.Frames[i+1:] {
+					if padded.Overlaps(image.Rect(other.X, other.Y, other.X+other.W, other.Y+other.H)) {
+						t.Errorf("frame %s overlaps %s", frame.Name, other.Name)
+					}
+				}
+
+				src := byName[frame.Name]
+				if rect.Size() != src.Image.Bounds().Size() {
+					t.Errorf("frame %s is %v, source is %v", frame.Name, rect.Size(), src.Image.Bounds().Size())
+				}
+				if got, want := a.Image.At(frame.X, frame.Y), src.Image.At(0, 0); got != want {
+					t.Errorf("frame %s holds %v, want %v", frame.Name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildDeterministic(t *testing.T) {
+	list := sources(25, 7)
+	first, err := Build(list, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	// The layout depends on the images, not the order they are given in
+	for seed := int64(0); seed < 5; seed++ {
+		shuffled := append([]Source(nil), list...)
+		rand.New(rand.NewSource(seed)).Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+		again, err := Build(shuffled, DefaultOptions())
+		if err != nil {
+			t.Fatalf("Build: %v", err)
+		}
+		if !reflect.DeepEqual(first.Metadata, again.Metadata) {
+			t.Fatalf("layout changed with input order %d", seed)
+		}
+		if !reflect.DeepEqual(first.Image.Pix, again.Image.Pix) {
+			t.Fatalf("image changed with input order %d", seed)
+		}
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		sources []Source
+		options Options
+	}{
+		{"no images", nil, DefaultOptions()},
+		{"image too large", []Source{{Name: "big", Image: solid(65, 8, color.RGBA{})}}, Options{MaxSize: 64}},
+		{"images do not fit", sources(20, 3), Options{MaxSize: 32}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Build(tt.sources, tt.options); err == nil {
+				t.Error("Build succeeded")
+			}
+		})
+	}
+}
+
+func TestMetadataRoundTrip(t *testing.T) {
+	a, err := Build(sources(6, 2), DefaultOptions())
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	dir := t.TempDir()
+	imagePath := filepath.Join(dir, "images", "atlas.png")
+	metadataPath := filepath.Join(dir, "atlas.json")
+	if err := os.MkdirAll(filepath.Dir(imagePath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Save(imagePath, metadataPath); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	meta, err := LoadMetadata(metadataPath)
+	if err != nil {
+		t.Fatalf("LoadMetadata: %v", err)
+	}
+	// The image path is stored relative and resolved on load
+	if meta.Image != imagePath {
+		t.Errorf("image = %q, want %q", meta.Image, imagePath)
+	}
+	want := a.Metadata
+	want.Image = imagePath
+	if !reflect.DeepEqual(*meta, want) {
+		t.Errorf("metadata = %+v, want %+v", *meta, want)
+	}
+
+	img, err := loadPNG(meta.Image)
+	if err != nil {
+		t.Fatalf("loadPNG: %v", err)
+	}
+	for _, frame := range meta.Frames {
+		if got, want := color.RGBAModel.Convert(img.At(frame.X, frame.Y)), a.Image.At(frame.X, frame.Y); got != want {
+			t.Errorf("frame %s pixel = %v, want %v", frame.Name, got, want)
+		}
+	}
+}
+
+func TestSequence(t *testing.T) {
+	meta := &Metadata{Frames: []Frame{
+		{Name: "walk_10", X: 10},
+		{Name: "walk_2", X: 2},
+		{Name: "walk-1", X: 1},
+		{Name: "walking_0", X: 100},
+		{Name: "walk", X: 200},
+		{Name: "run_0", X: 300},
+	}}
+
+	got := meta.Sequence("walk")
+	var xs []int
+	for _, frame := range got {
+		xs = append(xs, frame.X)
+	}
+	if want := []int{1, 2, 10}; !reflect.DeepEqual(xs, want) {
+		t.Errorf("walk frames at %v, want %v", xs, want)
+	}
+	if regions := Regions(got); regions[0] != [4]int{1, 0, 0, 0} {
+		t.Errorf("regions = %v", regions)
+	}
+	if frames := meta.Sequence("jump"); len(frames) != 0 {
+		t.Errorf("jump = %v, want none", frames)
+	}
+}
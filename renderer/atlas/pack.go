@@ -0,0 +1,100 @@
+package atlas
+
+import (
+	"image"
+)
+
+// Packer places rectangles in a fixed-size bin with the MaxRects
+// algorithm: it keeps the maximal free rectangles left in the bin and puts
+// each new rectangle where it leaves the shortest leftover side.
+type Packer struct {
+	width  int
+	height int
+	free   []image.Rectangle
+}
+
+// NewPacker creates an empty bin
+func NewPacker(width, height int) *Packer {
+	return &Packer{
+		width:  width,
+		height: height,
+		free:   []image.Rectangle{image.Rect(0, 0, width, height)},
+	}
+}
+
+// Insert finds a place for a width x height rectangle and reserves it
+func (p *Packer) Insert(width, height int) (image.Rectangle, bool) {
+	if width <= 0 || height <= 0 {
+		return image.Rectangle{}, false
+	}
+
+	best := -1
+	bestShort, bestLong := 0, 0
+	for i, free := range p.free {
+		if width > free.Dx() || height > free.Dy() {
+			continue
+		}
+		leftoverX := free.Dx() - width
+		leftoverY := free.Dy() - height
+		short, long := min(leftoverX, leftoverY), max(leftoverX, leftoverY)
+		if best < 0 || short < bestShort || (short == bestShort && long < bestLong) {
+			best, bestShort, bestLong = i, short, long
+		}
+	}
+	if best < 0 {
+		return image.Rectangle{}, false
+	}
+
+	placed := image.Rectangle{Min: p.free[best].Min, Max: p.free[best].Min.Add(image.Pt(width, height))}
+	p.split(placed)
+	p.prune()
+	return placed, true
+}
+
+// split replaces every free rectangle overlapping placed with the up to
+// four maximal rectangles around it
+func (p *Packer) split(placed image.Rectangle) {
+	var next []image.Rectangle
+	for _, free := range p.free {
+		if !free.Overlaps(placed) {
+			next = append(next, free)
+			continue
+		}
+		if placed.Min.X > free.Min.X {
+			next = append(next, image.Rect(free.Min.X, free.Min.Y, placed.Min.X, free.Max.Y))
+		}
+		if placed.Max.X < free.Max.X {
+			next = append(next, image.Rect(placed.Max.X, free.Min.Y, free.Max.X, free.Max.Y))
+		}
+		if placed.Min.Y > free.Min.Y {
+			next = append(next, image.Rect(free.Min.X, free.Min.Y, free.Max.X, placed.Min.Y))
+		}
+		if placed.Max.Y < free.Max.Y {
+			next = append(next, image.Rect(free.Min.X, placed.Max.Y, free.Max.X, free.Max.Y))
+		}
+	}
+	p.free = next
+}
+
+// prune drops free rectangles contained in another one
+func (p *Packer) prune() {
+	kept := make([]image.Rectangle, 0, len(p.free))
+	for i, a := range p.free {
+		contained := false
+		for j, b := range p.free {
+			if i == j || !a.In(b) {
+				continue
+			}
+			// Of two identical rectangles keep the first
+			if a == b && i < j {
+				continue
+			}
+			contained = true
+			break
+		}
+		if !contained {
+			kept = append(kept, a)
+		}
+	}
+	p.free = kept
+}
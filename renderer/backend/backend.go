@@ -0,0 +1,88 @@
+package backend
+
+import (
+	"image"
+
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+)
+
+// MeshHandle identifies a mesh owned by a backend
+type MeshHandle uint32
+
+// InvalidMesh is never returned by CreateMesh
+const InvalidMesh MeshHandle = 0
+
+// TextureHandle identifies a texture owned by a backend
+type TextureHandle uint32
+
+// InvalidTexture is never returned by CreateTexture or CreateCubemap
+const InvalidTexture TextureHandle = 0
+
+// Cubemap faces in the order +X, -X, +Y, -Y, +Z, -Z, each square with its
+// top row first, as OpenGL expects them
+type CubemapFaces [6]*image.RGBA
+
+// Instance is the per-instance data of an instanced draw. Model uses the
+// same layout as DrawMesh; Color tints the vertex colors.
+type Instance struct {
+	Model bmath.Matrix4
+	Color [4]float32
+}
+
+// SpriteVertex is a corner of a sprite quad. Texture coordinates have
+// their origin at the top-left of the image; Color tints the texel.
+type SpriteVertex struct {
+	X, Y, Z float32
+	U, V    float32
+	Color   [4]float32
+}
+
+// Backend is the set of operations the engine needs from a rendering API.
+//
+// Vertex data uses the engine's interleaved layout of position (x, y, z)
+// followed by color (r, g, b). Matrices are passed exactly as they are
+// uploaded to the GPU, so every backend must interpret them the same way.
+type Backend interface {
+	// CreateMesh uploads vertex data; indices may be nil for non-indexed meshes
+	CreateMesh(vertices []float32, indices []uint32) MeshHandle
+	DeleteMesh(mesh MeshHandle)
+	DrawMesh(mesh MeshHandle, model bmath.Matrix4)
+	// DrawMeshInstanced draws a mesh once per instance in a single call
+	DrawMeshInstanced(mesh MeshHandle, instances []Instance)
+	// DrawLines draws line segments from pairs of vertices in world space
+	DrawLines(vertices []float32, depthTest bool)
+	// MeshBounds returns the local-space bounds of a mesh's positions
+	MeshBounds(mesh MeshHandle) bmath.AABB
+
+	// CreateTexture uploads an image as a 2D texture, filtered linearly
+	// when smooth is set and with nearest filtering otherwise
+	CreateTexture(img *image.RGBA, smooth bool) TextureHandle
+	// CreateDistanceFieldTexture uploads a signed distance field, filtered
+	// linearly, whose alpha is 0.5 on shape edges. DrawSprites draws it as
+	// a sharp-edged mask tinted by the vertex colors.
+	CreateDistanceFieldTexture(img *image.RGBA) TextureHandle
+	// UpdateTexture replaces the pixels of a 2D texture with an image of
+	// the same size
+	UpdateTexture(texture TextureHandle, img *image.RGBA)
+	CreateCubemap(faces CubemapFaces) TextureHandle
+	DeleteTexture(texture TextureHandle)
+	// DrawSkybox fills every pixel still at the far plane with the cubemap
+	// as seen from the camera's orientation; it does not write depth
+	DrawSkybox(cubemap TextureHandle)
+	// DrawSprites draws textured quads from groups of four vertices, in
+	// order around the quad, alpha blended and without depth testing
+	DrawSprites(texture TextureHandle, vertices []SpriteVertex)
+
+	SetCamera(view, projection bmath.Matrix4)
+	SetViewport(width, height int)
+	SetClearColor(r, g, b, a float32)
+	// SetDepthWrite controls whether draws update the depth buffer
+	SetDepthWrite(enabled bool)
+	// SetViewMode changes how subsequent mesh draws are shaded; it stays in
+	// effect until changed, so each viewport can set its own before drawing
+	SetViewMode(mode ViewMode)
+
+	BeginFrame()
+	EndFrame()
+	Cleanup()
+}
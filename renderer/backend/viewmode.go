@@ -0,0 +1,69 @@
+package backend
+
+import (
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+)
+
+// ViewMode selects how meshes are shaded. Lines are always drawn as
+// submitted so grids and debug draw stay readable in every mode.
+type ViewMode int
+
+const (
+	// ViewShaded draws meshes with their vertex colors
+	ViewShaded ViewMode = iota
+	// ViewWireframe draws only triangle edges
+	ViewWireframe
+	// ViewWireframeShaded draws triangle edges over the shaded meshes
+	ViewWireframeShaded
+	// ViewNormals colors surfaces by their world-space normal. The vertex
+	// layout has no normals, so the face normal is shown.
+	ViewNormals
+	// ViewUVChecker draws a checker pattern. The vertex layout has no
+	// texture coordinates, so the pattern is box-projected in object space.
+	ViewUVChecker
+	// ViewDepth shows linear view depth between the camera's near (white)
+	// and far (black) planes
+	ViewDepth
+	// ViewOverdraw accumulates a heat color for every fragment, ignoring
+	// depth, so pixels shaded many times show up bright
+	ViewOverdraw
+)
+
+var viewModeNames = [...]string{
+	"Shaded",
+	"Wireframe",
+	"Wireframe + Shaded",
+	"Normals",
+	"UV Checker",
+	"Depth",
+	"Overdraw",
+}
+
+func (m ViewMode) String() string {
+	if m < 0 || int(m) >= len(viewModeNames) {
+		return "Unknown"
+	}
+	return viewModeNames[m]
+}
+
+// Next returns the mode after m, wrapping back to ViewShaded
+func (m ViewMode) Next() ViewMode {
+	return ViewMode((int(m) + 1) % len(viewModeNames))
+}
+
+// Overdraw heat added per fragment in ViewOverdraw; about ten layers
+// saturate the red channel
+var OverdrawColor = [4]float32{0.1, 0.04, 0.02, 1}
+
+// WireframeColor is the edge color used by ViewWireframeShaded
+var WireframeColor = [4]float32{0.05, 0.05, 0.05, 1}
+
+// NearFar recovers the near and far clip distances from a perspective
+// projection built by math.NewPerspective
+func NearFar(projection bmath.Matrix4) (near, far float32) {
+	a, b := projection[10], projection[11]
+	if a == 1 || a == -1 {
+		return 0, 1
+	}
+	return b / (a - 1), b / (a + 1)
+}
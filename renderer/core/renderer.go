@@ -2,10 +2,18 @@ package core
 
 import (
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/javanhut/BifrostEngine/m/v2/camera"
 	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/backend"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/debugdraw"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/environment"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/meshgen"
 	"github.com/javanhut/BifrostEngine/m/v2/renderer/opengl"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/profiler"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/resource"
 	"github.com/javanhut/BifrostEngine/m/v2/renderer/window"
 )
 
@@ -13,7 +21,6 @@ type Renderer struct {
 	window  *window.Window
 	context *opengl.Context
 	shader  *opengl.Shader
-	lineShader *opengl.Shader
 	triangle *opengl.Mesh
 	cube     *opengl.Mesh
 	sphere   *opengl.Mesh
@@ -22,11 +29,38 @@ type Renderer struct {
 	triangleMesh *opengl.Mesh
 	pyramid  *opengl.Mesh
 	camera  *camera.Camera3D
-	gridMesh *opengl.Mesh
+	backend  *opengl.Backend
+	debugDraw *debugdraw.DebugDraw
+	meshHandles map[string]backend.MeshHandle
+	viewMode backend.ViewMode
+	skyboxes *environment.Cache
+	skybox   backend.TextureHandle
+	skyboxPath string
+	resources []resource.ID
+	debugLogger *opengl.DebugLogger
+	profiler *profiler.Profiler
+}
+
+// Options configures renderer creation
+type Options struct {
+	// Debug enables GL error reporting and object labels for capture tools
+	Debug bool
+	// DebugLogger receives GL debug messages; a logger writing to standard
+	// output is used when nil
+	DebugLogger *opengl.DebugLogger
+}
+
+// DefaultOptions turns debug output on when BIFROST_GL_DEBUG is set
+func DefaultOptions() Options {
+	return Options{Debug: os.Getenv("BIFROST_GL_DEBUG") != ""}
 }
 
 func New(width, height int, title string) (*Renderer, error) {
-	win, err := window.New(width, height, title)
+	return NewWithOptions(width, height, title, DefaultOptions())
+}
+
+func NewWithOptions(width, height int, title string, options Options) (*Renderer, error) {
+	win, err := window.NewWithDebugContext(width, height, title, options.Debug)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create window: %w", err)
 	}
@@ -37,18 +71,21 @@ func New(width, height int, title string) (*Renderer, error) {
 		return nil, fmt.Errorf("failed to create OpenGL context: %w", err)
 	}
 
+	// Debug output goes on before any objects exist so they all get labels
+	debugLogger := options.DebugLogger
+	if options.Debug {
+		if debugLogger == nil {
+			debugLogger = opengl.NewDebugLogger(nil)
+		}
+		mode := ctx.EnableDebug(debugLogger)
+		fmt.Printf("OpenGL debug output: %v\n", mode)
+	}
+
 	shader, err := opengl.NewShader(opengl.DefaultVertexShader, opengl.DefaultFragmentShader)
 	if err != nil {
 		win.Destroy()
 		return nil, fmt.Errorf("failed to create shader: %w", err)
 	}
-	
-	// Create line shader for grid
-	lineShader, err := opengl.NewShader(opengl.DefaultVertexShader, opengl.DefaultFragmentShader)
-	if err != nil {
-		win.Destroy()
-		return nil, fmt.Errorf("failed to create line shader: %w", err)
-	}
 
 	// Triangle vertices: x, y, z, r, g, b
 	// Simple setup: triangle at origin
@@ -68,6 +105,41 @@ func New(width, height int, title string) (*Renderer, error) {
 	triangleMesh := opengl.NewTriangleMesh()
 	pyramid := opengl.NewPyramidMesh()
 
+	glBackend, err := opengl.NewBackend(ctx)
+	if err != nil {
+		win.Destroy()
+		return nil, fmt.Errorf("failed to create render backend: %w", err)
+	}
+
+	// Expose the built-in meshes to backend users by mesh type name
+	meshHandles := map[string]backend.MeshHandle{
+		"cube":     glBackend.AddMesh(cube),
+		"sphere":   glBackend.AddMesh(sphere),
+		"cylinder": glBackend.AddMesh(cylinder),
+		"plane":    glBackend.AddMesh(plane),
+		"triangle": glBackend.AddMesh(triangleMesh),
+		"pyramid":  glBackend.AddMesh(pyramid),
+	}
+
+	// The renderer's own objects are tracked alongside the backend's so
+	// anything not released at shutdown is reported
+	resources := []resource.ID{
+		glBackend.Track(resource.KindShader, "renderer", shader),
+		glBackend.Track(resource.KindMesh, "triangle", triangle),
+		glBackend.Track(resource.KindMesh, "cube", cube),
+		glBackend.Track(resource.KindMesh, "sphere", sphere),
+		glBackend.Track(resource.KindMesh, "cylinder", cylinder),
+		glBackend.Track(resource.KindMesh, "plane", plane),
+		glBackend.Track(resource.KindMesh, "triangleMesh", triangleMesh),
+		glBackend.Track(resource.KindMesh, "pyramid", pyramid),
+	}
+
+	// Generated meshes are owned by the backend
+	cone := meshgen.Cone(0.5, 1.0, 24)
+	meshHandles["cone"] = glBackend.CreateMesh(cone.PositionColor(), cone.Indices)
+	torus := meshgen.Torus(0.35, 0.15, 32, 16)
+	meshHandles["torus"] = glBackend.CreateMesh(torus.PositionColor(), torus.Indices)
+
 	// Create camera back at z=3 looking at origin (standard setup)
 	cameraPos := bmath.NewVector3(0, 0, 3)
 	cameraTarget := bmath.NewVector3(0, 0, 0)
@@ -84,7 +156,6 @@ func New(width, height int, title string) (*Renderer, error) {
 		window:  win,
 		context: ctx,
 		shader:  shader,
-		lineShader: lineShader,
 		triangle: triangle,
 		cube:     cube,
 		sphere:   sphere,
@@ -93,18 +164,58 @@ func New(width, height int, title string) (*Renderer, error) {
 		triangleMesh: triangleMesh,
 		pyramid:  pyramid,
 		camera:  cam,
+		backend:  glBackend,
+		meshHandles: meshHandles,
+		debugDraw: debugdraw.New(glBackend),
+		skyboxes: environment.NewCache(glBackend),
+		resources: resources,
+		debugLogger: debugLogger,
+		profiler: profiler.New(profiler.DefaultWindow),
 	}, nil
 }
 
 func (r *Renderer) BeginFrame() {
 	width, height := r.window.GetSize()
-	r.context.SetViewport(0, 0, int32(width), int32(height))
-	r.context.Clear(0.1, 0.1, 0.1, 1.0)
+	r.backend.SetViewport(width, height)
+	r.backend.BeginFrame()
 	
 	// Update camera aspect ratio if window resized
 	if width > 0 && height > 0 {
 		r.camera.SetAspect(float32(width) / float32(height))
 	}
+	
+	// The sky goes on the far plane without writing depth, so everything
+	// drawn afterwards covers it
+	if r.skybox != backend.InvalidTexture {
+		defer r.BeginPass("skybox")()
+		r.backend.SetCamera(r.camera.GetViewMatrix(), r.camera.GetProjectionMatrix())
+		r.backend.DrawSkybox(r.skybox)
+	}
+}
+
+func (r *Renderer) SetClearColor(red, green, blue, alpha float32) {
+	r.backend.SetClearColor(red, green, blue, alpha)
+}
+
+// SetSkybox shows a cubemap loaded by environment.Load behind the scene;
+// an empty path goes back to the clear color. Loaded skyboxes are cached,
+// and setting the current path again does nothing.
+func (r *Renderer) SetSkybox(path string) error {
+	if path == r.skyboxPath {
+		return nil
+	}
+	r.skyboxPath = path
+	r.skybox = backend.InvalidTexture
+	if path == "" {
+		return nil
+	}
+	
+	skybox, err := r.skyboxes.Get(path)
+	if err != nil {
+		return fmt.Errorf("failed to load skybox: %w", err)
+	}
+	r.skybox = skybox
+	return nil
 }
 
 func (r *Renderer) DrawTriangle() {
@@ -173,16 +284,7 @@ func (r *Renderer) DrawRotatingCube(time float32) {
 }
 
 func (r *Renderer) DrawCubeWithTransform(model bmath.Matrix4) {
-	r.shader.Use()
-	
-	view := r.camera.GetViewMatrix()
-	projection := r.camera.GetProjectionMatrix()
-	
-	r.shader.SetMatrix4("model", &model[0])
-	r.shader.SetMatrix4("view", &view[0])
-	r.shader.SetMatrix4("projection", &projection[0])
-	
-	r.cube.Draw()
+	r.drawWithTransform("cube", model)
 }
 
 func (r *Renderer) DrawTriangleWithTransform(model bmath.Matrix4) {
@@ -199,68 +301,62 @@ func (r *Renderer) DrawTriangleWithTransform(model bmath.Matrix4) {
 }
 
 func (r *Renderer) DrawSphereWithTransform(model bmath.Matrix4) {
-	r.shader.Use()
-	
-	view := r.camera.GetViewMatrix()
-	projection := r.camera.GetProjectionMatrix()
-	
-	r.shader.SetMatrix4("model", &model[0])
-	r.shader.SetMatrix4("view", &view[0])
-	r.shader.SetMatrix4("projection", &projection[0])
-	
-	r.sphere.Draw()
+	r.drawWithTransform("sphere", model)
 }
 
 func (r *Renderer) DrawCylinderWithTransform(model bmath.Matrix4) {
-	r.shader.Use()
-	
-	view := r.camera.GetViewMatrix()
-	projection := r.camera.GetProjectionMatrix()
-	
-	r.shader.SetMatrix4("model", &model[0])
-	r.shader.SetMatrix4("view", &view[0])
-	r.shader.SetMatrix4("projection", &projection[0])
-	
-	r.cylinder.Draw()
+	r.drawWithTransform("cylinder", model)
+}
+
+func (r *Renderer) DrawConeWithTransform(model bmath.Matrix4) {
+	r.drawWithTransform("cone", model)
+}
+
+func (r *Renderer) DrawTorusWithTransform(model bmath.Matrix4) {
+	r.drawWithTransform("torus", model)
 }
 
 func (r *Renderer) DrawPlaneWithTransform(model bmath.Matrix4) {
-	r.shader.Use()
-	
-	view := r.camera.GetViewMatrix()
-	projection := r.camera.GetProjectionMatrix()
-	
-	r.shader.SetMatrix4("model", &model[0])
-	r.shader.SetMatrix4("view", &view[0])
-	r.shader.SetMatrix4("projection", &projection[0])
-	
-	r.plane.Draw()
+	r.drawWithTransform("plane", model)
 }
 
 func (r *Renderer) DrawTriangleMeshWithTransform(model bmath.Matrix4) {
-	r.shader.Use()
-	
-	view := r.camera.GetViewMatrix()
-	projection := r.camera.GetProjectionMatrix()
-	
-	r.shader.SetMatrix4("model", &model[0])
-	r.shader.SetMatrix4("view", &view[0])
-	r.shader.SetMatrix4("projection", &projection[0])
-	
-	r.triangleMesh.Draw()
+	r.drawWithTransform("triangle", model)
 }
 
 func (r *Renderer) DrawPyramidWithTransform(model bmath.Matrix4) {
-	r.shader.Use()
-	
+	r.drawWithTransform("pyramid", model)
+}
+
+// drawWithTransform draws a built-in mesh through the backend so the
+// current view mode applies
+func (r *Renderer) drawWithTransform(meshType string, model bmath.Matrix4) {
+	r.backend.SetCamera(r.camera.GetViewMatrix(), r.camera.GetProjectionMatrix())
+	r.backend.DrawMesh(r.meshHandles[meshType], model)
+}
+
+// InFrustum reports whether a built-in mesh drawn with a model matrix, in
+// the layout the Draw*WithTransform methods take, is in the camera's view
+func (r *Renderer) InFrustum(meshType string, model bmath.Matrix4) bool {
+	handle, exists := r.meshHandles[meshType]
+	if !exists {
+		return true
+	}
+	// Matrices here are in the GPU's column-major layout, bounds and frustum
+	// planes in our row-major convention
 	view := r.camera.GetViewMatrix()
 	projection := r.camera.GetProjectionMatrix()
-	
-	r.shader.SetMatrix4("model", &model[0])
-	r.shader.SetMatrix4("view", &view[0])
-	r.shader.SetMatrix4("projection", &projection[0])
-	
-	r.pyramid.Draw()
+	frustum := bmath.NewFrustum(view.Multiply(projection).Transpose())
+	return frustum.IntersectsAABB(r.backend.MeshBounds(handle).Transform(model.Transpose()))
+}
+
+func (r *Renderer) SetViewMode(mode backend.ViewMode) {
+	r.viewMode = mode
+	r.backend.SetViewMode(mode)
+}
+
+func (r *Renderer) GetViewMode() backend.ViewMode {
+	return r.viewMode
 }
 
 func (r *Renderer) GetCamera() *camera.Camera3D {
@@ -271,6 +367,15 @@ func (r *Renderer) GetWindow() *window.Window {
 	return r.window
 }
 
+func (r *Renderer) GetBackend() backend.Backend {
+	return r.backend
+}
+
+func (r *Renderer) GetMeshHandle(meshType string) (backend.MeshHandle, bool) {
+	handle, exists := r.meshHandles[meshType]
+	return handle, exists
+}
+
 func (r *Renderer) DrawTriangleNoCamera() {
 	r.shader.Use()
 	
@@ -325,46 +430,70 @@ func (r *Renderer) DrawGrid(lines []bmath.Vector3, color [4]float32) {
 		vertices = append(vertices, line.X, line.Y, line.Z, color[0], color[1], color[2])
 	}
 	
-	// Create temporary mesh for grid lines
-	if r.gridMesh != nil {
-		r.gridMesh.Delete()
+	r.backend.SetCamera(r.camera.GetViewMatrix(), r.camera.GetProjectionMatrix())
+	r.backend.DrawLines(vertices, true)
+}
+
+func (r *Renderer) GetDebugDraw() *debugdraw.DebugDraw {
+	return r.debugDraw
+}
+
+// FlushDebugDraw draws the debug primitives accumulated this frame with the
+// renderer's camera; call it once per frame after the scene is drawn
+func (r *Renderer) FlushDebugDraw(deltaTime float32) {
+	defer r.BeginPass("debug draw")()
+	width, height := r.window.GetSize()
+	r.debugDraw.Flush(r.camera, width, height, deltaTime)
+}
+
+// GetProfiler returns the frame profiler, which EndFrame advances
+func (r *Renderer) GetProfiler() *profiler.Profiler {
+	return r.profiler
+}
+
+// BeginPass times a render pass on the CPU and the GPU and returns the
+// function ending it. Passes must not overlap.
+func (r *Renderer) BeginPass(name string) func() {
+	if !r.profiler.IsEnabled() {
+		return func() {}
+	}
+	endCPU := r.profiler.Begin(name)
+	r.backend.BeginTimer(name)
+	return func() {
+		r.backend.EndTimer()
+		endCPU()
 	}
-	r.gridMesh = opengl.NewMeshLines(vertices)
-	
-	r.lineShader.Use()
-	
-	model := bmath.NewMatrix4Identity()
-	view := r.camera.GetViewMatrix()
-	projection := r.camera.GetProjectionMatrix()
-	
-	r.lineShader.SetMatrix4("model", &model[0])
-	r.lineShader.SetMatrix4("view", &view[0])
-	r.lineShader.SetMatrix4("projection", &projection[0])
-	
-	r.gridMesh.DrawLines()
 }
 
 func (r *Renderer) EndFrame() {
+	r.backend.ResolveTimers(func(name string, elapsed time.Duration) {
+		r.profiler.Record(profiler.GPU, name, elapsed)
+	})
+	r.profiler.EndFrame()
+	r.backend.EndFrame()
 	r.window.SwapBuffers()
 	r.window.PollEvents()
 }
 
+// GetDebugLogger returns the GL debug logger, or nil when the renderer was
+// created without debug output
+func (r *Renderer) GetDebugLogger() *opengl.DebugLogger {
+	return r.debugLogger
+}
+
 func (r *Renderer) ShouldClose() bool {
 	return r.window.ShouldClose()
 }
 
 func (r *Renderer) Cleanup() {
-	r.triangle.Delete()
-	r.cube.Delete()
-	r.sphere.Delete()
-	r.cylinder.Delete()
-	r.plane.Delete()
-	r.triangleMesh.Delete()
-	r.pyramid.Delete()
-	r.shader.Delete()
-	r.lineShader.Delete()
-	if r.gridMesh != nil {
-		r.gridMesh.Delete()
+	r.backend.DeleteMesh(r.meshHandles["cone"])
+	r.backend.DeleteMesh(r.meshHandles["torus"])
+	r.skyboxes.Cleanup()
+	r.debugDraw.Cleanup()
+	for _, id := range r.resources {
+		r.backend.Resources().Release(id)
 	}
+	r.resources = nil
+	r.backend.Cleanup()
 	r.window.Destroy()
 }
\ No newline at end of file
@@ -0,0 +1,323 @@
+package debugdraw
+
+import (
+	"math"
+
+	"github.com/javanhut/BifrostEngine/m/v2/camera"
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/backend"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/sprite"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/text"
+)
+
+// LabelSize is the pixel size debug text labels are drawn at
+const LabelSize = 14
+
+// Options control how long a primitive lives and how it is drawn
+type Options struct {
+	// Duration in seconds the primitive stays visible; 0 draws it for one frame
+	Duration float32
+	// NoDepthTest draws the primitive on top of scene geometry
+	NoDepthTest bool
+}
+
+type line struct {
+	from, to  bmath.Vector3
+	color     [3]float32
+	remaining float32
+	depthTest bool
+}
+
+type label struct {
+	position  bmath.Vector3
+	text      string
+	color     [3]float32
+	remaining float32
+}
+
+// ScreenLabel is a world-space text label projected to window coordinates
+// (origin bottom-left, matching the editor GUI)
+type ScreenLabel struct {
+	X, Y  float32
+	Text  string
+	Color [3]float32
+}
+
+// DebugDraw accumulates immediate-mode debug primitives during a frame and
+// submits them with one line draw per depth mode when flushed. Text labels
+// are projected to the window and drawn on top with the built-in mono font.
+type DebugDraw struct {
+	backend  backend.Backend
+	lines    []line
+	labels   []label
+	depth    []float32
+	overlay  []float32
+	screen   []ScreenLabel
+	segments int
+	batch    *sprite.SpriteBatch
+	drawer   *text.Drawer
+}
+
+// New creates a debug drawer that renders through a backend
+func New(b backend.Backend) *DebugDraw {
+	return &DebugDraw{
+		backend:  b,
+		segments: 24,
+	}
+}
+
+func options(opts []Options) Options {
+	if len(opts) > 0 {
+		return opts[0]
+	}
+	return Options{}
+}
+
+// SetCircleSegments sets how many segments circles and spheres use
+func (d *DebugDraw) SetCircleSegments(segments int) {
+	if segments >= 3 {
+		d.segments = segments
+	}
+}
+
+// Line draws a line segment
+func (d *DebugDraw) Line(from, to bmath.Vector3, color [3]float32, opts ...Options) {
+	o := options(opts)
+	d.lines = append(d.lines, line{
+		from:      from,
+		to:        to,
+		color:     color,
+		remaining: o.Duration,
+		depthTest: !o.NoDepthTest,
+	})
+}
+
+// Arrow draws a line with an arrow head at to
+func (d *DebugDraw) Arrow(from, to bmath.Vector3, color [3]float32, opts ...Options) {
+	d.Line(from, to, color, opts...)
+
+	direction := to.Sub(from)
+	length := direction.Length()
+	if length == 0 {
+		return
+	}
+	direction = direction.Div(length)
+
+	headLength := length * 0.2
+	side := perpendicular(direction).Mul(headLength * 0.5)
+	up := direction.Cross(side)
+	base := to.Sub(direction.Mul(headLength))
+
+	d.Line(to, base.Add(side), color, opts...)
+	d.Line(to, base.Sub(side), color, opts...)
+	d.Line(to, base.Add(up), color, opts...)
+	d.Line(to, base.Sub(up), color, opts...)
+}
+
+// WireBox draws the edges of an axis-aligned box
+func (d *DebugDraw) WireBox(box bmath.AABB, color [3]float32, opts ...Options) {
+	lo, hi := box.Min, box.Max
+	d.box([8]bmath.Vector3{
+		{X: lo.X, Y: lo.Y, Z: lo.Z},
+		{X: hi.X, Y: lo.Y, Z: lo.Z},
+		{X: hi.X, Y: hi.Y, Z: lo.Z},
+		{X: lo.X, Y: hi.Y, Z: lo.Z},
+		{X: lo.X, Y: lo.Y, Z: hi.Z},
+		{X: hi.X, Y: lo.Y, Z: hi.Z},
+		{X: hi.X, Y: hi.Y, Z: hi.Z},
+		{X: lo.X, Y: hi.Y, Z: hi.Z},
+	}, color, opts...)
+}
+
+// WireSphere draws three orthogonal circles
+func (d *DebugDraw) WireSphere(center bmath.Vector3, radius float32, color [3]float32, opts ...Options) {
+	d.Circle(center, bmath.Vector3Right, radius, color, opts...)
+	d.Circle(center, bmath.Vector3Up, radius, color, opts...)
+	d.Circle(center, bmath.Vector3Back, radius, color, opts...)
+}
+
+// Circle draws a circle in the plane with the given normal
+func (d *DebugDraw) Circle(center, normal bmath.Vector3, radius float32, color [3]float32, opts ...Options) {
+	normal = normal.Normalize()
+	u := perpendicular(normal)
+	v := normal.Cross(u)
+
+	previous := center.Add(u.Mul(radius))
+	for i := 1; i <= d.segments; i++ {
+		angle := float64(i) * 2 * math.Pi / float64(d.segments)
+		offset := u.Mul(float32(math.Cos(angle)) * radius).Add(v.Mul(float32(math.Sin(angle)) * radius))
+		current := center.Add(offset)
+		d.Line(previous, current, color, opts...)
+		previous = current
+	}
+}
+
+// Frustum draws the edges of a view frustum
+func (d *DebugDraw) Frustum(frustum bmath.Frustum, color [3]float32, opts ...Options) {
+	d.box(frustum.Corners(), color, opts...)
+}
+
+// Axes draws the X, Y and Z axes of a transform in red, green and blue.
+// The matrix uses the row-major convention of scene transforms.
+func (d *DebugDraw) Axes(transform bmath.Matrix4, size float32, opts ...Options) {
+	origin := transform.MultiplyVector3(bmath.Vector3Zero, 1)
+	d.Arrow(origin, transform.MultiplyVector3(bmath.Vector3Right.Mul(size), 1), [3]float32{1, 0, 0}, opts...)
+	d.Arrow(origin, transform.MultiplyVector3(bmath.Vector3Up.Mul(size), 1), [3]float32{0, 1, 0}, opts...)
+	d.Arrow(origin, transform.MultiplyVector3(bmath.Vector3Back.Mul(size), 1), [3]float32{0, 0, 1}, opts...)
+}
+
+// Text places a text label at a world position
+func (d *DebugDraw) Text(position bmath.Vector3, text string, color [3]float32, opts ...Options) {
+	d.labels = append(d.labels, label{
+		position:  position,
+		text:      text,
+		color:     color,
+		remaining: options(opts).Duration,
+	})
+}
+
+// box draws the twelve edges between two quads of corners
+func (d *DebugDraw) box(corners [8]bmath.Vector3, color [3]float32, opts ...Options) {
+	for i := 0; i < 4; i++ {
+		next := (i + 1) % 4
+		d.Line(corners[i], corners[next], color, opts...)
+		d.Line(corners[i+4], corners[next+4], color, opts...)
+		d.Line(corners[i], corners[i+4], color, opts...)
+	}
+}
+
+// Flush draws all accumulated primitives with the given camera, projects
+// and draws the text labels for a viewport of width x height pixels, and
+// then ages timed primitives by deltaTime, dropping expired and
+// single-frame ones.
+func (d *DebugDraw) Flush(cam camera.Camera, width, height int, deltaTime float32) {
+	view := cam.GetViewMatrix()
+	projection := cam.GetProjectionMatrix()
+
+	d.depth = d.depth[:0]
+	d.overlay = d.overlay[:0]
+	for _, l := range d.lines {
+		if l.depthTest {
+			d.depth = appendLine(d.depth, l)
+		} else {
+			d.overlay = appendLine(d.overlay, l)
+		}
+	}
+
+	if len(d.depth) > 0 || len(d.overlay) > 0 {
+		d.backend.SetCamera(view, projection)
+	}
+	if len(d.depth) > 0 {
+		d.backend.DrawLines(d.depth, true)
+	}
+	if len(d.overlay) > 0 {
+		d.backend.DrawLines(d.overlay, false)
+	}
+
+	d.projectLabels(view, projection, width, height)
+	d.drawLabels(width, height)
+	d.expire(deltaTime)
+}
+
+func appendLine(vertices []float32, l line) []float32 {
+	return append(vertices,
+		l.from.X, l.from.Y, l.from.Z, l.color[0], l.color[1], l.color[2],
+		l.to.X, l.to.Y, l.to.Z, l.color[0], l.color[1], l.color[2],
+	)
+}
+
+// projectLabels maps labels through the camera matrices, which are in the
+// GPU's column-major layout, and drops those behind the camera
+func (d *DebugDraw) projectLabels(view, projection bmath.Matrix4, width, height int) {
+	clip := view.Multiply(projection).Transpose()
+
+	d.screen = d.screen[:0]
+	for _, l := range d.labels {
+		p := l.position
+		w := clip[12]*p.X + clip[13]*p.Y + clip[14]*p.Z + clip[15]
+		if w <= 0 {
+			continue
+		}
+		ndc := clip.MultiplyVector3(p, 1).Div(w)
+		d.screen = append(d.screen, ScreenLabel{
+			X:     (ndc.X + 1) * 0.5 * float32(width),
+			Y:     (ndc.Y + 1) * 0.5 * float32(height),
+			Text:  l.text,
+			Color: l.color,
+		})
+	}
+}
+
+// drawLabels draws the projected labels centered above their points, in
+// a pixel-space projection with the origin at the bottom-left
+func (d *DebugDraw) drawLabels(width, height int) {
+	if len(d.screen) == 0 || width <= 0 || height <= 0 {
+		return
+	}
+	if d.drawer == nil {
+		face, err := text.NewFace(text.MonoFont(), text.DefaultOptions(LabelSize))
+		if err != nil {
+			return
+		}
+		d.drawer = text.NewDrawer(d.backend, face)
+		d.batch = sprite.NewSpriteBatch(d.backend)
+	}
+
+	projection := bmath.NewOrthographic(0, float32(width), 0, float32(height), -1, 1)
+	d.batch.Begin(bmath.NewMatrix4Identity(), projection.Transpose())
+	options := text.DefaultDrawOptions()
+	options.Origin = bmath.NewVector2(0.5, 0)
+	for _, l := range d.screen {
+		options.Position = bmath.NewVector2(l.X, l.Y)
+		options.Color = [4]float32{l.Color[0], l.Color[1], l.Color[2], 1}
+		d.drawer.Draw(d.batch, l.Text, options)
+	}
+	d.batch.End()
+}
+
+func (d *DebugDraw) expire(deltaTime float32) {
+	lines := d.lines[:0]
+	for _, l := range d.lines {
+		l.remaining -= deltaTime
+		if l.remaining > 0 {
+			lines = append(lines, l)
+		}
+	}
+	d.lines = lines
+
+	labels := d.labels[:0]
+	for _, l := range d.labels {
+		l.remaining -= deltaTime
+		if l.remaining > 0 {
+			labels = append(labels, l)
+		}
+	}
+	d.labels = labels
+}
+
+// Labels returns the text labels projected by the last Flush
+func (d *DebugDraw) Labels() []ScreenLabel {
+	return d.screen
+}
+
+// Cleanup deletes the label font textures
+func (d *DebugDraw) Cleanup() {
+	if d.drawer != nil {
+		d.drawer.Cleanup()
+	}
+}
+
+// Clear drops all primitives, including timed ones
+func (d *DebugDraw) Clear() {
+	d.lines = d.lines[:0]
+	d.labels = d.labels[:0]
+}
+
+// perpendicular returns a unit vector perpendicular to v
+func perpendicular(v bmath.Vector3) bmath.Vector3 {
+	axis := bmath.Vector3Up
+	if bmath.Abs(v.Dot(axis)) > 0.9 {
+		axis = bmath.Vector3Right
+	}
+	return v.Cross(axis).Normalize()
+}
@@ -0,0 +1,129 @@
+package debugdraw
+
+import (
+	"math"
+	"testing"
+
+	"github.com/javanhut/BifrostEngine/m/v2/camera"
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/software"
+)
+
+const viewport = 64
+
+// newCamera looks down -Z at the origin from 5 units away
+func newCamera() *camera.Camera3D {
+	return camera.NewCamera3D(bmath.NewVector3(0, 0, 5), bmath.NewVector3(0, 0, 0), math.Pi/2, 1, 0.1, 100)
+}
+
+// project maps a point to window coordinates the way the vertex shader
+// does, reading both camera matrices column by column
+func project(cam camera.Camera, p bmath.Vector3) (float32, float32) {
+	apply := func(m bmath.Matrix4, v [4]float32) [4]float32 {
+		var out [4]float32
+		for row := range out {
+			for col := range v {
+				out[row] += m[col*4+row] * v[col]
+			}
+		}
+		return out
+	}
+	clip := apply(cam.GetProjectionMatrix(), apply(cam.GetViewMatrix(), [4]float32{p.X, p.Y, p.Z, 1}))
+	return (clip[0]/clip[3] + 1) * 0.5 * viewport, (clip[1]/clip[3] + 1) * 0.5 * viewport
+}
+
+func TestLabelProjection(t *testing.T) {
+	tests := []struct {
+		name     string
+		position bmath.Vector3
+		visible  bool
+	}{
+		{"look-at target", bmath.NewVector3(0, 0, 0), true},
+		{"right", bmath.NewVector3(0.5, 0, 0), true},
+		{"up", bmath.NewVector3(0, 0.5, 0), true},
+		{"lower left far away", bmath.NewVector3(-2, -2, -20), true},
+		{"behind the camera", bmath.NewVector3(0, 0, 10), false},
+		{"at the eye", bmath.NewVector3(0, 0, 5), false},
+	}
+
+	// The look-at target lands in the middle of the window
+	if x, y := project(newCamera(), bmath.NewVector3(0, 0, 0)); x != viewport/2 || y != viewport/2 {
+		t.Fatalf("target projects to (%v, %v)", x, y)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(software.NewRasterizer(viewport, viewport))
+			d.Text(tt.position, "label", [3]float32{1, 0, 0})
+			d.Flush(newCamera(), viewport, viewport, 0)
+
+			labels := d.Labels()
+			if !tt.visible {
+				if len(labels) != 0 {
+					t.Fatalf("labels = %+v, want none", labels)
+				}
+				return
+			}
+			if len(labels) != 1 {
+				t.Fatalf("got %d labels, want 1", len(labels))
+			}
+			l := labels[0]
+			wantX, wantY := project(newCamera(), tt.position)
+			if math.Abs(float64(l.X-wantX)) > 1e-3 || math.Abs(float64(l.Y-wantY)) > 1e-3 {
+				t.Errorf("label at (%v, %v), want (%v, %v)", l.X, l.Y, wantX, wantY)
+			}
+			if l.Text != "label" || l.Color != [3]float32{1, 0, 0} {
+				t.Errorf("label = %+v", l)
+			}
+		})
+	}
+}
+
+func TestLabelsDrawn(t *testing.T) {
+	r := software.NewRasterizer(viewport, viewport)
+	r.SetClearColor(0, 0, 0, 1)
+	r.BeginFrame()
+	d := New(r)
+	d.Text(bmath.NewVector3(0, 0, 0), "W", [3]float32{1, 1, 1})
+	d.Flush(newCamera(), viewport, viewport, 0)
+
+	// The label is centered above its point, so it lights pixels in the
+	// upper half of the window around the middle column and none below
+	img := r.Image()
+	above, below := 0, 0
+	for y := 0; y < viewport; y++ {
+		for x := 0; x < viewport; x++ {
+			if img.RGBAAt(x, y).R == 0 {
+				continue
+			}
+			// Image rows run top-down
+			if y < viewport/2 {
+				above++
+				if x < viewport/2-LabelSize || x > viewport/2+LabelSize {
+					t.Errorf("pixel (%d, %d) lit away from the label", x, y)
+				}
+			} else {
+				below++
+			}
+		}
+	}
+	if above == 0 {
+		t.Error("label not drawn")
+	}
+	if below != 0 {
+		t.Errorf("%d pixels lit below the label's point", below)
+	}
+}
+
+func TestLabelExpiry(t *testing.T) {
+	d := New(software.NewRasterizer(viewport, viewport))
+	d.Text(bmath.NewVector3(0, 0, 0), "frame", [3]float32{1, 1, 1})
+	d.Text(bmath.NewVector3(0, 0, 0), "timed", [3]float32{1, 1, 1}, Options{Duration: 1})
+
+	for frame, want := range []int{2, 1, 0} {
+		d.Flush(newCamera(), viewport, viewport, 0.6)
+		if got := len(d.Labels()); got != want {
+			t.Errorf("frame %d: %d labels, want %d", frame, got, want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package environment
+
+import (
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/backend"
+)
+
+type cacheEntry struct {
+	texture backend.TextureHandle
+	err     error
+}
+
+// Cache loads each environment map once and keeps it on the backend.
+// Failed loads are remembered too, so a bad path is reported only once.
+type Cache struct {
+	backend backend.Backend
+	entries map[string]cacheEntry
+}
+
+func NewCache(b backend.Backend) *Cache {
+	return &Cache{
+		backend: b,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// Get returns the cubemap for a path accepted by Load, loading it on first use
+func (c *Cache) Get(path string) (backend.TextureHandle, error) {
+	if entry, exists := c.entries[path]; exists {
+		return entry.texture, entry.err
+	}
+
+	entry := cacheEntry{}
+	faces, err := Load(path, 0)
+	if err != nil {
+		entry.err = err
+	} else {
+		entry.texture = c.backend.CreateCubemap(faces)
+	}
+	c.entries[path] = entry
+	return entry.texture, entry.err
+}
+
+// Cleanup deletes all loaded cubemaps from the backend
+func (c *Cache) Cleanup() {
+	for _, entry := range c.entries {
+		if entry.texture != backend.InvalidTexture {
+			c.backend.DeleteTexture(entry.texture)
+		}
+	}
+	c.entries = make(map[string]cacheEntry)
+}
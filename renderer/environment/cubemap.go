@@ -0,0 +1,104 @@
+package environment
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/backend"
+)
+
+// Cubemap face indices, in the order of backend.CubemapFaces
+const (
+	FacePositiveX = iota
+	FaceNegativeX
+	FacePositiveY
+	FaceNegativeY
+	FacePositiveZ
+	FaceNegativeZ
+)
+
+// FaceDirection returns the unnormalized direction through texture
+// coordinates (s, t) of a cube face, following the OpenGL cubemap layout
+// where t grows downwards through the face image
+func FaceDirection(face int, s, t float32) bmath.Vector3 {
+	sc := 2*s - 1
+	tc := 2*t - 1
+	switch face {
+	case FacePositiveX:
+		return bmath.NewVector3(1, -tc, -sc)
+	case FaceNegativeX:
+		return bmath.NewVector3(-1, -tc, sc)
+	case FacePositiveY:
+		return bmath.NewVector3(sc, 1, tc)
+	case FaceNegativeY:
+		return bmath.NewVector3(sc, -1, -tc)
+	case FacePositiveZ:
+		return bmath.NewVector3(sc, -tc, 1)
+	default:
+		return bmath.NewVector3(-sc, -tc, -1)
+	}
+}
+
+// FaceCoords returns the cube face a direction points at and the texture
+// coordinates within it; it is the inverse of FaceDirection
+func FaceCoords(direction bmath.Vector3) (face int, s, t float32) {
+	x, y, z := direction.X, direction.Y, direction.Z
+	ax, ay, az := bmath.Abs(x), bmath.Abs(y), bmath.Abs(z)
+
+	var sc, tc, major float32
+	switch {
+	case ax >= ay && ax >= az:
+		major = ax
+		if x > 0 {
+			face, sc, tc = FacePositiveX, -z, -y
+		} else {
+			face, sc, tc = FaceNegativeX, z, -y
+		}
+	case ay >= az:
+		major = ay
+		if y > 0 {
+			face, sc, tc = FacePositiveY, x, z
+		} else {
+			face, sc, tc = FaceNegativeY, x, -z
+		}
+	default:
+		major = az
+		if z > 0 {
+			face, sc, tc = FacePositiveZ, x, -y
+		} else {
+			face, sc, tc = FaceNegativeZ, -x, -y
+		}
+	}
+
+	if major == 0 {
+		return FacePositiveZ, 0.5, 0.5
+	}
+	return face, (sc/major + 1) / 2, (tc/major + 1) / 2
+}
+
+// Sample returns the texel of a cubemap a direction points at
+func Sample(faces backend.CubemapFaces, direction bmath.Vector3) color.RGBA {
+	face, s, t := FaceCoords(direction)
+	img := faces[face]
+	if img == nil {
+		return color.RGBA{}
+	}
+
+	bounds := img.Bounds()
+	x := bounds.Min.X + min(int(s*float32(bounds.Dx())), bounds.Dx()-1)
+	y := bounds.Min.Y + min(int(t*float32(bounds.Dy())), bounds.Dy()-1)
+	return img.RGBAAt(x, y)
+}
+
+// toRGBA converts any image to a tightly packed RGBA image
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
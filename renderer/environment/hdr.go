@@ -0,0 +1,206 @@
+package environment
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+// HDRImage is a linear floating-point RGB image
+type HDRImage struct {
+	Width  int
+	Height int
+	// Pix holds RGB triples row by row from the top
+	Pix []float32
+}
+
+func NewHDRImage(width, height int) *HDRImage {
+	return &HDRImage{
+		Width:  width,
+		Height: height,
+		Pix:    make([]float32, width*height*3),
+	}
+}
+
+func (h *HDRImage) At(x, y int) [3]float32 {
+	i := (y*h.Width + x) * 3
+	return [3]float32{h.Pix[i], h.Pix[i+1], h.Pix[i+2]}
+}
+
+func (h *HDRImage) Set(x, y int, rgb [3]float32) {
+	i := (y*h.Width + x) * 3
+	h.Pix[i], h.Pix[i+1], h.Pix[i+2] = rgb[0], rgb[1], rgb[2]
+}
+
+// Bilinear samples the image at normalized coordinates, wrapping
+// horizontally and clamping vertically as suits equirectangular maps
+func (h *HDRImage) Bilinear(u, v float32) [3]float32 {
+	fx := u*float32(h.Width) - 0.5
+	fy := v*float32(h.Height) - 0.5
+	x0 := int(math.Floor(float64(fx)))
+	y0 := int(math.Floor(float64(fy)))
+	tx := fx - float32(x0)
+	ty := fy - float32(y0)
+
+	wrap := func(x int) int {
+		x %= h.Width
+		if x < 0 {
+			x += h.Width
+		}
+		return x
+	}
+	clamp := func(y int) int {
+		return max(0, min(y, h.Height-1))
+	}
+
+	a := h.At(wrap(x0), clamp(y0))
+	b := h.At(wrap(x0+1), clamp(y0))
+	c := h.At(wrap(x0), clamp(y0+1))
+	d := h.At(wrap(x0+1), clamp(y0+1))
+
+	var rgb [3]float32
+	for i := range rgb {
+		top := a[i] + (b[i]-a[i])*tx
+		bottom := c[i] + (d[i]-c[i])*tx
+		rgb[i] = top + (bottom-top)*ty
+	}
+	return rgb
+}
+
+// LoadHDR reads a Radiance RGBE (.hdr) file
+func LoadHDR(path string) (*HDRImage, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, err := DecodeHDR(bufio.NewReader(file))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return img, nil
+}
+
+// DecodeHDR reads a Radiance RGBE image with the standard -Y +X
+// orientation, either flat or with per-channel run-length encoding
+func DecodeHDR(r *bufio.Reader) (*HDRImage, error) {
+	magic, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(magic, "#?") {
+		return nil, errors.New("not a Radiance HDR file")
+	}
+
+	// Header lines run until a blank line
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if format, ok := strings.CutPrefix(line, "FORMAT="); ok && format != "32-bit_rle_rgbe" {
+			return nil, fmt.Errorf("unsupported format %q", format)
+		}
+	}
+
+	resolution, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	var width, height int
+	if _, err := fmt.Sscanf(resolution, "-Y %d +X %d", &height, &width); err != nil {
+		return nil, fmt.Errorf("unsupported resolution line %q", strings.TrimSpace(resolution))
+	}
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid size %dx%d", width, height)
+	}
+
+	img := NewHDRImage(width, height)
+	scanline := make([]byte, width*4)
+	for y := 0; y < height; y++ {
+		if err := readScanline(r, scanline, width); err != nil {
+			return nil, fmt.Errorf("scanline %d: %w", y, err)
+		}
+		for x := 0; x < width; x++ {
+			img.Set(x, y, rgbe(scanline[x*4:x*4+4]))
+		}
+	}
+	return img, nil
+}
+
+func readScanline(r *bufio.Reader, scanline []byte, width int) error {
+	header, err := r.Peek(4)
+	if err != nil {
+		return err
+	}
+
+	// Run-length encoded scanlines start with 2, 2 and the width
+	if width < 8 || width > 0x7fff || header[0] != 2 || header[1] != 2 || header[2]&0x80 != 0 {
+		_, err := io.ReadFull(r, scanline)
+		return err
+	}
+	if int(header[2])<<8|int(header[3]) != width {
+		return errors.New("scanline width mismatch")
+	}
+	r.Discard(4)
+
+	// Each channel is stored separately as runs and literal spans
+	for channel := 0; channel < 4; channel++ {
+		for x := 0; x < width; {
+			count, err := r.ReadByte()
+			if err != nil {
+				return err
+			}
+			if count > 128 {
+				n := int(count - 128)
+				value, err := r.ReadByte()
+				if err != nil {
+					return err
+				}
+				if x+n > width {
+					return errors.New("run overflows scanline")
+				}
+				for ; n > 0; n-- {
+					scanline[x*4+channel] = value
+					x++
+				}
+			} else {
+				n := int(count)
+				if n == 0 || x+n > width {
+					return errors.New("invalid literal span")
+				}
+				for ; n > 0; n-- {
+					value, err := r.ReadByte()
+					if err != nil {
+						return err
+					}
+					scanline[x*4+channel] = value
+					x++
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// rgbe decodes a shared-exponent pixel to linear RGB
+func rgbe(p []byte) [3]float32 {
+	if p[3] == 0 {
+		return [3]float32{}
+	}
+	f := float32(math.Ldexp(1, int(p[3])-(128+8)))
+	return [3]float32{
+		(float32(p[0]) + 0.5) * f,
+		(float32(p[1]) + 0.5) * f,
+		(float32(p[2]) + 0.5) * f,
+	}
+}
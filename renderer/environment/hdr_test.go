@@ -0,0 +1,181 @@
+package environment
+
+import (
+	"bufio"
+	"bytes"
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+)
+
+const hdrHeader = "#?RADIANCE\nFORMAT=32-bit_rle_rgbe\nEXPOSURE=1.0\n\n"
+
+func decode(data []byte) (*HDRImage, error) {
+	return DecodeHDR(bufio.NewReader(bytes.NewReader(data)))
+}
+
+func TestDecodeHDR(t *testing.T) {
+	// Pixels of a 2x2 flat image, top row first, sharing exponents so the
+	// values are exact
+	flat := append([]byte(hdrHeader+"-Y 2 +X 2\n"),
+		127, 63, 31, 129, // (127.5, 63.5, 31.5) / 128
+		0, 0, 0, 0, // zero exponent is black
+		255, 0, 0, 128, // (255.5, 0.5, 0.5) / 256
+		1, 2, 3, 136, // (1.5, 2.5, 3.5)
+	)
+
+	img, err := decode(flat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Width != 2 || img.Height != 2 {
+		t.Fatalf("size %dx%d, want 2x2", img.Width, img.Height)
+	}
+	want := map[[2]int][3]float32{
+		{0, 0}: {127.5 / 128, 63.5 / 128, 31.5 / 128},
+		{1, 0}: {0, 0, 0},
+		{0, 1}: {255.5 / 256, 0.5 / 256, 0.5 / 256},
+		{1, 1}: {1.5, 2.5, 3.5},
+	}
+	for p, rgb := range want {
+		if got := img.At(p[0], p[1]); got != rgb {
+			t.Errorf("pixel %v = %v, want %v", p, got, rgb)
+		}
+	}
+}
+
+func TestDecodeHDRRunLength(t *testing.T) {
+	// One 8 pixel scanline: red a single run, green literal values, blue
+	// a run of zeros and a shared exponent
+	data := append([]byte(hdrHeader+"-Y 1 +X 8\n"),
+		2, 2, 0, 8,
+		128+8, 63,
+		8, 0, 1, 2, 3, 4, 5, 6, 7,
+		128+8, 0,
+		128+8, 136,
+	)
+
+	img, err := decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for x := 0; x < 8; x++ {
+		want := [3]float32{63.5, float32(x) + 0.5, 0.5}
+		if got := img.At(x, 0); got != want {
+			t.Errorf("pixel %d = %v, want %v", x, got, want)
+		}
+	}
+}
+
+func TestDecodeHDRErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"not radiance", "P6\n2 2\n", "not a Radiance HDR file"},
+		{"xyze format", "#?RADIANCE\nFORMAT=32-bit_rle_xyze\n\n-Y 1 +X 1\n", "unsupported format"},
+		{"flipped rows", hdrHeader + "+Y 1 +X 1\n\x00\x00\x00\x00", "unsupported resolution line"},
+		{"empty", hdrHeader + "-Y 0 +X 1\n", "invalid size"},
+		{"truncated", hdrHeader + "-Y 2 +X 1\n\x01\x01\x01\x80", "scanline 1"},
+		{"width mismatch", hdrHeader + "-Y 1 +X 8\n\x02\x02\x00\x09", "scanline width mismatch"},
+		{"run overflow", hdrHeader + "-Y 1 +X 8\n\x02\x02\x00\x08\x89\x00", "run overflows scanline"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decode([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// directionPanorama encodes the direction each texel looks along as its
+// color, mapping -1..1 to 0..1, using the layout EquirectangularToCubemap
+// expects: the center column looks down -Z and the top row up +Y
+func directionPanorama(width, height int) *HDRImage {
+	panorama := NewHDRImage(width, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			phi := ((float64(x)+0.5)/float64(width) - 0.5) * 2 * math.Pi
+			theta := (float64(y) + 0.5) / float64(height) * math.Pi
+			d := [3]float64{math.Sin(theta) * math.Sin(phi), math.Cos(theta), -math.Sin(theta) * math.Cos(phi)}
+			panorama.Set(x, y, [3]float32{float32(d[0]+1) / 2, float32(d[1]+1) / 2, float32(d[2]+1) / 2})
+		}
+	}
+	return panorama
+}
+
+// direction decodes a texel of a direction panorama
+func direction(c color.RGBA) bmath.Vector3 {
+	return bmath.NewVector3(float32(c.R)/255*2-1, float32(c.G)/255*2-1, float32(c.B)/255*2-1)
+}
+
+func near(a, b bmath.Vector3, epsilon float32) bool {
+	return a.Sub(b).Length() < epsilon
+}
+
+func TestEquirectangularFaceOrientation(t *testing.T) {
+	const size = 16
+	faces := EquirectangularToCubemap(directionPanorama(256, 128), size, false)
+
+	// What each face looks at, and what its top and right edges look at
+	tests := []struct {
+		name               string
+		face               int
+		center, top, right bmath.Vector3
+	}{
+		{"+X", FacePositiveX, bmath.NewVector3(1, 0, 0), bmath.NewVector3(0, 1, 0), bmath.NewVector3(0, 0, -1)},
+		{"-X", FaceNegativeX, bmath.NewVector3(-1, 0, 0), bmath.NewVector3(0, 1, 0), bmath.NewVector3(0, 0, 1)},
+		{"+Y", FacePositiveY, bmath.NewVector3(0, 1, 0), bmath.NewVector3(0, 0, -1), bmath.NewVector3(1, 0, 0)},
+		{"-Y", FaceNegativeY, bmath.NewVector3(0, -1, 0), bmath.NewVector3(0, 0, 1), bmath.NewVector3(1, 0, 0)},
+		{"+Z", FacePositiveZ, bmath.NewVector3(0, 0, 1), bmath.NewVector3(0, 1, 0), bmath.NewVector3(1, 0, 0)},
+		{"-Z", FaceNegativeZ, bmath.NewVector3(0, 0, -1), bmath.NewVector3(0, 1, 0), bmath.NewVector3(-1, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := faces[tt.face]
+			if got := direction(img.RGBAAt(size/2, size/2)); !near(got, tt.center, 0.15) {
+				t.Errorf("center looks along %v, want %v", got, tt.center)
+			}
+			// Edge texels lie halfway between the center and the edge's axis
+			top := tt.center.Add(tt.top).Normalize()
+			if got := direction(img.RGBAAt(size/2, 0)); !near(got, top, 0.15) {
+				t.Errorf("top edge looks along %v, want %v", got, top)
+			}
+			right := tt.center.Add(tt.right).Normalize()
+			if got := direction(img.RGBAAt(size-1, size/2)); !near(got, right, 0.15) {
+				t.Errorf("right edge looks along %v, want %v", got, right)
+			}
+
+			// Every texel shows the panorama along its own direction
+			for y := 0; y < size; y++ {
+				for x := 0; x < size; x++ {
+					s := (float32(x) + 0.5) / size
+					v := (float32(y) + 0.5) / size
+					want := FaceDirection(tt.face, s, v).Normalize()
+					if got := direction(img.RGBAAt(x, y)); !near(got, want, 0.05) {
+						t.Fatalf("texel (%d, %d) looks along %v, want %v", x, y, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFaceCoordsInvertsFaceDirection(t *testing.T) {
+	for face := FacePositiveX; face <= FaceNegativeZ; face++ {
+		for _, st := range [][2]float32{{0.5, 0.5}, {0.1, 0.2}, {0.9, 0.3}, {0.25, 0.95}} {
+			gotFace, s, u := FaceCoords(FaceDirection(face, st[0], st[1]).Mul(3))
+			if gotFace != face || bmath.Abs(s-st[0]) > 1e-5 || bmath.Abs(u-st[1]) > 1e-5 {
+				t.Errorf("face %d at %v maps back to face %d at (%v, %v)", face, st, gotFace, s, u)
+			}
+		}
+	}
+}
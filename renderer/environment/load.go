@@ -0,0 +1,167 @@
+package environment
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/backend"
+)
+
+// Exposure scales HDR radiance before tone mapping
+var Exposure float32 = 1.0
+
+// faceNames lists the file names accepted for each face, in face order
+var faceNames = [6][]string{
+	{"px", "posx", "right"},
+	{"nx", "negx", "left"},
+	{"py", "posy", "top"},
+	{"ny", "negy", "bottom"},
+	{"pz", "posz", "front"},
+	{"nz", "negz", "back"},
+}
+
+var faceExtensions = []string{".png", ".jpg", ".jpeg"}
+
+// Load reads a cubemap from a directory of six face images or converts it
+// from an equirectangular panorama. Radiance .hdr panoramas are tone
+// mapped; other image formats are used as-is. faceSize may be 0 to use a
+// quarter of the panorama's width.
+func Load(path string, faceSize int) (backend.CubemapFaces, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return backend.CubemapFaces{}, err
+	}
+	if info.IsDir() {
+		return LoadFaces(path)
+	}
+
+	var panorama *HDRImage
+	toneMap := strings.EqualFold(filepath.Ext(path), ".hdr")
+	if toneMap {
+		panorama, err = LoadHDR(path)
+	} else {
+		panorama, err = loadLDR(path)
+	}
+	if err != nil {
+		return backend.CubemapFaces{}, err
+	}
+
+	if faceSize <= 0 {
+		faceSize = max(panorama.Width/4, 1)
+	}
+	return EquirectangularToCubemap(panorama, faceSize, toneMap), nil
+}
+
+// LoadFaces reads six square face images from a directory, named px, nx,
+// py, ny, pz, nz (or right, left, top, bottom, front, back) with a .png or
+// .jpg extension
+func LoadFaces(dir string) (backend.CubemapFaces, error) {
+	var faces backend.CubemapFaces
+	size := 0
+	for face, names := range faceNames {
+		path := findFace(dir, names)
+		if path == "" {
+			return faces, fmt.Errorf("no %s face in %s", names[0], dir)
+		}
+
+		img, err := decodeFile(path)
+		if err != nil {
+			return faces, err
+		}
+		bounds := img.Bounds()
+		if bounds.Dx() != bounds.Dy() {
+			return faces, fmt.Errorf("cubemap face %s is not square", path)
+		}
+		if size == 0 {
+			size = bounds.Dx()
+		} else if bounds.Dx() != size {
+			return faces, fmt.Errorf("cubemap face %s is %dpx, expected %dpx", path, bounds.Dx(), size)
+		}
+		faces[face] = toRGBA(img)
+	}
+	return faces, nil
+}
+
+func findFace(dir string, names []string) string {
+	for _, name := range names {
+		for _, ext := range faceExtensions {
+			path := filepath.Join(dir, name+ext)
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+func decodeFile(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return img, nil
+}
+
+// loadLDR reads an 8-bit panorama into an HDRImage with values in [0, 1]
+func loadLDR(path string) (*HDRImage, error) {
+	img, err := decodeFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rgba := toRGBA(img)
+	bounds := rgba.Bounds()
+	panorama := NewHDRImage(bounds.Dx(), bounds.Dy())
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			c := rgba.RGBAAt(x, y)
+			panorama.Set(x, y, [3]float32{float32(c.R) / 255, float32(c.G) / 255, float32(c.B) / 255})
+		}
+	}
+	return panorama, nil
+}
+
+// EquirectangularToCubemap resamples a latitude-longitude panorama, whose
+// center column looks down -Z, into six cube faces. With toneMap set the
+// linear radiance is mapped to display values with Exposure, the Reinhard
+// operator and gamma 2.2.
+func EquirectangularToCubemap(panorama *HDRImage, faceSize int, toneMap bool) backend.CubemapFaces {
+	var faces backend.CubemapFaces
+	for face := range faces {
+		img := image.NewRGBA(image.Rect(0, 0, faceSize, faceSize))
+		for y := 0; y < faceSize; y++ {
+			for x := 0; x < faceSize; x++ {
+				s := (float32(x) + 0.5) / float32(faceSize)
+				t := (float32(y) + 0.5) / float32(faceSize)
+				d := FaceDirection(face, s, t).Normalize()
+
+				u := 0.5 + float32(math.Atan2(float64(d.X), float64(-d.Z))/(2*math.Pi))
+				v := float32(math.Acos(float64(max(-1, min(d.Y, 1)))) / math.Pi)
+				rgb := panorama.Bilinear(u, v)
+
+				offset := img.PixOffset(x, y)
+				for i, c := range rgb {
+					if toneMap {
+						c *= Exposure
+						c = float32(math.Pow(float64(c/(1+c)), 1/2.2))
+					}
+					img.Pix[offset+i] = uint8(max(0, min(c, 1))*255 + 0.5)
+				}
+				img.Pix[offset+3] = 255
+			}
+		}
+		faces[face] = img
+	}
+	return faces
+}
@@ -5,4 +5,7 @@ go 1.21
 require (
 	github.com/go-gl/gl v0.0.0-20231021071112-07e5d0ea2e71
 	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20240506104042-037f3cc74f2a
+	golang.org/x/image v0.18.0
 )
+
+require golang.org/x/text v0.16.0 // indirect
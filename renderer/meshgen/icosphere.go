@@ -0,0 +1,69 @@
+package meshgen
+
+import (
+	"math"
+
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+)
+
+var icosahedronFaces = [20][3]uint32{
+	{0, 11, 5}, {0, 5, 1}, {0, 1, 7}, {0, 7, 10}, {0, 10, 11},
+	{1, 5, 9}, {5, 11, 4}, {11, 10, 2}, {10, 7, 6}, {7, 1, 8},
+	{3, 9, 4}, {3, 4, 2}, {3, 2, 6}, {3, 6, 8}, {3, 8, 9},
+	{4, 9, 5}, {2, 4, 11}, {6, 2, 10}, {8, 6, 7}, {9, 8, 1},
+}
+
+// Icosphere builds a sphere by splitting each face of an icosahedron into
+// four subdivisions times, giving evenly sized triangles. UVs are a
+// spherical projection and stretch across the seam.
+func Icosphere(radius float32, subdivisions int) *Mesh {
+	t := float32((1 + math.Sqrt(5)) / 2)
+	directions := []bmath.Vector3{
+		{X: -1, Y: t}, {X: 1, Y: t}, {X: -1, Y: -t}, {X: 1, Y: -t},
+		{Y: -1, Z: t}, {Y: 1, Z: t}, {Y: -1, Z: -t}, {Y: 1, Z: -t},
+		{X: t, Z: -1}, {X: t, Z: 1}, {X: -t, Z: -1}, {X: -t, Z: 1},
+	}
+	for i := range directions {
+		directions[i] = directions[i].Normalize()
+	}
+	faces := icosahedronFaces[:]
+
+	for level := 0; level < subdivisions; level++ {
+		midpoints := make(map[[2]uint32]uint32)
+		midpoint := func(a, b uint32) uint32 {
+			key := [2]uint32{min(a, b), max(a, b)}
+			if index, exists := midpoints[key]; exists {
+				return index
+			}
+			directions = append(directions, directions[a].Add(directions[b]).Normalize())
+			index := uint32(len(directions) - 1)
+			midpoints[key] = index
+			return index
+		}
+
+		next := make([][3]uint32, 0, len(faces)*4)
+		for _, f := range faces {
+			ab := midpoint(f[0], f[1])
+			bc := midpoint(f[1], f[2])
+			ca := midpoint(f[2], f[0])
+			next = append(next,
+				[3]uint32{f[0], ab, ca},
+				[3]uint32{f[1], bc, ab},
+				[3]uint32{f[2], ca, bc},
+				[3]uint32{ab, bc, ca},
+			)
+		}
+		faces = next
+	}
+
+	m := &Mesh{}
+	for _, d := range directions {
+		u := 0.5 + float32(math.Atan2(float64(d.X), float64(d.Z))/(2*math.Pi))
+		v := float32(math.Acos(float64(bmath.Clamp(d.Y, -1, 1))) / math.Pi)
+		m.addVertex(d.Mul(radius), d, u, v)
+	}
+	for _, f := range faces {
+		m.addTriangle(f[0], f[1], f[2])
+	}
+	return m.computeBounds()
+}
@@ -0,0 +1,76 @@
+package meshgen
+
+import (
+	"math"
+
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+)
+
+// profilePoint is a point of a surface of revolution in the (radius, y)
+// half-plane with its normal in that plane
+type profilePoint struct {
+	radius, y        float32
+	normalR, normalY float32
+	v                float32
+}
+
+// lathe revolves a profile around the Y axis. The profile must run from top
+// to bottom along the outside of the surface (clockwise in the radius-y
+// plane with the normal on its right) for the faces to wind outwards.
+// Columns are duplicated at the seam so U runs from 0 to 1; rows with zero
+// radius collapse to a point and only get one triangle per segment.
+func (m *Mesh) lathe(profile []profilePoint, segments int) {
+	base := uint32(len(m.Vertices))
+	columns := segments + 1
+
+	for _, p := range profile {
+		for i := 0; i <= segments; i++ {
+			u := float32(i) / float32(segments)
+			theta := float64(u) * 2 * math.Pi
+			sin, cos := float32(math.Sin(theta)), float32(math.Cos(theta))
+			position := bmath.NewVector3(p.radius*sin, p.y, p.radius*cos)
+
+			// A tip is shared by the faces on either side of its column,
+			// so its normal points between them
+			if p.radius == 0 && p.normalR != 0 {
+				theta += math.Pi / float64(segments)
+				sin, cos = float32(math.Sin(theta)), float32(math.Cos(theta))
+			}
+			normal := bmath.NewVector3(p.normalR*sin, p.normalY, p.normalR*cos).Normalize()
+
+			m.addVertex(position, normal, u, p.v)
+		}
+	}
+
+	for row := 0; row+1 < len(profile); row++ {
+		for i := 0; i < segments; i++ {
+			a := base + uint32(row*columns+i)
+			b := a + 1
+			d := a + uint32(columns)
+			c := d + 1
+
+			switch {
+			case profile[row].radius == 0:
+				m.addTriangle(a, d, c)
+			case profile[row+1].radius == 0:
+				m.addTriangle(a, d, b)
+			default:
+				m.addQuad(a, d, c, b)
+			}
+		}
+	}
+}
+
+// disc returns the profile of a flat cap facing up or down
+func disc(radius, y float32, up bool) []profilePoint {
+	if up {
+		return []profilePoint{
+			{radius: 0, y: y, normalY: 1, v: 0},
+			{radius: radius, y: y, normalY: 1, v: 1},
+		}
+	}
+	return []profilePoint{
+		{radius: radius, y: y, normalY: -1, v: 0},
+		{radius: 0, y: y, normalY: -1, v: 1},
+	}
+}
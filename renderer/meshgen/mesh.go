@@ -0,0 +1,79 @@
+// Package meshgen builds parameterized primitive meshes on the CPU. Every
+// mesh has unit normals pointing outwards, texture coordinates and
+// counter-clockwise front faces, and can be converted to the engine's
+// position and color vertex layout for upload.
+package meshgen
+
+import (
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+)
+
+type Vertex struct {
+	Position bmath.Vector3
+	Normal   bmath.Vector3
+	UV       [2]float32
+}
+
+// Mesh is an indexed triangle list
+type Mesh struct {
+	Vertices []Vertex
+	Indices  []uint32
+	Bounds   bmath.AABB
+}
+
+func (m *Mesh) addVertex(position, normal bmath.Vector3, u, v float32) uint32 {
+	m.Vertices = append(m.Vertices, Vertex{Position: position, Normal: normal, UV: [2]float32{u, v}})
+	return uint32(len(m.Vertices) - 1)
+}
+
+func (m *Mesh) addTriangle(a, b, c uint32) {
+	m.Indices = append(m.Indices, a, b, c)
+}
+
+// addQuad adds two triangles for the quad a, b, c, d in counter-clockwise order
+func (m *Mesh) addQuad(a, b, c, d uint32) {
+	m.Indices = append(m.Indices, a, b, c, c, d, a)
+}
+
+func (m *Mesh) computeBounds() *Mesh {
+	if len(m.Vertices) == 0 {
+		return m
+	}
+	m.Bounds = bmath.NewAABB(m.Vertices[0].Position, m.Vertices[0].Position)
+	for _, v := range m.Vertices[1:] {
+		m.Bounds = m.Bounds.Expand(v.Position)
+	}
+	return m
+}
+
+// PositionColor returns the vertices in the engine's x, y, z, r, g, b
+// layout, colored by their normals like the built-in sphere
+func (m *Mesh) PositionColor() []float32 {
+	vertices := make([]float32, 0, len(m.Vertices)*6)
+	for _, v := range m.Vertices {
+		vertices = append(vertices,
+			v.Position.X, v.Position.Y, v.Position.Z,
+			0.5+0.5*v.Normal.X, 0.5+0.5*v.Normal.Y, 0.5+0.5*v.Normal.Z,
+		)
+	}
+	return vertices
+}
+
+// Interleaved returns the vertices as position, normal and UV, eight
+// floats per vertex
+func (m *Mesh) Interleaved() []float32 {
+	vertices := make([]float32, 0, len(m.Vertices)*8)
+	for _, v := range m.Vertices {
+		vertices = append(vertices,
+			v.Position.X, v.Position.Y, v.Position.Z,
+			v.Normal.X, v.Normal.Y, v.Normal.Z,
+			v.UV[0], v.UV[1],
+		)
+	}
+	return vertices
+}
+
+// TriangleCount returns the number of triangles
+func (m *Mesh) TriangleCount() int {
+	return len(m.Indices) / 3
+}
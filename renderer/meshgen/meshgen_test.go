@@ -0,0 +1,170 @@
+package meshgen
+
+import (
+	"testing"
+
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+)
+
+const epsilon = 1e-4
+
+func near(a, b float32) bool {
+	return bmath.Abs(a-b) < epsilon
+}
+
+func nearVector(a, b bmath.Vector3) bool {
+	return near(a.X, b.X) && near(a.Y, b.Y) && near(a.Z, b.Z)
+}
+
+func TestCounts(t *testing.T) {
+	tests := []struct {
+		name     string
+		mesh     *Mesh
+		vertices int
+		indices  int
+	}{
+		{"sphere", Sphere(1, 16, 8), 9 * 17, 6 * 16 * 7},
+		{"icosphere/0", Icosphere(1, 0), 12, 60},
+		{"icosphere/2", Icosphere(1, 2), 10*16 + 2, 60 * 16},
+		{"cylinder", Cylinder(1, 2, 12), 6 * 13, 12 * 12},
+		{"cone", Cone(1, 2, 12), 4 * 13, 6 * 12},
+		{"torus", Torus(1, 0.25, 24, 8), 25 * 9, 6 * 24 * 8},
+		{"capsule", Capsule(0.5, 2, 12, 4), 10 * 13, 12 * 12 * 4},
+		{"plane grid", PlaneGrid(4, 2, 4, 2), 5 * 3, 6 * 4 * 2},
+		{"box", Box(bmath.NewVector3(1, 1, 1), 0, 0), 24, 36},
+		{"beveled box", Box(bmath.NewVector3(1, 2, 3), 0.1, 3), 6 * 8 * 8, 6 * 7 * 7 * 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(tt.mesh.Vertices); got != tt.vertices {
+				t.Errorf("vertices = %d, want %d", got, tt.vertices)
+			}
+			if got := len(tt.mesh.Indices); got != tt.indices {
+				t.Errorf("indices = %d, want %d", got, tt.indices)
+			}
+			for _, index := range tt.mesh.Indices {
+				if int(index) >= len(tt.mesh.Vertices) {
+					t.Fatalf("index %d out of range", index)
+				}
+			}
+			if got := len(tt.mesh.PositionColor()); got != tt.vertices*6 {
+				t.Errorf("PositionColor floats = %d, want %d", got, tt.vertices*6)
+			}
+			if got := len(tt.mesh.Interleaved()); got != tt.vertices*8 {
+				t.Errorf("Interleaved floats = %d, want %d", got, tt.vertices*8)
+			}
+		})
+	}
+}
+
+func allMeshes() map[string]*Mesh {
+	return map[string]*Mesh{
+		"sphere":      Sphere(0.5, 16, 16),
+		"icosphere":   Icosphere(0.5, 2),
+		"cylinder":    Cylinder(0.5, 2, 16),
+		"cone":        Cone(0.5, 2, 16),
+		"torus":       Torus(1, 0.3, 24, 12),
+		"capsule":     Capsule(0.5, 2, 16, 6),
+		"plane grid":  PlaneGrid(10, 10, 5, 5),
+		"box":         Box(bmath.NewVector3(1, 2, 3), 0, 1),
+		"beveled box": Box(bmath.NewVector3(1, 2, 3), 0.2, 4),
+	}
+}
+
+// TestNormals checks that normals are unit length and that every triangle
+// winds counter-clockwise around the normals of its vertices
+func TestNormals(t *testing.T) {
+	for name, m := range allMeshes() {
+		t.Run(name, func(t *testing.T) {
+			for i, v := range m.Vertices {
+				if !near(v.Normal.Length(), 1) {
+					t.Fatalf("vertex %d normal %v is not unit length", i, v.Normal)
+				}
+				if v.UV[0] < -epsilon || v.UV[0] > 1+epsilon || v.UV[1] < -epsilon || v.UV[1] > 1+epsilon {
+					t.Fatalf("vertex %d UV %v outside [0, 1]", i, v.UV)
+				}
+			}
+
+			for i := 0; i < len(m.Indices); i += 3 {
+				a := m.Vertices[m.Indices[i]]
+				b := m.Vertices[m.Indices[i+1]]
+				c := m.Vertices[m.Indices[i+2]]
+
+				face := b.Position.Sub(a.Position).Cross(c.Position.Sub(a.Position))
+				if face.Length() < 1e-6 {
+					continue
+				}
+				average := a.Normal.Add(b.Normal).Add(c.Normal)
+				if face.Dot(average) <= 0 {
+					t.Fatalf("triangle %d winds against its normals", i/3)
+				}
+			}
+		})
+	}
+}
+
+func TestSphereNormalsPointFromCenter(t *testing.T) {
+	for name, m := range map[string]*Mesh{"sphere": Sphere(2, 12, 6), "icosphere": Icosphere(2, 1)} {
+		for i, v := range m.Vertices {
+			if !near(v.Position.Length(), 2) {
+				t.Fatalf("%s: vertex %d at distance %v, want 2", name, i, v.Position.Length())
+			}
+			if !nearVector(v.Normal, v.Position.Normalize()) {
+				t.Fatalf("%s: vertex %d normal %v, want %v", name, i, v.Normal, v.Position.Normalize())
+			}
+		}
+	}
+}
+
+func TestTorusNormalsPointFromTube(t *testing.T) {
+	m := Torus(1, 0.25, 16, 8)
+	for i, v := range m.Vertices {
+		ring := bmath.NewVector3(v.Position.X, 0, v.Position.Z).Normalize()
+		fromTube := v.Position.Sub(ring).Normalize()
+		if !nearVector(v.Normal, fromTube) {
+			t.Fatalf("vertex %d normal %v, want %v", i, v.Normal, fromTube)
+		}
+	}
+}
+
+func TestBeveledBoxNormals(t *testing.T) {
+	m := Box(bmath.NewVector3(2, 2, 2), 0.25, 3)
+	inner := float32(0.75)
+	for i, v := range m.Vertices {
+		core := bmath.NewVector3(
+			bmath.Clamp(v.Position.X, -inner, inner),
+			bmath.Clamp(v.Position.Y, -inner, inner),
+			bmath.Clamp(v.Position.Z, -inner, inner),
+		)
+		offset := v.Position.Sub(core)
+		if !near(offset.Length(), 0.25) {
+			t.Fatalf("vertex %d is %v from the inner box, want 0.25", i, offset.Length())
+		}
+		if !nearVector(v.Normal, offset.Normalize()) {
+			t.Fatalf("vertex %d normal %v, want %v", i, v.Normal, offset.Normalize())
+		}
+	}
+}
+
+func TestBounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		mesh     *Mesh
+		min, max bmath.Vector3
+	}{
+		{"sphere", Sphere(0.5, 16, 16), bmath.NewVector3(-0.5, -0.5, -0.5), bmath.NewVector3(0.5, 0.5, 0.5)},
+		{"cylinder", Cylinder(0.5, 2, 16), bmath.NewVector3(-0.5, -1, -0.5), bmath.NewVector3(0.5, 1, 0.5)},
+		{"cone", Cone(1, 3, 4), bmath.NewVector3(-1, -1.5, -1), bmath.NewVector3(1, 1.5, 1)},
+		{"torus", Torus(1, 0.25, 16, 8), bmath.NewVector3(-1.25, -0.25, -1.25), bmath.NewVector3(1.25, 0.25, 1.25)},
+		{"capsule", Capsule(0.5, 3, 16, 4), bmath.NewVector3(-0.5, -1.5, -0.5), bmath.NewVector3(0.5, 1.5, 0.5)},
+		{"plane grid", PlaneGrid(4, 2, 3, 3), bmath.NewVector3(-2, 0, -1), bmath.NewVector3(2, 0, 1)},
+		{"beveled box", Box(bmath.NewVector3(1, 2, 3), 0.2, 2), bmath.NewVector3(-0.5, -1, -1.5), bmath.NewVector3(0.5, 1, 1.5)},
+	}
+
+	for _, tt := range tests {
+		if !nearVector(tt.mesh.Bounds.Min, tt.min) || !nearVector(tt.mesh.Bounds.Max, tt.max) {
+			t.Errorf("%s: bounds %v, want {%v %v}", tt.name, tt.mesh.Bounds, tt.min, tt.max)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package meshgen
+
+import (
+	"math"
+
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+)
+
+// Sphere builds a UV sphere centered at the origin with segments around
+// the Y axis and rings from pole to pole
+func Sphere(radius float32, segments, rings int) *Mesh {
+	segments = max(segments, 3)
+	rings = max(rings, 2)
+
+	profile := make([]profilePoint, rings+1)
+	for ring := range profile {
+		v := float32(ring) / float32(rings)
+		phi := float64(v) * math.Pi
+		sin, cos := float32(math.Sin(phi)), float32(math.Cos(phi))
+		if ring == 0 || ring == rings {
+			sin = 0
+		}
+		profile[ring] = profilePoint{radius: radius * sin, y: radius * cos, normalR: sin, normalY: cos, v: v}
+	}
+
+	m := &Mesh{}
+	m.lathe(profile, segments)
+	return m.computeBounds()
+}
+
+// Cylinder builds a capped cylinder along the Y axis centered at the origin
+func Cylinder(radius, height float32, segments int) *Mesh {
+	segments = max(segments, 3)
+	top, bottom := height/2, -height/2
+
+	m := &Mesh{}
+	m.lathe([]profilePoint{
+		{radius: radius, y: top, normalR: 1, v: 0},
+		{radius: radius, y: bottom, normalR: 1, v: 1},
+	}, segments)
+	m.lathe(disc(radius, top, true), segments)
+	m.lathe(disc(radius, bottom, false), segments)
+	return m.computeBounds()
+}
+
+// Cone builds a cone along the Y axis with its apex at height/2 and a
+// capped base at -height/2
+func Cone(radius, height float32, segments int) *Mesh {
+	segments = max(segments, 3)
+	top, bottom := height/2, -height/2
+
+	// The side normal leans up by the cone's slope
+	slope := bmath.NewVector3(height, radius, 0).Normalize()
+
+	m := &Mesh{}
+	m.lathe([]profilePoint{
+		{radius: 0, y: top, normalR: slope.X, normalY: slope.Y, v: 0},
+		{radius: radius, y: bottom, normalR: slope.X, normalY: slope.Y, v: 1},
+	}, segments)
+	m.lathe(disc(radius, bottom, false), segments)
+	return m.computeBounds()
+}
+
+// Capsule builds a cylinder with hemispherical ends along the Y axis.
+// height is the total height including the ends and is at least 2*radius;
+// rings is the number of rings per hemisphere.
+func Capsule(radius, height float32, segments, rings int) *Mesh {
+	segments = max(segments, 3)
+	rings = max(rings, 1)
+	half := max(height/2-radius, 0)
+	total := 2 * (half + radius)
+
+	profile := make([]profilePoint, 0, 2*rings+2)
+	for hemisphere, offset := range []float32{half, -half} {
+		for ring := 0; ring <= rings; ring++ {
+			phi := (float64(ring)/float64(rings) + float64(hemisphere)) * math.Pi / 2
+			sin, cos := float32(math.Sin(phi)), float32(math.Cos(phi))
+			if (hemisphere == 0 && ring == 0) || (hemisphere == 1 && ring == rings) {
+				sin = 0
+			}
+			y := offset + radius*cos
+			profile = append(profile, profilePoint{
+				radius:  radius * sin,
+				y:       y,
+				normalR: sin,
+				normalY: cos,
+				v:       (total/2 - y) / total,
+			})
+		}
+	}
+
+	m := &Mesh{}
+	m.lathe(profile, segments)
+	return m.computeBounds()
+}
+
+// Torus builds a ring around the Y axis. majorRadius is the distance from
+// the center to the middle of the tube and minorRadius the tube's radius.
+func Torus(majorRadius, minorRadius float32, majorSegments, minorSegments int) *Mesh {
+	majorSegments = max(majorSegments, 3)
+	minorSegments = max(minorSegments, 3)
+
+	// Walk the tube's cross-section clockwise from its top
+	profile := make([]profilePoint, minorSegments+1)
+	for i := range profile {
+		v := float32(i) / float32(minorSegments)
+		psi := math.Pi/2 - float64(v)*2*math.Pi
+		cos, sin := float32(math.Cos(psi)), float32(math.Sin(psi))
+		profile[i] = profilePoint{
+			radius:  majorRadius + minorRadius*cos,
+			y:       minorRadius * sin,
+			normalR: cos,
+			normalY: sin,
+			v:       v,
+		}
+	}
+
+	m := &Mesh{}
+	m.lathe(profile, majorSegments)
+	return m.computeBounds()
+}
+
+// PlaneGrid builds a grid in the XZ plane facing +Y, centered at the origin
+func PlaneGrid(width, depth float32, xSegments, zSegments int) *Mesh {
+	xSegments = max(xSegments, 1)
+	zSegments = max(zSegments, 1)
+
+	m := &Mesh{}
+	for j := 0; j <= zSegments; j++ {
+		v := float32(j) / float32(zSegments)
+		for i := 0; i <= xSegments; i++ {
+			u := float32(i) / float32(xSegments)
+			position := bmath.NewVector3((u-0.5)*width, 0, (v-0.5)*depth)
+			m.addVertex(position, bmath.Vector3Up, u, v)
+		}
+	}
+
+	columns := uint32(xSegments + 1)
+	for j := 0; j < zSegments; j++ {
+		for i := 0; i < xSegments; i++ {
+			a := uint32(j)*columns + uint32(i)
+			m.addQuad(a, a+columns, a+columns+1, a+1)
+		}
+	}
+	return m.computeBounds()
+}
+
+// boxFaces lists each face's normal and the tangent axes of its grid,
+// with u x v = normal so the grid winds outwards
+var boxFaces = [6][3]bmath.Vector3{
+	{{X: 1}, {Z: -1}, {Y: 1}},
+	{{X: -1}, {Z: 1}, {Y: 1}},
+	{{Y: 1}, {X: 1}, {Z: -1}},
+	{{Y: -1}, {X: 1}, {Z: 1}},
+	{{Z: 1}, {X: 1}, {Y: 1}},
+	{{Z: -1}, {X: -1}, {Y: 1}},
+}
+
+// Box builds a box of the given size centered at the origin. A positive
+// bevel rounds its edges and corners with that radius, using
+// bevelSegments steps per rounded edge; without a bevel each face is a
+// single quad with its own vertices.
+func Box(size bmath.Vector3, bevel float32, bevelSegments int) *Mesh {
+	half := size.Mul(0.5)
+	bevel = max(0, min(bevel, half.X, half.Y, half.Z))
+	bevelSegments = max(bevelSegments, 1)
+	inner := half.Sub(bmath.NewVector3(bevel, bevel, bevel))
+
+	m := &Mesh{}
+	for _, face := range boxFaces {
+		normal, uAxis, vAxis := face[0], face[1], face[2]
+		hu := bmath.Abs(uAxis.Dot(half))
+		hv := bmath.Abs(vAxis.Dot(half))
+		hn := bmath.Abs(normal.Dot(half))
+		us := boxCoordinates(hu, bevel, bevelSegments)
+		vs := boxCoordinates(hv, bevel, bevelSegments)
+
+		base := uint32(len(m.Vertices))
+		for _, cv := range vs {
+			for _, cu := range us {
+				p := normal.Mul(hn).Add(uAxis.Mul(cu)).Add(vAxis.Mul(cv))
+				u, v := (cu+hu)/(2*hu), (cv+hv)/(2*hv)
+				if bevel == 0 {
+					m.addVertex(p, normal, u, v)
+					continue
+				}
+
+				// Pull the point onto the rounded surface around the inner box
+				core := bmath.NewVector3(
+					bmath.Clamp(p.X, -inner.X, inner.X),
+					bmath.Clamp(p.Y, -inner.Y, inner.Y),
+					bmath.Clamp(p.Z, -inner.Z, inner.Z),
+				)
+				n := p.Sub(core).Normalize()
+				m.addVertex(core.Add(n.Mul(bevel)), n, u, v)
+			}
+		}
+
+		columns := uint32(len(us))
+		for j := 0; j+1 < len(vs); j++ {
+			for i := 0; i+1 < len(us); i++ {
+				a := base + uint32(j)*columns + uint32(i)
+				m.addQuad(a, a+1, a+columns+1, a+columns)
+			}
+		}
+	}
+	return m.computeBounds()
+}
+
+// boxCoordinates returns the grid coordinates across a face of half-size
+// h: the bevel is split into segments at both ends, the flat middle is one
+// step
+func boxCoordinates(h, bevel float32, segments int) []float32 {
+	if bevel == 0 {
+		return []float32{-h, h}
+	}
+	coordinates := make([]float32, 0, 2*segments+2)
+	for k := 0; k <= segments; k++ {
+		coordinates = append(coordinates, -h+bevel*float32(k)/float32(segments))
+	}
+	for k := 0; k <= segments; k++ {
+		coordinates = append(coordinates, h-bevel+bevel*float32(k)/float32(segments))
+	}
+	return coordinates
+}
@@ -0,0 +1,382 @@
+package opengl
+
+import (
+	"fmt"
+	"image"
+	"time"
+
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/backend"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/resource"
+)
+
+// backendMesh is a mesh exposed through a handle; resource is zero for
+// meshes the backend does not own
+type backendMesh struct {
+	mesh     *Mesh
+	resource resource.ID
+}
+
+// backendTexture is either a 2D texture or a cubemap
+type backendTexture struct {
+	texture  *Texture
+	cubemap  *Cubemap
+	resource resource.ID
+}
+
+// Backend implements backend.Backend on top of an OpenGL context
+type Backend struct {
+	context     *Context
+	shader      *Shader
+	instanced   *Shader
+	viewShader  *Shader
+	sprites     *SpriteRenderer
+	instances   *InstanceBuffer
+	lines       *LineBuffer
+	skybox      *Skybox
+	meshes      map[backend.MeshHandle]backendMesh
+	nextHandle  backend.MeshHandle
+	textures    map[backend.TextureHandle]backendTexture
+	nextTexture backend.TextureHandle
+	view        bmath.Matrix4
+	projection  bmath.Matrix4
+	clearColor  [4]float32
+	viewMode    backend.ViewMode
+	single      [1]backend.Instance
+	resources   *resource.Manager
+	internal    []resource.ID
+	timers      *GPUTimers
+}
+
+var _ backend.Backend = (*Backend)(nil)
+
+func NewBackend(ctx *Context) (*Backend, error) {
+	shader, err := NewShader(DefaultVertexShader, DefaultFragmentShader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backend shader: %w", err)
+	}
+
+	instanced, err := NewShader(InstancedVertexShader, InstancedFragmentShader)
+	if err != nil {
+		shader.Delete()
+		return nil, fmt.Errorf("failed to create instanced shader: %w", err)
+	}
+
+	viewShader, err := NewShader(ViewModeVertexShader, ViewModeFragmentShader)
+	if err != nil {
+		instanced.Delete()
+		shader.Delete()
+		return nil, fmt.Errorf("failed to create view mode shader: %w", err)
+	}
+
+	skybox, err := NewSkybox()
+	if err != nil {
+		viewShader.Delete()
+		instanced.Delete()
+		shader.Delete()
+		return nil, fmt.Errorf("failed to create skybox: %w", err)
+	}
+
+	sprites, err := NewSpriteRenderer()
+	if err != nil {
+		skybox.Delete()
+		viewShader.Delete()
+		instanced.Delete()
+		shader.Delete()
+		return nil, err
+	}
+
+	b := &Backend{
+		context:     ctx,
+		shader:      shader,
+		instanced:   instanced,
+		viewShader:  viewShader,
+		sprites:     sprites,
+		instances:   NewInstanceBuffer(),
+		lines:       NewLineBuffer(),
+		skybox:      skybox,
+		meshes:      make(map[backend.MeshHandle]backendMesh),
+		nextHandle:  1,
+		textures:    make(map[backend.TextureHandle]backendTexture),
+		nextTexture: 1,
+		view:        bmath.NewMatrix4Identity(),
+		projection:  bmath.NewMatrix4Identity(),
+		clearColor:  [4]float32{0.1, 0.1, 0.1, 1.0},
+		resources:   resource.NewManager(),
+		timers:      NewGPUTimers(),
+	}
+
+	b.internal = []resource.ID{
+		b.Track(resource.KindShader, "default", shader),
+		b.Track(resource.KindShader, "instanced", instanced),
+		b.Track(resource.KindShader, "view mode", viewShader),
+		b.Track(resource.KindMesh, "skybox", skybox),
+		b.Track(resource.KindMesh, "sprites", sprites),
+		b.Track(resource.KindBuffer, "instances", b.instances),
+		b.Track(resource.KindBuffer, "lines", b.lines),
+		b.Track(resource.KindQuery, "timers", b.timers),
+	}
+	return b, nil
+}
+
+// Resources returns the manager tracking the backend's GPU objects, with
+// which other owners can register theirs for leak reporting
+func (b *Backend) Resources() *resource.Manager {
+	return b.resources
+}
+
+// Track registers object with the resource manager under name and, when
+// GL debug output is on, labels its GL objects with the same name
+func (b *Backend) Track(kind resource.Kind, name string, object resource.Deleter) resource.ID {
+	if labeled, ok := object.(interface{ SetLabel(string) }); ok {
+		labeled.SetLabel(name)
+	}
+	return b.resources.Register(kind, name, object)
+}
+
+func (b *Backend) CreateMesh(vertices []float32, indices []uint32) backend.MeshHandle {
+	var mesh *Mesh
+	if indices == nil {
+		mesh = NewMesh(vertices)
+	} else {
+		mesh = NewIndexedMesh(vertices, indices)
+	}
+	handle := b.register(mesh)
+	entry := b.meshes[handle]
+	entry.resource = b.Track(resource.KindMesh, fmt.Sprintf("mesh #%d", handle), mesh)
+	b.meshes[handle] = entry
+	return handle
+}
+
+// AddMesh exposes an existing mesh through the backend without taking
+// ownership of it; the caller remains responsible for deleting it.
+func (b *Backend) AddMesh(mesh *Mesh) backend.MeshHandle {
+	return b.register(mesh)
+}
+
+func (b *Backend) register(mesh *Mesh) backend.MeshHandle {
+	handle := b.nextHandle
+	b.nextHandle++
+	b.meshes[handle] = backendMesh{mesh: mesh}
+	return handle
+}
+
+// RetainMesh adds a reference to a mesh created by CreateMesh, for owners
+// sharing one handle; each reference is dropped with DeleteMesh
+func (b *Backend) RetainMesh(handle backend.MeshHandle) {
+	if entry, exists := b.meshes[handle]; exists && entry.resource != 0 {
+		b.resources.Retain(entry.resource)
+	}
+}
+
+// DeleteMesh drops a reference to a mesh. The handle stops working with
+// the last reference; the GL objects are freed at the start of the next
+// frame.
+func (b *Backend) DeleteMesh(handle backend.MeshHandle) {
+	entry, exists := b.meshes[handle]
+	if !exists {
+		return
+	}
+	if entry.resource != 0 {
+		b.resources.Release(entry.resource)
+		if _, live := b.resources.Get(entry.resource); live {
+			return
+		}
+	}
+	delete(b.meshes, handle)
+}
+
+func (b *Backend) DrawMesh(handle backend.MeshHandle, model bmath.Matrix4) {
+	entry, exists := b.meshes[handle]
+	if !exists {
+		return
+	}
+
+	// The view modes are implemented on the instanced path
+	if b.viewMode != backend.ViewShaded {
+		b.single[0] = backend.Instance{Model: model, Color: [4]float32{1, 1, 1, 1}}
+		b.DrawMeshInstanced(handle, b.single[:])
+		return
+	}
+
+	b.shader.Use()
+	b.shader.SetMatrix4("model", &model[0])
+	b.shader.SetMatrix4("view", &b.view[0])
+	b.shader.SetMatrix4("projection", &b.projection[0])
+
+	entry.mesh.Draw()
+}
+
+func (b *Backend) DrawMeshInstanced(handle backend.MeshHandle, instances []backend.Instance) {
+	entry, exists := b.meshes[handle]
+	if !exists || len(instances) == 0 {
+		return
+	}
+
+	b.instances.Upload(instances)
+	b.drawViewMode(entry.mesh, int32(len(instances)))
+}
+
+func (b *Backend) DrawLines(vertices []float32, depthTest bool) {
+	if !depthTest {
+		b.context.SetDepthTest(false)
+		defer b.context.SetDepthTest(true)
+	}
+
+	identity := bmath.NewMatrix4Identity()
+	b.shader.Use()
+	b.shader.SetMatrix4("model", &identity[0])
+	b.shader.SetMatrix4("view", &b.view[0])
+	b.shader.SetMatrix4("projection", &b.projection[0])
+
+	b.lines.Draw(vertices)
+}
+
+func (b *Backend) MeshBounds(handle backend.MeshHandle) bmath.AABB {
+	entry, exists := b.meshes[handle]
+	if !exists {
+		return bmath.AABB{}
+	}
+	return entry.mesh.GetBounds()
+}
+
+func (b *Backend) CreateTexture(img *image.RGBA, smooth bool) backend.TextureHandle {
+	handle := b.nextTexture
+	b.nextTexture++
+	texture := NewTexture(img, smooth)
+	b.textures[handle] = backendTexture{
+		texture:  texture,
+		resource: b.Track(resource.KindTexture, fmt.Sprintf("texture #%d", handle), texture),
+	}
+	return handle
+}
+
+func (b *Backend) CreateDistanceFieldTexture(img *image.RGBA) backend.TextureHandle {
+	handle := b.nextTexture
+	b.nextTexture++
+	texture := NewDistanceFieldTexture(img)
+	b.textures[handle] = backendTexture{
+		texture:  texture,
+		resource: b.Track(resource.KindTexture, fmt.Sprintf("distance field #%d", handle), texture),
+	}
+	return handle
+}
+
+func (b *Backend) UpdateTexture(handle backend.TextureHandle, img *image.RGBA) {
+	entry, exists := b.textures[handle]
+	if !exists || entry.texture == nil {
+		return
+	}
+	entry.texture.Update(img)
+}
+
+func (b *Backend) CreateCubemap(faces backend.CubemapFaces) backend.TextureHandle {
+	handle := b.nextTexture
+	b.nextTexture++
+	cubemap := NewCubemap(faces)
+	b.textures[handle] = backendTexture{
+		cubemap:  cubemap,
+		resource: b.Track(resource.KindTexture, fmt.Sprintf("cubemap #%d", handle), cubemap),
+	}
+	return handle
+}
+
+// RetainTexture adds a reference to a texture; each reference is dropped
+// with DeleteTexture
+func (b *Backend) RetainTexture(handle backend.TextureHandle) {
+	if entry, exists := b.textures[handle]; exists {
+		b.resources.Retain(entry.resource)
+	}
+}
+
+func (b *Backend) DeleteTexture(handle backend.TextureHandle) {
+	entry, exists := b.textures[handle]
+	if !exists {
+		return
+	}
+	b.resources.Release(entry.resource)
+	if _, live := b.resources.Get(entry.resource); !live {
+		delete(b.textures, handle)
+	}
+}
+
+func (b *Backend) DrawSkybox(handle backend.TextureHandle) {
+	entry, exists := b.textures[handle]
+	if !exists || entry.cubemap == nil {
+		return
+	}
+	b.skybox.Draw(b.context, entry.cubemap, &b.view[0], &b.projection[0])
+}
+
+func (b *Backend) DrawSprites(handle backend.TextureHandle, vertices []backend.SpriteVertex) {
+	entry, exists := b.textures[handle]
+	if !exists || entry.texture == nil {
+		return
+	}
+	b.sprites.Draw(b.context, entry.texture, vertices, &b.view[0], &b.projection[0])
+}
+
+func (b *Backend) SetCamera(view, projection bmath.Matrix4) {
+	b.view = view
+	b.projection = projection
+}
+
+func (b *Backend) SetViewport(width, height int) {
+	b.context.SetViewport(0, 0, int32(width), int32(height))
+}
+
+func (b *Backend) SetClearColor(r, g, bl, a float32) {
+	b.clearColor = [4]float32{r, g, bl, a}
+}
+
+func (b *Backend) SetDepthWrite(enabled bool) {
+	b.context.SetDepthWrite(enabled)
+}
+
+func (b *Backend) SetViewMode(mode backend.ViewMode) {
+	b.viewMode = mode
+}
+
+// BeginTimer starts measuring the GPU time of a render pass; passes do
+// not nest
+func (b *Backend) BeginTimer(name string) {
+	b.timers.Begin(name)
+}
+
+// EndTimer ends the pass started by BeginTimer
+func (b *Backend) EndTimer() {
+	b.timers.End()
+}
+
+// ResolveTimers reports pass timings as they become available, a few
+// frames after they were measured
+func (b *Backend) ResolveTimers(record func(name string, elapsed time.Duration)) {
+	b.timers.Resolve(record)
+}
+
+func (b *Backend) BeginFrame() {
+	// Nothing from the previous frame is in use any more
+	b.resources.Collect()
+
+	b.context.ApplyDefaultState()
+	b.context.Clear(b.clearColor[0], b.clearColor[1], b.clearColor[2], b.clearColor[3])
+}
+
+func (b *Backend) EndFrame() {
+	// Buffer swapping is owned by the window
+	b.context.CheckErrors("frame")
+}
+
+// Cleanup frees the backend's own objects and reports meshes and textures
+// that were created but never deleted, as well as objects other owners
+// registered and did not release, before freeing them too
+func (b *Backend) Cleanup() {
+	for _, id := range b.internal {
+		b.resources.Release(id)
+	}
+	b.internal = nil
+
+	b.resources.Shutdown()
+	b.meshes = make(map[backend.MeshHandle]backendMesh)
+	b.textures = make(map[backend.TextureHandle]backendTexture)
+}
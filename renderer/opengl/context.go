@@ -9,6 +9,8 @@ import (
 
 type Context struct {
 	initialized bool
+	debugMode   DebugMode
+	logger      *DebugLogger
 }
 
 func NewContext() (*Context, error) {
@@ -26,15 +28,25 @@ func NewContext() (*Context, error) {
 	fmt.Printf("Vendor: %s\n", vendor)
 	fmt.Printf("Renderer: %s\n", renderer)
 	
+	ctx.ApplyDefaultState()
+	
+	return ctx, nil
+}
+
+// ApplyDefaultState restores the pipeline state the renderer relies on,
+// which overlays such as the editor GUI may have changed
+func (c *Context) ApplyDefaultState() {
 	gl.Enable(gl.DEPTH_TEST)
 	gl.DepthFunc(gl.LESS)
+	gl.DepthMask(true)
 	gl.Enable(gl.BLEND)
 	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+	gl.Disable(gl.POLYGON_OFFSET_LINE)
+	gl.Enable(gl.TEXTURE_CUBE_MAP_SEAMLESS)
 	
 	// Disable face culling for now to debug
 	gl.Disable(gl.CULL_FACE)
-	
-	return ctx, nil
 }
 
 func (c *Context) Clear(r, g, b, a float32) {
@@ -42,6 +54,51 @@ func (c *Context) Clear(r, g, b, a float32) {
 	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 }
 
+func (c *Context) SetDepthTest(enabled bool) {
+	if enabled {
+		gl.Enable(gl.DEPTH_TEST)
+	} else {
+		gl.Disable(gl.DEPTH_TEST)
+	}
+}
+
+func (c *Context) SetDepthWrite(enabled bool) {
+	gl.DepthMask(enabled)
+}
+
+// SetDepthLessEqual lets fragments exactly on the stored depth pass, as
+// the skybox needs on the far plane
+func (c *Context) SetDepthLessEqual(enabled bool) {
+	if enabled {
+		gl.DepthFunc(gl.LEQUAL)
+	} else {
+		gl.DepthFunc(gl.LESS)
+	}
+}
+
+// SetWireframe rasterizes polygons as their edges. Edges are pulled
+// slightly towards the camera so they win the depth test against the
+// filled surface they outline.
+func (c *Context) SetWireframe(enabled bool) {
+	if enabled {
+		gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
+		gl.Enable(gl.POLYGON_OFFSET_LINE)
+		gl.PolygonOffset(-1, -1)
+	} else {
+		gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+		gl.Disable(gl.POLYGON_OFFSET_LINE)
+	}
+}
+
+// SetAdditiveBlend switches between additive and the default alpha blending
+func (c *Context) SetAdditiveBlend(enabled bool) {
+	if enabled {
+		gl.BlendFunc(gl.ONE, gl.ONE)
+	} else {
+		gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	}
+}
+
 func (c *Context) SetViewport(x, y, width, height int32) {
 	gl.Viewport(x, y, width, height)
 }
@@ -0,0 +1,346 @@
+package opengl
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// Severity orders debug messages from purely informational to errors
+type Severity int
+
+const (
+	SeverityNotification Severity = iota
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityNotification:
+		return "notification"
+	case SeverityLow:
+		return "low"
+	case SeverityMedium:
+		return "medium"
+	case SeverityHigh:
+		return "high"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// Source is the part of the GL implementation a debug message came from
+type Source int
+
+const (
+	SourceAPI Source = iota
+	SourceWindowSystem
+	SourceShaderCompiler
+	SourceThirdParty
+	SourceApplication
+	SourceOther
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceAPI:
+		return "api"
+	case SourceWindowSystem:
+		return "window"
+	case SourceShaderCompiler:
+		return "shader"
+	case SourceThirdParty:
+		return "third-party"
+	case SourceApplication:
+		return "application"
+	default:
+		return "other"
+	}
+}
+
+// DebugMode is how GL errors reach the logger
+type DebugMode int
+
+const (
+	// DebugOff reports nothing
+	DebugOff DebugMode = iota
+	// DebugCallback receives messages from the driver through KHR_debug
+	DebugCallback
+	// DebugPolling checks glGetError at fixed points, for contexts without
+	// KHR_debug such as OpenGL 4.1 on macOS
+	DebugPolling
+)
+
+func (m DebugMode) String() string {
+	switch m {
+	case DebugCallback:
+		return "KHR_debug"
+	case DebugPolling:
+		return "glGetError"
+	default:
+		return "off"
+	}
+}
+
+// DebugMessage is a single message from the GL debug layer
+type DebugMessage struct {
+	Source   Source
+	Type     string
+	ID       uint32
+	Severity Severity
+	Message  string
+}
+
+// DebugLogger writes GL debug messages as key=value lines, dropping those
+// below a minimum severity or from muted sources
+type DebugLogger struct {
+	mu          sync.Mutex
+	out         io.Writer
+	minSeverity Severity
+	muted       map[Source]bool
+	counts      [SeverityHigh + 1]int
+}
+
+// NewDebugLogger creates a logger writing to out, or to standard output
+// when out is nil. Notifications are filtered out by default.
+func NewDebugLogger(out io.Writer) *DebugLogger {
+	if out == nil {
+		out = os.Stdout
+	}
+	return &DebugLogger{
+		out:         out,
+		minSeverity: SeverityLow,
+		muted:       make(map[Source]bool),
+	}
+}
+
+// SetMinSeverity drops messages below severity
+func (l *DebugLogger) SetMinSeverity(severity Severity) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.minSeverity = severity
+}
+
+// MuteSource drops or restores messages from source
+func (l *DebugLogger) MuteSource(source Source, muted bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.muted[source] = muted
+}
+
+// Log writes msg unless it is filtered
+func (l *DebugLogger) Log(msg DebugMessage) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if msg.Severity < l.minSeverity || l.muted[msg.Source] {
+		return
+	}
+	if msg.Severity >= 0 && msg.Severity <= SeverityHigh {
+		l.counts[msg.Severity]++
+	}
+	fmt.Fprintf(l.out, "gl severity=%v source=%v type=%s id=%d msg=%q\n",
+		msg.Severity, msg.Source, msg.Type, msg.ID, strings.TrimSpace(msg.Message))
+}
+
+// Count returns how many messages of severity were written
+func (l *DebugLogger) Count(severity Severity) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if severity < 0 || severity > SeverityHigh {
+		return 0
+	}
+	return l.counts[severity]
+}
+
+// labelsEnabled is set once KHR_debug is available; object labels are
+// ignored otherwise
+var labelsEnabled bool
+
+// setLabel names a GL object for capture tools such as RenderDoc
+func setLabel(identifier, name uint32, label string) {
+	if !labelsEnabled || name == 0 || label == "" {
+		return
+	}
+	gl.ObjectLabel(identifier, name, int32(len(label)), gl.Str(label+"\x00"))
+}
+
+// EnableDebug routes GL errors into logger. KHR_debug is used when the
+// context supports it, falling back to glGetError polling in CheckErrors.
+func (c *Context) EnableDebug(logger *DebugLogger) DebugMode {
+	c.logger = logger
+
+	if !hasKHRDebug() {
+		c.debugMode = DebugPolling
+		return c.debugMode
+	}
+
+	var flags int32
+	gl.GetIntegerv(gl.CONTEXT_FLAGS, &flags)
+	if flags&gl.CONTEXT_FLAG_DEBUG_BIT == 0 {
+		fmt.Printf("OpenGL debug: context was not created with the debug flag, messages may be incomplete\n")
+	}
+
+	gl.Enable(gl.DEBUG_OUTPUT)
+	// Report messages from the thread and call that caused them
+	gl.Enable(gl.DEBUG_OUTPUT_SYNCHRONOUS)
+	gl.DebugMessageCallback(func(source, gltype, id, severity uint32, length int32, message string, userParam unsafe.Pointer) {
+		logger.Log(DebugMessage{
+			Source:   debugSource(source),
+			Type:     debugType(gltype),
+			ID:       id,
+			Severity: debugSeverity(severity),
+			Message:  message,
+		})
+	}, nil)
+	gl.DebugMessageControl(gl.DONT_CARE, gl.DONT_CARE, gl.DONT_CARE, 0, nil, true)
+
+	labelsEnabled = true
+	c.debugMode = DebugCallback
+	return c.debugMode
+}
+
+// DebugMode reports how errors are being checked
+func (c *Context) DebugMode() DebugMode {
+	return c.debugMode
+}
+
+// CheckErrors logs every pending glGetError code when polling, naming the
+// operation that preceded the check, and returns how many were found
+func (c *Context) CheckErrors(operation string) int {
+	if c.debugMode != DebugPolling {
+		return 0
+	}
+
+	count := 0
+	for code := gl.GetError(); code != gl.NO_ERROR; code = gl.GetError() {
+		c.logger.Log(DebugMessage{
+			Source:   SourceAPI,
+			Type:     "error",
+			ID:       code,
+			Severity: SeverityHigh,
+			Message:  fmt.Sprintf("%s after %s", errorName(code), operation),
+		})
+		count++
+		// A lost context reports errors forever
+		if count >= 32 {
+			break
+		}
+	}
+	return count
+}
+
+// PushGroup opens a named group of commands in capture tools
+func (c *Context) PushGroup(name string) {
+	if !labelsEnabled {
+		return
+	}
+	gl.PushDebugGroup(gl.DEBUG_SOURCE_APPLICATION, 0, int32(len(name)), gl.Str(name+"\x00"))
+}
+
+// PopGroup closes the group opened by the last PushGroup
+func (c *Context) PopGroup() {
+	if !labelsEnabled {
+		return
+	}
+	gl.PopDebugGroup()
+}
+
+func hasKHRDebug() bool {
+	var major, minor int32
+	gl.GetIntegerv(gl.MAJOR_VERSION, &major)
+	gl.GetIntegerv(gl.MINOR_VERSION, &minor)
+	if major > 4 || (major == 4 && minor >= 3) {
+		return true
+	}
+
+	var count int32
+	gl.GetIntegerv(gl.NUM_EXTENSIONS, &count)
+	for i := int32(0); i < count; i++ {
+		if gl.GoStr(gl.GetStringi(gl.EXTENSIONS, uint32(i))) == "GL_KHR_debug" {
+			return true
+		}
+	}
+	return false
+}
+
+func debugSource(source uint32) Source {
+	switch source {
+	case gl.DEBUG_SOURCE_API:
+		return SourceAPI
+	case gl.DEBUG_SOURCE_WINDOW_SYSTEM:
+		return SourceWindowSystem
+	case gl.DEBUG_SOURCE_SHADER_COMPILER:
+		return SourceShaderCompiler
+	case gl.DEBUG_SOURCE_THIRD_PARTY:
+		return SourceThirdParty
+	case gl.DEBUG_SOURCE_APPLICATION:
+		return SourceApplication
+	default:
+		return SourceOther
+	}
+}
+
+func debugType(gltype uint32) string {
+	switch gltype {
+	case gl.DEBUG_TYPE_ERROR:
+		return "error"
+	case gl.DEBUG_TYPE_DEPRECATED_BEHAVIOR:
+		return "deprecated"
+	case gl.DEBUG_TYPE_UNDEFINED_BEHAVIOR:
+		return "undefined"
+	case gl.DEBUG_TYPE_PORTABILITY:
+		return "portability"
+	case gl.DEBUG_TYPE_PERFORMANCE:
+		return "performance"
+	case gl.DEBUG_TYPE_MARKER:
+		return "marker"
+	case gl.DEBUG_TYPE_PUSH_GROUP:
+		return "push-group"
+	case gl.DEBUG_TYPE_POP_GROUP:
+		return "pop-group"
+	default:
+		return "other"
+	}
+}
+
+func debugSeverity(severity uint32) Severity {
+	switch severity {
+	case gl.DEBUG_SEVERITY_HIGH:
+		return SeverityHigh
+	case gl.DEBUG_SEVERITY_MEDIUM:
+		return SeverityMedium
+	case gl.DEBUG_SEVERITY_LOW:
+		return SeverityLow
+	default:
+		return SeverityNotification
+	}
+}
+
+func errorName(code uint32) string {
+	switch code {
+	case gl.INVALID_ENUM:
+		return "GL_INVALID_ENUM"
+	case gl.INVALID_VALUE:
+		return "GL_INVALID_VALUE"
+	case gl.INVALID_OPERATION:
+		return "GL_INVALID_OPERATION"
+	case gl.STACK_OVERFLOW:
+		return "GL_STACK_OVERFLOW"
+	case gl.STACK_UNDERFLOW:
+		return "GL_STACK_UNDERFLOW"
+	case gl.OUT_OF_MEMORY:
+		return "GL_OUT_OF_MEMORY"
+	case gl.INVALID_FRAMEBUFFER_OPERATION:
+		return "GL_INVALID_FRAMEBUFFER_OPERATION"
+	default:
+		return fmt.Sprintf("GL error 0x%04X", code)
+	}
+}
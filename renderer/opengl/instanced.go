@@ -0,0 +1,116 @@
+package opengl
+
+import (
+	"unsafe"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/backend"
+)
+
+const (
+	InstancedVertexShader = `
+#version 410 core
+layout (location = 0) in vec3 aPos;
+layout (location = 1) in vec3 aColor;
+layout (location = 2) in mat4 aModel;
+layout (location = 6) in vec4 aTint;
+
+uniform mat4 view;
+uniform mat4 projection;
+
+out vec4 vertexColor;
+
+void main() {
+    gl_Position = projection * view * aModel * vec4(aPos, 1.0);
+    vertexColor = vec4(aColor, 1.0) * aTint;
+}
+` + "\x00"
+
+	InstancedFragmentShader = `
+#version 410 core
+in vec4 vertexColor;
+out vec4 FragColor;
+
+void main() {
+    FragColor = vertexColor;
+}
+` + "\x00"
+)
+
+// Instance attributes: a mat4 in locations 2-5 followed by a vec4 tint
+const (
+	instanceModelLocation = 2
+	instanceColorLocation = 6
+	instanceStride        = int32(unsafe.Sizeof(backend.Instance{}))
+	instanceColorOffset   = int(unsafe.Offsetof(backend.Instance{}.Color))
+)
+
+// InstanceBuffer is a dynamic vertex buffer holding per-instance data
+type InstanceBuffer struct {
+	vbo      uint32
+	capacity int
+}
+
+func NewInstanceBuffer() *InstanceBuffer {
+	buffer := &InstanceBuffer{}
+	gl.GenBuffers(1, &buffer.vbo)
+	return buffer
+}
+
+// SetLabel names the instance buffer in capture tools
+func (b *InstanceBuffer) SetLabel(label string) {
+	// Buffer names only become objects once bound
+	gl.BindBuffer(gl.ARRAY_BUFFER, b.vbo)
+	setLabel(gl.BUFFER, b.vbo, label)
+}
+
+func (b *InstanceBuffer) Upload(instances []backend.Instance) {
+	if len(instances) == 0 {
+		return
+	}
+
+	size := len(instances) * int(instanceStride)
+	gl.BindBuffer(gl.ARRAY_BUFFER, b.vbo)
+	if size > b.capacity {
+		gl.BufferData(gl.ARRAY_BUFFER, size, gl.Ptr(&instances[0]), gl.DYNAMIC_DRAW)
+		b.capacity = size
+	} else {
+		gl.BufferSubData(gl.ARRAY_BUFFER, 0, size, gl.Ptr(&instances[0]))
+	}
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+}
+
+func (b *InstanceBuffer) Delete() {
+	gl.DeleteBuffers(1, &b.vbo)
+}
+
+// DrawInstanced draws count instances of the mesh using the per-instance
+// attributes in buffer
+func (m *Mesh) DrawInstanced(buffer *InstanceBuffer, count int32) {
+	gl.BindVertexArray(m.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, buffer.vbo)
+
+	// A mat4 attribute occupies four consecutive vec4 locations, one per column
+	for column := uint32(0); column < 4; column++ {
+		location := instanceModelLocation + column
+		gl.VertexAttribPointer(location, 4, gl.FLOAT, false, instanceStride, gl.PtrOffset(int(column)*4*4))
+		gl.VertexAttribDivisor(location, 1)
+		gl.EnableVertexAttribArray(location)
+	}
+	gl.VertexAttribPointer(instanceColorLocation, 4, gl.FLOAT, false, instanceStride, gl.PtrOffset(instanceColorOffset))
+	gl.VertexAttribDivisor(instanceColorLocation, 1)
+	gl.EnableVertexAttribArray(instanceColorLocation)
+
+	if m.indexed {
+		gl.DrawElementsInstanced(gl.TRIANGLES, m.indexCount, gl.UNSIGNED_INT, gl.PtrOffset(0), count)
+	} else {
+		gl.DrawArraysInstanced(gl.TRIANGLES, 0, m.vertexCount, count)
+	}
+
+	// Leave the VAO usable by non-instanced draws
+	for location := uint32(instanceModelLocation); location <= instanceColorLocation; location++ {
+		gl.DisableVertexAttribArray(location)
+	}
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+	gl.BindVertexArray(0)
+}
@@ -0,0 +1,67 @@
+package opengl
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// LineBuffer is a persistent dynamic vertex buffer for line segments,
+// reused every frame instead of creating a mesh per draw
+type LineBuffer struct {
+	vao      uint32
+	vbo      uint32
+	capacity int
+}
+
+func NewLineBuffer() *LineBuffer {
+	buffer := &LineBuffer{}
+
+	gl.GenVertexArrays(1, &buffer.vao)
+	gl.BindVertexArray(buffer.vao)
+
+	gl.GenBuffers(1, &buffer.vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, buffer.vbo)
+
+	// Position attribute
+	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 6*4, gl.PtrOffset(0))
+	gl.EnableVertexAttribArray(0)
+
+	// Color attribute
+	gl.VertexAttribPointer(1, 3, gl.FLOAT, false, 6*4, gl.PtrOffset(3*4))
+	gl.EnableVertexAttribArray(1)
+
+	gl.BindVertexArray(0)
+
+	return buffer
+}
+
+// SetLabel names the line buffer in capture tools
+func (b *LineBuffer) SetLabel(label string) {
+	setLabel(gl.VERTEX_ARRAY, b.vao, label)
+	setLabel(gl.BUFFER, b.vbo, label)
+}
+
+// Draw uploads the vertices and draws them as GL_LINES
+func (b *LineBuffer) Draw(vertices []float32) {
+	if len(vertices) < 12 {
+		return
+	}
+
+	size := len(vertices) * 4
+	gl.BindBuffer(gl.ARRAY_BUFFER, b.vbo)
+	if size > b.capacity {
+		gl.BufferData(gl.ARRAY_BUFFER, size, gl.Ptr(vertices), gl.DYNAMIC_DRAW)
+		b.capacity = size
+	} else {
+		gl.BufferSubData(gl.ARRAY_BUFFER, 0, size, gl.Ptr(vertices))
+	}
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+
+	gl.BindVertexArray(b.vao)
+	gl.DrawArrays(gl.LINES, 0, int32(len(vertices)/6))
+	gl.BindVertexArray(0)
+}
+
+func (b *LineBuffer) Delete() {
+	gl.DeleteVertexArrays(1, &b.vao)
+	gl.DeleteBuffers(1, &b.vbo)
+}
@@ -2,6 +2,7 @@ package opengl
 
 import (
 	"github.com/go-gl/gl/v4.1-core/gl"
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
 )
 
 type Mesh struct {
@@ -11,12 +12,21 @@ type Mesh struct {
 	vertexCount int32
 	indexCount  int32
 	indexed     bool
+	bounds      bmath.AABB
+}
+
+// SetLabel names the mesh's vertex array and buffers in capture tools
+func (m *Mesh) SetLabel(label string) {
+	setLabel(gl.VERTEX_ARRAY, m.vao, label)
+	setLabel(gl.BUFFER, m.vbo, label+" vertices")
+	setLabel(gl.BUFFER, m.ebo, label+" indices")
 }
 
 func NewMesh(vertices []float32) *Mesh {
 	mesh := &Mesh{
 		vertexCount: int32(len(vertices) / 6), // 3 for position, 3 for color
 		indexed:     false,
+		bounds:      bmath.NewAABBFromVertices(vertices, 6),
 	}
 
 	// Generate and bind VAO
@@ -47,6 +57,7 @@ func NewIndexedMesh(vertices []float32, indices []uint32) *Mesh {
 		vertexCount: int32(len(vertices) / 6),
 		indexCount:  int32(len(indices)),
 		indexed:     true,
+		bounds:      bmath.NewAABBFromVertices(vertices, 6),
 	}
 
 	// Generate and bind VAO
@@ -87,6 +98,10 @@ func (m *Mesh) Draw() {
 	gl.BindVertexArray(0)
 }
 
+func (m *Mesh) GetBounds() bmath.AABB {
+	return m.bounds
+}
+
 func (m *Mesh) Delete() {
 	gl.DeleteVertexArrays(1, &m.vao)
 	gl.DeleteBuffers(1, &m.vbo)
@@ -99,6 +114,7 @@ func NewMeshLines(vertices []float32) *Mesh {
 	mesh := &Mesh{
 		vertexCount: int32(len(vertices) / 6), // 3 for position, 3 for color
 		indexed:     false,
+		bounds:      bmath.NewAABBFromVertices(vertices, 6),
 	}
 
 	// Generate and bind VAO
@@ -50,6 +50,11 @@ void main() {
 ` + "\x00"
 )
 
+// SetLabel names the shader program in capture tools
+func (s *Shader) SetLabel(label string) {
+	setLabel(gl.PROGRAM, s.program, label)
+}
+
 func NewShader(vertexSource, fragmentSource string) (*Shader, error) {
 	vertexShader, err := CompileShader(vertexSource, gl.VERTEX_SHADER)
 	if err != nil {
@@ -85,4 +90,18 @@ func (s *Shader) Delete() {
 
 func (s *Shader) GetProgramID() uint32 {
 	return s.program
-}
\ No newline at end of file
+}
+func (s *Shader) SetInt(name string, value int32) {
+	location := gl.GetUniformLocation(s.program, gl.Str(name + "\x00"))
+	gl.Uniform1i(location, value)
+}
+
+func (s *Shader) SetFloat(name string, value float32) {
+	location := gl.GetUniformLocation(s.program, gl.Str(name + "\x00"))
+	gl.Uniform1f(location, value)
+}
+
+func (s *Shader) SetVector4(name string, value [4]float32) {
+	location := gl.GetUniformLocation(s.program, gl.Str(name + "\x00"))
+	gl.Uniform4f(location, value[0], value[1], value[2], value[3])
+}
@@ -0,0 +1,171 @@
+package opengl
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/backend"
+)
+
+const (
+	SkyboxVertexShader = `
+#version 410 core
+layout (location = 0) in vec3 aPos;
+
+uniform mat4 view;
+uniform mat4 projection;
+
+out vec3 direction;
+
+void main() {
+    direction = aPos;
+    // Drop the camera translation so the sky stays at infinity
+    vec4 pos = projection * mat4(mat3(view)) * vec4(aPos, 1.0);
+    // z = w puts every fragment on the far plane
+    gl_Position = pos.xyww;
+}
+` + "\x00"
+
+	SkyboxFragmentShader = `
+#version 410 core
+in vec3 direction;
+out vec4 FragColor;
+
+uniform samplerCube skybox;
+
+void main() {
+    FragColor = texture(skybox, direction);
+}
+` + "\x00"
+)
+
+type Cubemap struct {
+	texture uint32
+}
+
+// SetLabel names the cubemap texture in capture tools
+func (c *Cubemap) SetLabel(label string) {
+	setLabel(gl.TEXTURE, c.texture, label)
+}
+
+func NewCubemap(faces backend.CubemapFaces) *Cubemap {
+	cubemap := &Cubemap{}
+	gl.GenTextures(1, &cubemap.texture)
+	gl.BindTexture(gl.TEXTURE_CUBE_MAP, cubemap.texture)
+
+	for i, face := range faces {
+		if face == nil {
+			continue
+		}
+		bounds := face.Bounds()
+		gl.PixelStorei(gl.UNPACK_ROW_LENGTH, int32(face.Stride/4))
+		gl.TexImage2D(gl.TEXTURE_CUBE_MAP_POSITIVE_X+uint32(i), 0, gl.RGBA8,
+			int32(bounds.Dx()), int32(bounds.Dy()), 0, gl.RGBA, gl.UNSIGNED_BYTE,
+			gl.Ptr(face.Pix[face.PixOffset(bounds.Min.X, bounds.Min.Y):]))
+	}
+	gl.PixelStorei(gl.UNPACK_ROW_LENGTH, 0)
+
+	gl.TexParameteri(gl.TEXTURE_CUBE_MAP, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_CUBE_MAP, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_CUBE_MAP, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_CUBE_MAP, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_CUBE_MAP, gl.TEXTURE_WRAP_R, gl.CLAMP_TO_EDGE)
+	gl.BindTexture(gl.TEXTURE_CUBE_MAP, 0)
+
+	return cubemap
+}
+
+func (c *Cubemap) Bind(unit uint32) {
+	gl.ActiveTexture(gl.TEXTURE0 + unit)
+	gl.BindTexture(gl.TEXTURE_CUBE_MAP, c.texture)
+}
+
+func (c *Cubemap) Delete() {
+	gl.DeleteTextures(1, &c.texture)
+}
+
+// Skybox draws a cubemap around the camera on the far plane
+type Skybox struct {
+	shader *Shader
+	vao    uint32
+	vbo    uint32
+	ebo    uint32
+}
+
+func NewSkybox() (*Skybox, error) {
+	shader, err := NewShader(SkyboxVertexShader, SkyboxFragmentShader)
+	if err != nil {
+		return nil, err
+	}
+
+	vertices := []float32{
+		-1, -1, -1,
+		1, -1, -1,
+		1, 1, -1,
+		-1, 1, -1,
+		-1, -1, 1,
+		1, -1, 1,
+		1, 1, 1,
+		-1, 1, 1,
+	}
+	indices := []uint32{
+		0, 1, 2, 2, 3, 0, // back
+		4, 6, 5, 6, 4, 7, // front
+		0, 3, 7, 7, 4, 0, // left
+		1, 5, 6, 6, 2, 1, // right
+		3, 2, 6, 6, 7, 3, // top
+		0, 4, 5, 5, 1, 0, // bottom
+	}
+
+	skybox := &Skybox{shader: shader}
+	gl.GenVertexArrays(1, &skybox.vao)
+	gl.BindVertexArray(skybox.vao)
+
+	gl.GenBuffers(1, &skybox.vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, skybox.vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
+
+	gl.GenBuffers(1, &skybox.ebo)
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, skybox.ebo)
+	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
+
+	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 3*4, gl.PtrOffset(0))
+	gl.EnableVertexAttribArray(0)
+
+	gl.BindVertexArray(0)
+
+	return skybox, nil
+}
+
+// Draw renders the cubemap behind everything already drawn. Depth testing
+// uses LEQUAL so the sky passes where the depth buffer is still cleared.
+// SetLabel names the skybox shader and cube geometry in capture tools
+func (s *Skybox) SetLabel(label string) {
+	s.shader.SetLabel(label)
+	setLabel(gl.VERTEX_ARRAY, s.vao, label)
+	setLabel(gl.BUFFER, s.vbo, label+" vertices")
+	setLabel(gl.BUFFER, s.ebo, label+" indices")
+}
+
+func (s *Skybox) Draw(ctx *Context, cubemap *Cubemap, view, projection *float32) {
+	ctx.SetDepthLessEqual(true)
+	ctx.SetDepthWrite(false)
+
+	s.shader.Use()
+	s.shader.SetMatrix4("view", view)
+	s.shader.SetMatrix4("projection", projection)
+	s.shader.SetInt("skybox", 0)
+	cubemap.Bind(0)
+
+	gl.BindVertexArray(s.vao)
+	gl.DrawElements(gl.TRIANGLES, 36, gl.UNSIGNED_INT, gl.PtrOffset(0))
+	gl.BindVertexArray(0)
+
+	ctx.SetDepthWrite(true)
+	ctx.SetDepthLessEqual(false)
+}
+
+func (s *Skybox) Delete() {
+	gl.DeleteVertexArrays(1, &s.vao)
+	gl.DeleteBuffers(1, &s.vbo)
+	gl.DeleteBuffers(1, &s.ebo)
+	s.shader.Delete()
+}
@@ -1,50 +1,21 @@
 package opengl
 
 import (
-	"math"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/meshgen"
 )
 
+// NewSphereMesh creates the built-in sphere of diameter 1
 func NewSphereMesh() *Mesh {
-	const segments = 16
-	const rings = 16
-	
-	vertices := make([]float32, 0)
-	indices := make([]uint32, 0)
-	
-	// Generate sphere vertices
-	for ring := 0; ring <= rings; ring++ {
-		phi := float64(ring) * math.Pi / float64(rings)
-		
-		for segment := 0; segment <= segments; segment++ {
-			theta := float64(segment) * 2.0 * math.Pi / float64(segments)
-			
-			x := math.Sin(phi) * math.Cos(theta)
-			y := math.Cos(phi)
-			z := math.Sin(phi) * math.Sin(theta)
-			
-			// Position
-			vertices = append(vertices, float32(x*0.5), float32(y*0.5), float32(z*0.5))
-			
-			// Color based on position for visual distinction
-			r := float32(0.5 + 0.5*x)
-			g := float32(0.5 + 0.5*y)
-			b := float32(0.5 + 0.5*z)
-			vertices = append(vertices, r, g, b)
-		}
-	}
-	
-	// Generate sphere indices
-	for ring := 0; ring < rings; ring++ {
-		for segment := 0; segment < segments; segment++ {
-			current := uint32(ring*(segments+1) + segment)
-			next := current + uint32(segments+1)
-			
-			// First triangle
-			indices = append(indices, current, next, current+1)
-			// Second triangle
-			indices = append(indices, current+1, next, next+1)
-		}
-	}
-	
-	return NewIndexedMesh(vertices, indices)
-}
\ No newline at end of file
+	return NewSphereMeshWithSegments(16, 16)
+}
+
+// NewSphereMeshWithSegments creates a sphere of diameter 1 with the given
+// segments around the Y axis and rings from pole to pole
+func NewSphereMeshWithSegments(segments, rings int) *Mesh {
+	return NewGeneratedMesh(meshgen.Sphere(0.5, segments, rings))
+}
+
+// NewGeneratedMesh uploads a generated mesh, colored by its normals
+func NewGeneratedMesh(m *meshgen.Mesh) *Mesh {
+	return NewIndexedMesh(m.PositionColor(), m.Indices)
+}
@@ -0,0 +1,236 @@
+package opengl
+
+import (
+	"fmt"
+	"image"
+	"unsafe"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/backend"
+)
+
+const (
+	SpriteVertexShader = `
+#version 410 core
+layout (location = 0) in vec3 aPos;
+layout (location = 1) in vec2 aUV;
+layout (location = 2) in vec4 aColor;
+
+uniform mat4 view;
+uniform mat4 projection;
+
+out vec2 uv;
+out vec4 tint;
+
+void main() {
+    gl_Position = projection * view * vec4(aPos, 1.0);
+    uv = aUV;
+    tint = aColor;
+}
+` + "\x00"
+
+	SpriteFragmentShader = `
+#version 410 core
+in vec2 uv;
+in vec4 tint;
+out vec4 FragColor;
+
+uniform sampler2D sprite;
+uniform bool distanceField;
+
+void main() {
+    if (distanceField) {
+        // Alpha is 0.5 on the edge; smooth over about a screen pixel
+        float distance = texture(sprite, uv).a;
+        float width = fwidth(distance);
+        float alpha = smoothstep(0.5 - width, 0.5 + width, distance);
+        FragColor = vec4(tint.rgb, tint.a * alpha);
+    } else {
+        FragColor = texture(sprite, uv) * tint;
+    }
+}
+` + "\x00"
+)
+
+const (
+	spriteVertexStride = int32(unsafe.Sizeof(backend.SpriteVertex{}))
+	spriteUVOffset     = int(unsafe.Offsetof(backend.SpriteVertex{}.U))
+	spriteColorOffset  = int(unsafe.Offsetof(backend.SpriteVertex{}.Color))
+)
+
+// Texture is a 2D RGBA texture
+type Texture struct {
+	texture       uint32
+	width         int
+	height        int
+	distanceField bool
+}
+
+func NewTexture(img *image.RGBA, smooth bool) *Texture {
+	bounds := img.Bounds()
+	texture := &Texture{width: bounds.Dx(), height: bounds.Dy()}
+
+	gl.GenTextures(1, &texture.texture)
+	gl.BindTexture(gl.TEXTURE_2D, texture.texture)
+
+	// The first image row becomes t = 0, matching the top-left origin of
+	// sprite texture coordinates
+	gl.PixelStorei(gl.UNPACK_ROW_LENGTH, int32(img.Stride/4))
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA8, int32(texture.width), int32(texture.height), 0,
+		gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(img.Pix[img.PixOffset(bounds.Min.X, bounds.Min.Y):]))
+	gl.PixelStorei(gl.UNPACK_ROW_LENGTH, 0)
+
+	filter := int32(gl.NEAREST)
+	if smooth {
+		filter = gl.LINEAR
+	}
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, filter)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, filter)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+
+	return texture
+}
+
+// NewDistanceFieldTexture uploads a signed distance field, which sprites
+// draw as a sharp mask
+func NewDistanceFieldTexture(img *image.RGBA) *Texture {
+	texture := NewTexture(img, true)
+	texture.distanceField = true
+	return texture
+}
+
+// Update replaces the texture's pixels with an image of the same size
+func (t *Texture) Update(img *image.RGBA) {
+	bounds := img.Bounds()
+	if bounds.Dx() != t.width || bounds.Dy() != t.height {
+		return
+	}
+	gl.BindTexture(gl.TEXTURE_2D, t.texture)
+	gl.PixelStorei(gl.UNPACK_ROW_LENGTH, int32(img.Stride/4))
+	gl.TexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, int32(t.width), int32(t.height),
+		gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(img.Pix[img.PixOffset(bounds.Min.X, bounds.Min.Y):]))
+	gl.PixelStorei(gl.UNPACK_ROW_LENGTH, 0)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+}
+
+// SetLabel names the texture in capture tools
+func (t *Texture) SetLabel(label string) {
+	setLabel(gl.TEXTURE, t.texture, label)
+}
+
+func (t *Texture) Size() (int, int) {
+	return t.width, t.height
+}
+
+func (t *Texture) Bind(unit uint32) {
+	gl.ActiveTexture(gl.TEXTURE0 + unit)
+	gl.BindTexture(gl.TEXTURE_2D, t.texture)
+}
+
+func (t *Texture) Delete() {
+	gl.DeleteTextures(1, &t.texture)
+}
+
+// SpriteRenderer draws batches of textured quads from a dynamic vertex
+// buffer, with a shared index buffer describing two triangles per quad
+type SpriteRenderer struct {
+	shader   *Shader
+	vao      uint32
+	vbo      uint32
+	ebo      uint32
+	capacity int // in quads
+}
+
+func NewSpriteRenderer() (*SpriteRenderer, error) {
+	shader, err := NewShader(SpriteVertexShader, SpriteFragmentShader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sprite shader: %w", err)
+	}
+
+	s := &SpriteRenderer{shader: shader}
+
+	gl.GenVertexArrays(1, &s.vao)
+	gl.BindVertexArray(s.vao)
+
+	gl.GenBuffers(1, &s.vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, s.vbo)
+
+	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, spriteVertexStride, gl.PtrOffset(0))
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointer(1, 2, gl.FLOAT, false, spriteVertexStride, gl.PtrOffset(spriteUVOffset))
+	gl.EnableVertexAttribArray(1)
+	gl.VertexAttribPointer(2, 4, gl.FLOAT, false, spriteVertexStride, gl.PtrOffset(spriteColorOffset))
+	gl.EnableVertexAttribArray(2)
+
+	gl.GenBuffers(1, &s.ebo)
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, s.ebo)
+
+	gl.BindVertexArray(0)
+
+	return s, nil
+}
+
+// SetLabel names the sprite shader and buffers in capture tools
+func (s *SpriteRenderer) SetLabel(label string) {
+	s.shader.SetLabel(label)
+	setLabel(gl.VERTEX_ARRAY, s.vao, label)
+	setLabel(gl.BUFFER, s.vbo, label+" vertices")
+	setLabel(gl.BUFFER, s.ebo, label+" indices")
+}
+
+// Draw uploads the quads and draws them with the texture in one call
+func (s *SpriteRenderer) Draw(ctx *Context, texture *Texture, vertices []backend.SpriteVertex, view, projection *float32) {
+	quads := len(vertices) / 4
+	if quads == 0 {
+		return
+	}
+
+	gl.BindVertexArray(s.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, s.vbo)
+	size := quads * 4 * int(spriteVertexStride)
+	if quads > s.capacity {
+		gl.BufferData(gl.ARRAY_BUFFER, size, gl.Ptr(&vertices[0]), gl.DYNAMIC_DRAW)
+		s.growIndices(quads)
+	} else {
+		gl.BufferSubData(gl.ARRAY_BUFFER, 0, size, gl.Ptr(&vertices[0]))
+	}
+
+	s.shader.Use()
+	s.shader.SetMatrix4("view", view)
+	s.shader.SetMatrix4("projection", projection)
+	s.shader.SetInt("sprite", 0)
+	distanceField := int32(0)
+	if texture.distanceField {
+		distanceField = 1
+	}
+	s.shader.SetInt("distanceField", distanceField)
+	texture.Bind(0)
+
+	ctx.SetDepthTest(false)
+	gl.DrawElements(gl.TRIANGLES, int32(quads*6), gl.UNSIGNED_INT, gl.PtrOffset(0))
+	ctx.SetDepthTest(true)
+
+	gl.BindVertexArray(0)
+}
+
+// growIndices rebuilds the index buffer for quads quads; the vertex array
+// must be bound
+func (s *SpriteRenderer) growIndices(quads int) {
+	indices := make([]uint32, 0, quads*6)
+	for i := 0; i < quads; i++ {
+		base := uint32(i * 4)
+		indices = append(indices, base, base+1, base+2, base+2, base+3, base)
+	}
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, s.ebo)
+	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
+	s.capacity = quads
+}
+
+func (s *SpriteRenderer) Delete() {
+	gl.DeleteVertexArrays(1, &s.vao)
+	gl.DeleteBuffers(1, &s.vbo)
+	gl.DeleteBuffers(1, &s.ebo)
+	s.shader.Delete()
+}
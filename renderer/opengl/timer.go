@@ -0,0 +1,91 @@
+package opengl
+
+import (
+	"time"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// timerLatency is how many frames a timer query gets to finish before its
+// result is read, so reading never stalls on the GPU
+const timerLatency = 3
+
+type timerQuery struct {
+	name string
+	id   uint32
+}
+
+// GPUTimers measures GPU time of named passes with GL_TIME_ELAPSED
+// queries. Passes cannot nest; a Begin while a pass is open is ignored.
+type GPUTimers struct {
+	frames  [timerLatency][]timerQuery
+	current int
+	open    bool
+	free    []uint32
+}
+
+func NewGPUTimers() *GPUTimers {
+	return &GPUTimers{}
+}
+
+// Begin starts timing the GPU commands of a pass
+func (t *GPUTimers) Begin(name string) {
+	if t.open {
+		return
+	}
+
+	var id uint32
+	if n := len(t.free); n > 0 {
+		id = t.free[n-1]
+		t.free = t.free[:n-1]
+	} else {
+		gl.GenQueries(1, &id)
+	}
+
+	gl.BeginQuery(gl.TIME_ELAPSED, id)
+	t.frames[t.current] = append(t.frames[t.current], timerQuery{name: name, id: id})
+	t.open = true
+}
+
+// End stops timing the pass started by Begin
+func (t *GPUTimers) End() {
+	if !t.open {
+		return
+	}
+	gl.EndQuery(gl.TIME_ELAPSED)
+	t.open = false
+}
+
+// Resolve finishes the frame's queries and passes the results of those
+// issued timerLatency-1 frames ago to record. Results that are still not
+// available are dropped rather than waited for.
+func (t *GPUTimers) Resolve(record func(name string, elapsed time.Duration)) {
+	t.End()
+	t.current = (t.current + 1) % timerLatency
+
+	for _, query := range t.frames[t.current] {
+		var available uint32
+		gl.GetQueryObjectuiv(query.id, gl.QUERY_RESULT_AVAILABLE, &available)
+		if available != 0 {
+			var elapsed uint64
+			gl.GetQueryObjectui64v(query.id, gl.QUERY_RESULT, &elapsed)
+			record(query.name, time.Duration(elapsed))
+		}
+		t.free = append(t.free, query.id)
+	}
+	t.frames[t.current] = t.frames[t.current][:0]
+}
+
+func (t *GPUTimers) Delete() {
+	t.End()
+	for i := range t.frames {
+		for _, query := range t.frames[i] {
+			t.free = append(t.free, query.id)
+		}
+		t.frames[i] = nil
+	}
+	if len(t.free) > 0 {
+		gl.DeleteQueries(int32(len(t.free)), &t.free[0])
+	}
+	t.free = nil
+}
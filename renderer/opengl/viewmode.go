@@ -0,0 +1,121 @@
+package opengl
+
+import (
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/backend"
+)
+
+// The view mode shader shares the instanced vertex layout; viewMode holds a
+// backend.ViewMode value
+const (
+	ViewModeVertexShader = `
+#version 410 core
+layout (location = 0) in vec3 aPos;
+layout (location = 1) in vec3 aColor;
+layout (location = 2) in mat4 aModel;
+layout (location = 6) in vec4 aTint;
+
+uniform mat4 view;
+uniform mat4 projection;
+
+out vec3 objectPos;
+out vec3 worldPos;
+out float viewDepth;
+
+void main() {
+    vec4 world = aModel * vec4(aPos, 1.0);
+    vec4 eye = view * world;
+    gl_Position = projection * eye;
+    objectPos = aPos;
+    worldPos = world.xyz;
+    viewDepth = -eye.z;
+}
+` + "\x00"
+
+	ViewModeFragmentShader = `
+#version 410 core
+in vec3 objectPos;
+in vec3 worldPos;
+in float viewDepth;
+out vec4 FragColor;
+
+uniform int viewMode;
+uniform float near;
+uniform float far;
+uniform vec4 solidColor;
+
+const int NORMALS = 3;
+const int UV_CHECKER = 4;
+const int DEPTH = 5;
+
+void main() {
+    if (viewMode == NORMALS) {
+        vec3 normal = normalize(cross(dFdx(worldPos), dFdy(worldPos)));
+        FragColor = vec4(normal * 0.5 + 0.5, 1.0);
+    } else if (viewMode == UV_CHECKER) {
+        // Project along the dominant axis of the object-space face normal
+        vec3 n = abs(cross(dFdx(objectPos), dFdy(objectPos)));
+        vec2 uv = objectPos.xy;
+        if (n.x > n.y && n.x > n.z) {
+            uv = objectPos.zy;
+        } else if (n.y > n.z) {
+            uv = objectPos.xz;
+        }
+        float cell = mod(floor(uv.x * 4.0) + floor(uv.y * 4.0), 2.0);
+        FragColor = vec4(mix(vec3(0.2), vec3(0.9), cell), 1.0);
+    } else if (viewMode == DEPTH) {
+        float depth = clamp((viewDepth - near) / (far - near), 0.0, 1.0);
+        FragColor = vec4(vec3(1.0 - depth), 1.0);
+    } else {
+        FragColor = solidColor;
+    }
+}
+` + "\x00"
+)
+
+// drawViewMode draws the uploaded instances according to the current view
+// mode, restoring the default pipeline state afterwards
+func (b *Backend) drawViewMode(mesh *Mesh, count int32) {
+	switch b.viewMode {
+	case backend.ViewShaded:
+		b.drawShaded(mesh, count)
+	case backend.ViewWireframe:
+		b.context.SetWireframe(true)
+		b.drawShaded(mesh, count)
+		b.context.SetWireframe(false)
+	case backend.ViewWireframeShaded:
+		b.drawShaded(mesh, count)
+		b.context.SetWireframe(true)
+		b.drawDebug(mesh, count, backend.WireframeColor)
+		b.context.SetWireframe(false)
+	case backend.ViewOverdraw:
+		b.context.SetDepthTest(false)
+		b.context.SetAdditiveBlend(true)
+		b.drawDebug(mesh, count, backend.OverdrawColor)
+		b.context.SetAdditiveBlend(false)
+		b.context.SetDepthTest(true)
+	default:
+		b.drawDebug(mesh, count, [4]float32{1, 1, 1, 1})
+	}
+}
+
+func (b *Backend) drawShaded(mesh *Mesh, count int32) {
+	b.instanced.Use()
+	b.instanced.SetMatrix4("view", &b.view[0])
+	b.instanced.SetMatrix4("projection", &b.projection[0])
+
+	mesh.DrawInstanced(b.instances, count)
+}
+
+func (b *Backend) drawDebug(mesh *Mesh, count int32, color [4]float32) {
+	near, far := backend.NearFar(b.projection)
+
+	b.viewShader.Use()
+	b.viewShader.SetMatrix4("view", &b.view[0])
+	b.viewShader.SetMatrix4("projection", &b.projection[0])
+	b.viewShader.SetInt("viewMode", int32(b.viewMode))
+	b.viewShader.SetFloat("near", near)
+	b.viewShader.SetFloat("far", far)
+	b.viewShader.SetVector4("solidColor", color)
+
+	mesh.DrawInstanced(b.instances, count)
+}
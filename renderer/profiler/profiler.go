@@ -0,0 +1,250 @@
+// Package profiler measures where frame time goes. CPU time is measured
+// with nested scopes around engine phases; GPU time arrives from timer
+// queries a few frames late and is recorded by name. Every series keeps
+// a rolling window of per-frame totals for averages.
+package profiler
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultWindow is the number of frames averaged by default
+const DefaultWindow = 60
+
+// Kind tells CPU and GPU measurements apart
+type Kind int
+
+const (
+	CPU Kind = iota
+	GPU
+)
+
+func (k Kind) String() string {
+	if k == GPU {
+		return "GPU"
+	}
+	return "CPU"
+}
+
+// FrameScope is the name of the CPU series timing whole frames
+const FrameScope = "frame"
+
+// Stat summarizes one series
+type Stat struct {
+	Kind Kind
+	// Path is the scope name prefixed with its enclosing scopes, e.g.
+	// "render/scene"
+	Path    string
+	Name    string
+	Depth   int
+	Last    time.Duration
+	Average time.Duration
+	Max     time.Duration
+}
+
+// Milliseconds returns the average in milliseconds, for display
+func (s Stat) Milliseconds() float64 {
+	return float64(s.Average) / float64(time.Millisecond)
+}
+
+type key struct {
+	kind Kind
+	path string
+}
+
+type series struct {
+	stat    Stat
+	samples []time.Duration
+	next    int
+	filled  int
+	current time.Duration
+	touched bool
+}
+
+func (s *series) push(d time.Duration) {
+	s.samples[s.next] = d
+	s.next = (s.next + 1) % len(s.samples)
+	if s.filled < len(s.samples) {
+		s.filled++
+	}
+
+	var total, max time.Duration
+	for i := 0; i < s.filled; i++ {
+		total += s.samples[i]
+		if s.samples[i] > max {
+			max = s.samples[i]
+		}
+	}
+	s.stat.Last = d
+	s.stat.Average = total / time.Duration(s.filled)
+	s.stat.Max = max
+}
+
+// Profiler aggregates CPU scopes and GPU timings per frame
+type Profiler struct {
+	mu         sync.Mutex
+	window     int
+	series     map[key]*series
+	order      []key
+	stack      []string
+	frameStart time.Time
+	enabled    bool
+}
+
+// New creates a profiler averaging over window frames
+func New(window int) *Profiler {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	p := &Profiler{
+		window:     window,
+		series:     make(map[key]*series),
+		frameStart: time.Now(),
+		enabled:    true,
+	}
+	p.get(CPU, FrameScope, FrameScope, 0)
+	return p
+}
+
+// SetEnabled turns measurement on or off; scopes are free when off
+func (p *Profiler) SetEnabled(enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.enabled = enabled
+	p.stack = p.stack[:0]
+	p.frameStart = time.Now()
+}
+
+// IsEnabled reports whether the profiler is measuring
+func (p *Profiler) IsEnabled() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.enabled
+}
+
+// Begin starts a CPU scope nested in any open scope and returns the
+// function ending it:
+//
+//	defer prof.Begin("update")()
+func (p *Profiler) Begin(name string) func() {
+	p.mu.Lock()
+	if !p.enabled {
+		p.mu.Unlock()
+		return func() {}
+	}
+	p.stack = append(p.stack, name)
+	path := strings.Join(p.stack, "/")
+	depth := len(p.stack) - 1
+	// Created up front so enclosing scopes are listed before nested ones
+	p.get(CPU, path, name, depth)
+	p.mu.Unlock()
+
+	start := time.Now()
+	return func() {
+		elapsed := time.Since(start)
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if len(p.stack) > depth {
+			p.stack = p.stack[:depth]
+		}
+		p.add(CPU, path, name, depth, elapsed)
+	}
+}
+
+// Record adds a measurement taken elsewhere, such as a resolved GPU timer
+func (p *Profiler) Record(kind Kind, name string, d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.enabled {
+		return
+	}
+	p.add(kind, name, name, 0, d)
+}
+
+func (p *Profiler) add(kind Kind, path, name string, depth int, d time.Duration) {
+	s := p.get(kind, path, name, depth)
+	// Scopes entered several times in a frame are summed
+	s.current += d
+	s.touched = true
+}
+
+func (p *Profiler) get(kind Kind, path, name string, depth int) *series {
+	k := key{kind: kind, path: path}
+	s, exists := p.series[k]
+	if !exists {
+		s = &series{
+			stat:    Stat{Kind: kind, Path: path, Name: name, Depth: depth},
+			samples: make([]time.Duration, p.window),
+		}
+		p.series[k] = s
+		p.order = append(p.order, k)
+	}
+	return s
+}
+
+// EndFrame closes the frame: the time since the previous EndFrame is
+// recorded as FrameScope and every series gets its total for the frame
+// added to the rolling window. Series measured in earlier frames but not
+// this one get a zero, so a scope that stops running fades out of the
+// averages instead of repeating its last frames.
+func (p *Profiler) EndFrame() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.enabled {
+		return
+	}
+
+	now := time.Now()
+	p.add(CPU, FrameScope, FrameScope, 0, now.Sub(p.frameStart))
+	p.frameStart = now
+
+	for _, s := range p.series {
+		// Scopes begun but never ended stay out of Stats
+		if !s.touched && s.filled == 0 {
+			continue
+		}
+		s.push(s.current)
+		s.current = 0
+		s.touched = false
+	}
+}
+
+// Stats returns every series in the order first measured
+func (p *Profiler) Stats() []Stat {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make([]Stat, 0, len(p.order))
+	for _, k := range p.order {
+		if s := p.series[k]; s.filled > 0 {
+			stats = append(stats, s.stat)
+		}
+	}
+	return stats
+}
+
+// Get returns the series for a scope path or GPU timer name
+func (p *Profiler) Get(kind Kind, path string) (Stat, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s, exists := p.series[key{kind: kind, path: path}]
+	if !exists || s.filled == 0 {
+		return Stat{}, false
+	}
+	return s.stat, true
+}
+
+// Reset forgets all measurements
+func (p *Profiler) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.series = make(map[key]*series)
+	p.order = nil
+	p.stack = p.stack[:0]
+	p.frameStart = time.Now()
+	p.get(CPU, FrameScope, FrameScope, 0)
+}
@@ -0,0 +1,179 @@
+package profiler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const ms = time.Millisecond
+
+func TestRollingWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		frames  []time.Duration
+		last    time.Duration
+		average time.Duration
+		max     time.Duration
+	}{
+		{"one frame", []time.Duration{4 * ms}, 4 * ms, 4 * ms, 4 * ms},
+		{"filling", []time.Duration{1 * ms, 2 * ms}, 2 * ms, 1500 * time.Microsecond, 2 * ms},
+		{"full", []time.Duration{1 * ms, 2 * ms, 6 * ms}, 6 * ms, 3 * ms, 6 * ms},
+		// The oldest frames fall out of the window, maximum and all
+		{"wrapped", []time.Duration{9 * ms, 2 * ms, 3 * ms, 4 * ms}, 4 * ms, 3 * ms, 4 * ms},
+		{"wrapped twice", []time.Duration{9 * ms, 9 * ms, 9 * ms, 1 * ms, 1 * ms, 4 * ms, 1 * ms}, 1 * ms, 2 * ms, 4 * ms},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(3)
+			for _, d := range tt.frames {
+				p.Record(GPU, "scene", d)
+				p.EndFrame()
+			}
+			stat, ok := p.Get(GPU, "scene")
+			if !ok {
+				t.Fatal("series missing")
+			}
+			if stat.Last != tt.last || stat.Average != tt.average || stat.Max != tt.max {
+				t.Errorf("last %v, average %v, max %v; want %v, %v, %v",
+					stat.Last, stat.Average, stat.Max, tt.last, tt.average, tt.max)
+			}
+		})
+	}
+}
+
+func TestNestedScopes(t *testing.T) {
+	p := New(10)
+	endRender := p.Begin("render")
+	endScene := p.Begin("scene")
+	p.Begin("shadows")()
+	endScene()
+	p.Begin("ui")()
+	endRender()
+	p.Begin("update")()
+	p.EndFrame()
+
+	var got []Stat
+	for _, stat := range p.Stats() {
+		got = append(got, Stat{Kind: stat.Kind, Path: stat.Path, Name: stat.Name, Depth: stat.Depth})
+	}
+	want := []Stat{
+		{Kind: CPU, Path: FrameScope, Name: FrameScope},
+		{Kind: CPU, Path: "render", Name: "render"},
+		{Kind: CPU, Path: "render/scene", Name: "scene", Depth: 1},
+		{Kind: CPU, Path: "render/scene/shadows", Name: "shadows", Depth: 2},
+		{Kind: CPU, Path: "render/ui", Name: "ui", Depth: 1},
+		{Kind: CPU, Path: "update", Name: "update"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("stats = %+v, want %+v", got, want)
+	}
+
+	// An enclosing scope takes at least as long as what it encloses
+	render, _ := p.Get(CPU, "render")
+	scene, _ := p.Get(CPU, "render/scene")
+	ui, _ := p.Get(CPU, "render/ui")
+	if render.Last < scene.Last+ui.Last {
+		t.Errorf("render took %v, less than its scopes' %v", render.Last, scene.Last+ui.Last)
+	}
+}
+
+func TestRepeatedScopesSummed(t *testing.T) {
+	p := New(10)
+	for i := 0; i < 3; i++ {
+		end := p.Begin("draw")
+		time.Sleep(ms)
+		end()
+		p.Record(GPU, "pass", time.Duration(i+1)*ms)
+	}
+	p.EndFrame()
+
+	if draw, _ := p.Get(CPU, "draw"); draw.Last < 3*ms {
+		t.Errorf("draw took %v over three scopes of at least 1ms", draw.Last)
+	}
+	if pass, _ := p.Get(GPU, "pass"); pass.Last != 6*ms || pass.Average != 6*ms {
+		t.Errorf("pass last %v, average %v, want the frame's 6ms sum", pass.Last, pass.Average)
+	}
+	if frame, _ := p.Get(CPU, FrameScope); frame.Last < 3*ms {
+		t.Errorf("frame took %v, less than its scopes", frame.Last)
+	}
+}
+
+func TestUntouchedSeries(t *testing.T) {
+	p := New(4)
+	p.Record(GPU, "shadows", 8*ms)
+	p.Begin("physics")()
+	p.EndFrame()
+
+	// Neither runs in the next two frames, and a scope is left open
+	p.Begin("loading")
+	p.EndFrame()
+	p.EndFrame()
+
+	shadows, ok := p.Get(GPU, "shadows")
+	if !ok || shadows.Last != 0 || shadows.Average != 8*ms/3 || shadows.Max != 8*ms {
+		t.Errorf("shadows = %+v, want a zero for each frame it missed", shadows)
+	}
+	if physics, _ := p.Get(CPU, "physics"); physics.Last != 0 {
+		t.Errorf("physics last %v, want 0", physics.Last)
+	}
+	if _, ok := p.Get(CPU, "loading"); ok {
+		t.Error("a scope that never ended has a series")
+	}
+	for _, stat := range p.Stats() {
+		if stat.Path == "loading" {
+			t.Error("a scope that never ended is listed")
+		}
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	p := New(10)
+	p.Record(GPU, "scene", 2*ms)
+	p.EndFrame()
+
+	p.SetEnabled(false)
+	if p.IsEnabled() {
+		t.Fatal("still enabled")
+	}
+	p.Begin("update")()
+	p.Record(GPU, "scene", 50*ms)
+	p.EndFrame()
+	if _, ok := p.Get(CPU, "update"); ok {
+		t.Error("scope measured while disabled")
+	}
+	if scene, _ := p.Get(GPU, "scene"); scene.Last != 2*ms || scene.Average != 2*ms {
+		t.Errorf("disabled frame changed scene to %+v", scene)
+	}
+
+	// Scopes left open when disabling do not leak into paths afterwards
+	p.SetEnabled(true)
+	end := p.Begin("outer")
+	p.SetEnabled(false)
+	p.SetEnabled(true)
+	end()
+	p.Begin("inner")()
+	p.EndFrame()
+	if _, ok := p.Get(CPU, "inner"); !ok {
+		t.Error("scope after re-enabling not measured at the top level")
+	}
+	if scene, _ := p.Get(GPU, "scene"); scene.Last != 0 || scene.Average != ms {
+		t.Errorf("scene = %+v, want a zero for the first enabled frame without it", scene)
+	}
+}
+
+func TestReset(t *testing.T) {
+	p := New(10)
+	p.Record(GPU, "scene", ms)
+	p.Begin("update")()
+	p.EndFrame()
+	p.Reset()
+	if stats := p.Stats(); len(stats) != 0 {
+		t.Errorf("stats after Reset = %+v", stats)
+	}
+	p.EndFrame()
+	if stats := p.Stats(); len(stats) != 1 || stats[0].Path != FrameScope {
+		t.Errorf("stats = %+v, want only the frame", stats)
+	}
+}
@@ -0,0 +1,173 @@
+package queue
+
+import (
+	"sort"
+
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/backend"
+)
+
+// Layer orders groups of draws; lower layers are drawn first
+type Layer int
+
+const (
+	LayerBackground Layer = -100
+	LayerDefault    Layer = 0
+	LayerOverlay    Layer = 100
+)
+
+// Command is a single draw collected by the queue
+type Command struct {
+	Mesh        backend.MeshHandle
+	Material    uint32
+	Layer       Layer
+	Transparent bool
+	// Depth is the view-space distance from the camera, used for sorting
+	Depth    float32
+	Instance backend.Instance
+}
+
+// Stats describes the last flush
+type Stats struct {
+	Opaque      int
+	Transparent int
+	DrawCalls   int
+}
+
+// RenderQueue collects draw commands for a frame and submits them in order:
+// by layer, then opaque before transparent. Opaque draws are grouped by
+// material and mesh and sorted front-to-back within a group to reduce
+// state changes and overdraw; transparent draws are sorted back-to-front
+// so blending composites correctly. Consecutive draws of the same mesh and
+// material are submitted as one instanced draw.
+type RenderQueue struct {
+	commands       []Command
+	disabledLayers map[Layer]bool
+	instances      []backend.Instance
+	stats          Stats
+}
+
+// New creates an empty render queue
+func New() *RenderQueue {
+	return &RenderQueue{
+		disabledLayers: make(map[Layer]bool),
+	}
+}
+
+// Push adds a draw command
+func (q *RenderQueue) Push(cmd Command) {
+	q.commands = append(q.commands, cmd)
+}
+
+// Len returns the number of queued commands
+func (q *RenderQueue) Len() int {
+	return len(q.commands)
+}
+
+// SetLayerEnabled controls whether commands in a layer are drawn
+func (q *RenderQueue) SetLayerEnabled(layer Layer, enabled bool) {
+	if enabled {
+		delete(q.disabledLayers, layer)
+	} else {
+		q.disabledLayers[layer] = true
+	}
+}
+
+// IsLayerEnabled reports whether commands in a layer are drawn
+func (q *RenderQueue) IsLayerEnabled(layer Layer) bool {
+	return !q.disabledLayers[layer]
+}
+
+// Sort orders the queued commands for submission
+func (q *RenderQueue) Sort() {
+	sort.SliceStable(q.commands, func(i, j int) bool {
+		return less(&q.commands[i], &q.commands[j])
+	})
+}
+
+func less(a, b *Command) bool {
+	if a.Layer != b.Layer {
+		return a.Layer < b.Layer
+	}
+	if a.Transparent != b.Transparent {
+		return !a.Transparent
+	}
+	if a.Transparent {
+		return a.Depth > b.Depth
+	}
+	if a.Material != b.Material {
+		return a.Material < b.Material
+	}
+	if a.Mesh != b.Mesh {
+		return a.Mesh < b.Mesh
+	}
+	return a.Depth < b.Depth
+}
+
+// Commands returns the queued commands in their current order
+func (q *RenderQueue) Commands() []Command {
+	return q.commands
+}
+
+// Flush sorts and submits all commands to the backend and empties the queue
+func (q *RenderQueue) Flush(b backend.Backend) Stats {
+	q.Sort()
+	q.stats = Stats{}
+
+	depthWrite := true
+	for start := 0; start < len(q.commands); {
+		cmd := &q.commands[start]
+
+		// Gather the run of commands that can share one instanced draw
+		end := start + 1
+		for end < len(q.commands) && batchable(cmd, &q.commands[end]) {
+			end++
+		}
+
+		if q.IsLayerEnabled(cmd.Layer) {
+			// Transparent surfaces are depth tested but must not occlude each other
+			if depthWrite == cmd.Transparent {
+				depthWrite = !cmd.Transparent
+				b.SetDepthWrite(depthWrite)
+			}
+
+			q.instances = q.instances[:0]
+			for i := start; i < end; i++ {
+				q.instances = append(q.instances, q.commands[i].Instance)
+			}
+			b.DrawMeshInstanced(cmd.Mesh, q.instances)
+
+			q.stats.DrawCalls++
+			if cmd.Transparent {
+				q.stats.Transparent += end - start
+			} else {
+				q.stats.Opaque += end - start
+			}
+		}
+
+		start = end
+	}
+
+	if !depthWrite {
+		b.SetDepthWrite(true)
+	}
+
+	q.Reset()
+	return q.stats
+}
+
+func batchable(a, b *Command) bool {
+	return a.Layer == b.Layer &&
+		a.Transparent == b.Transparent &&
+		a.Material == b.Material &&
+		a.Mesh == b.Mesh
+}
+
+// Reset empties the queue, keeping its storage for the next frame
+func (q *RenderQueue) Reset() {
+	q.commands = q.commands[:0]
+}
+
+// GetStats returns the statistics of the last flush
+func (q *RenderQueue) GetStats() Stats {
+	return q.stats
+}
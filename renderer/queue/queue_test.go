@@ -0,0 +1,170 @@
+package queue
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/backend"
+)
+
+// recorder logs the draws and depth write changes a flush makes. Any other
+// backend call panics on the nil embedded interface.
+type recorder struct {
+	backend.Backend
+	calls []string
+}
+
+func (r *recorder) DrawMeshInstanced(mesh backend.MeshHandle, instances []backend.Instance) {
+	// Each instance's red channel names the command it came from
+	call := fmt.Sprintf("mesh %d:", mesh)
+	for _, instance := range instances {
+		call += fmt.Sprintf(" %g", instance.Color[0])
+	}
+	r.calls = append(r.calls, call)
+}
+
+func (r *recorder) SetDepthWrite(enabled bool) {
+	r.calls = append(r.calls, fmt.Sprintf("depth write %v", enabled))
+}
+
+// command builds a draw tagged with id so its place in the output shows
+func command(id float32, layer Layer, material uint32, mesh backend.MeshHandle, depth float32, transparent bool) Command {
+	return Command{
+		Mesh:        mesh,
+		Material:    material,
+		Layer:       layer,
+		Transparent: transparent,
+		Depth:       depth,
+		Instance:    backend.Instance{Color: [4]float32{id, 0, 0, 1}},
+	}
+}
+
+func TestFlush(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []Command
+		disabled []Layer
+		want     []string
+		stats    Stats
+	}{
+		{
+			name: "opaque by layer",
+			commands: []Command{
+				command(1, LayerOverlay, 0, 1, 1, false),
+				command(2, LayerDefault, 0, 1, 1, false),
+				command(3, LayerBackground, 0, 1, 1, false),
+			},
+			want:  []string{"mesh 1: 3", "mesh 1: 2", "mesh 1: 1"},
+			stats: Stats{Opaque: 3, DrawCalls: 3},
+		},
+		{
+			name: "opaque by material before mesh",
+			commands: []Command{
+				command(1, LayerDefault, 2, 1, 1, false),
+				command(2, LayerDefault, 1, 2, 1, false),
+				command(3, LayerDefault, 1, 1, 1, false),
+			},
+			want:  []string{"mesh 1: 3", "mesh 2: 2", "mesh 1: 1"},
+			stats: Stats{Opaque: 3, DrawCalls: 3},
+		},
+		{
+			name: "opaque front to back",
+			commands: []Command{
+				command(1, LayerDefault, 0, 1, 5, false),
+				command(2, LayerDefault, 0, 1, 1, false),
+				command(3, LayerDefault, 0, 1, 3, false),
+			},
+			want:  []string{"mesh 1: 2 3 1"},
+			stats: Stats{Opaque: 3, DrawCalls: 1},
+		},
+		{
+			name: "same mesh runs merged",
+			commands: []Command{
+				command(1, LayerDefault, 0, 2, 1, false),
+				command(2, LayerDefault, 0, 1, 2, false),
+				command(3, LayerDefault, 0, 2, 3, false),
+				command(4, LayerDefault, 0, 1, 4, false),
+				command(5, LayerDefault, 1, 1, 5, false),
+				command(6, LayerOverlay, 0, 1, 6, false),
+			},
+			want:  []string{"mesh 1: 2 4", "mesh 2: 1 3", "mesh 1: 5", "mesh 1: 6"},
+			stats: Stats{Opaque: 6, DrawCalls: 4},
+		},
+		{
+			name: "transparent back to front after opaque",
+			commands: []Command{
+				command(1, LayerDefault, 0, 1, 1, true),
+				command(2, LayerDefault, 0, 2, 5, true),
+				command(3, LayerDefault, 0, 1, 3, true),
+				command(4, LayerDefault, 0, 1, 9, false),
+			},
+			want: []string{
+				"mesh 1: 4",
+				"depth write false",
+				"mesh 2: 2", "mesh 1: 3 1",
+				"depth write true",
+			},
+			stats: Stats{Opaque: 1, Transparent: 3, DrawCalls: 3},
+		},
+		{
+			name: "transparent layer under opaque",
+			commands: []Command{
+				command(1, LayerOverlay, 0, 1, 1, false),
+				command(2, LayerDefault, 0, 1, 1, true),
+			},
+			want:  []string{"depth write false", "mesh 1: 2", "depth write true", "mesh 1: 1"},
+			stats: Stats{Opaque: 1, Transparent: 1, DrawCalls: 2},
+		},
+		{
+			name: "disabled layers skipped",
+			commands: []Command{
+				command(1, LayerBackground, 0, 1, 1, false),
+				command(2, LayerDefault, 0, 1, 1, false),
+				command(3, LayerOverlay, 0, 1, 1, true),
+			},
+			disabled: []Layer{LayerDefault, LayerOverlay},
+			want:     []string{"mesh 1: 1"},
+			stats:    Stats{Opaque: 1, DrawCalls: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := New()
+			for _, layer := range tt.disabled {
+				q.SetLayerEnabled(layer, false)
+			}
+			for _, cmd := range tt.commands {
+				q.Push(cmd)
+			}
+
+			r := &recorder{}
+			stats := q.Flush(r)
+			if !reflect.DeepEqual(r.calls, tt.want) {
+				t.Errorf("calls = %q, want %q", r.calls, tt.want)
+			}
+			if stats != tt.stats || q.GetStats() != tt.stats {
+				t.Errorf("stats = %+v, want %+v", stats, tt.stats)
+			}
+			if q.Len() != 0 {
+				t.Errorf("%d commands left after the flush", q.Len())
+			}
+		})
+	}
+}
+
+func TestLayerEnabled(t *testing.T) {
+	q := New()
+	if !q.IsLayerEnabled(LayerDefault) {
+		t.Fatal("layers start disabled")
+	}
+	q.SetLayerEnabled(LayerDefault, false)
+	if q.IsLayerEnabled(LayerDefault) || !q.IsLayerEnabled(LayerOverlay) {
+		t.Error("disabling one layer affected another")
+	}
+	q.SetLayerEnabled(LayerDefault, true)
+	if !q.IsLayerEnabled(LayerDefault) {
+		t.Error("layer not enabled again")
+	}
+}
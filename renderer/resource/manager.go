@@ -0,0 +1,236 @@
+// Package resource tracks the lifetime of GPU objects. Objects are
+// reference counted; when the last reference is released the object is
+// queued and only deleted at the next Collect, which the backend calls at
+// a safe point in the frame. Objects still referenced at Shutdown are
+// reported as leaks together with where they were created.
+package resource
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Kind is the type of GPU object a resource wraps
+type Kind int
+
+const (
+	KindMesh Kind = iota
+	KindShader
+	KindTexture
+	KindFramebuffer
+	KindBuffer
+	KindQuery
+)
+
+var kindNames = [...]string{"mesh", "shader", "texture", "framebuffer", "buffer", "query"}
+
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return "unknown"
+	}
+	return kindNames[k]
+}
+
+// Deleter is implemented by GPU objects that can free themselves
+type Deleter interface {
+	Delete()
+}
+
+// ID identifies a tracked resource; zero is never assigned
+type ID uint64
+
+// Info describes a tracked resource
+type Info struct {
+	ID     ID
+	Kind   Kind
+	Name   string
+	Refs   int
+	Origin string
+}
+
+func (i Info) String() string {
+	return fmt.Sprintf("%s %q (refs=%d) created at %s", i.Kind, i.Name, i.Refs, i.Origin)
+}
+
+type entry struct {
+	info   Info
+	object Deleter
+}
+
+// Manager reference-counts GPU objects. Register, Retain and Release may be
+// called from any goroutine; Collect and Shutdown delete objects and must
+// run on the thread that owns the GPU context.
+type Manager struct {
+	mu      sync.Mutex
+	entries map[ID]*entry
+	pending []*entry
+	nextID  ID
+	deleted int
+}
+
+func NewManager() *Manager {
+	return &Manager{
+		entries: make(map[ID]*entry),
+		nextID:  1,
+	}
+}
+
+// Register starts tracking an object with one reference held by the caller
+func (m *Manager) Register(kind Kind, name string, object Deleter) ID {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := m.nextID
+	m.nextID++
+	m.entries[id] = &entry{
+		info: Info{
+			ID:     id,
+			Kind:   kind,
+			Name:   name,
+			Refs:   1,
+			Origin: origin(),
+		},
+		object: object,
+	}
+	return id
+}
+
+// Retain adds a reference to a live resource
+func (m *Manager) Retain(id ID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, exists := m.entries[id]
+	if !exists {
+		return fmt.Errorf("retain of unknown resource %d", id)
+	}
+	e.info.Refs++
+	return nil
+}
+
+// Release drops a reference. The object is deleted at the next Collect
+// once no references remain.
+func (m *Manager) Release(id ID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, exists := m.entries[id]
+	if !exists {
+		return fmt.Errorf("release of unknown or already released resource %d", id)
+	}
+	e.info.Refs--
+	if e.info.Refs == 0 {
+		delete(m.entries, id)
+		m.pending = append(m.pending, e)
+	}
+	return nil
+}
+
+// Collect deletes the objects whose last reference was released and
+// returns how many were deleted
+func (m *Manager) Collect() int {
+	m.mu.Lock()
+	pending := m.pending
+	m.pending = nil
+	m.deleted += len(pending)
+	m.mu.Unlock()
+
+	for _, e := range pending {
+		e.object.Delete()
+	}
+	return len(pending)
+}
+
+// Get returns the description of a live resource
+func (m *Manager) Get(id ID) (Info, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, exists := m.entries[id]
+	if !exists {
+		return Info{}, false
+	}
+	return e.info, true
+}
+
+// Live returns all resources that still hold references, oldest first
+func (m *Manager) Live() []Info {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	live := make([]Info, 0, len(m.entries))
+	for _, e := range m.entries {
+		live = append(live, e.info)
+	}
+	sort.Slice(live, func(i, j int) bool { return live[i].ID < live[j].ID })
+	return live
+}
+
+// Stats counts live resources by kind
+type Stats struct {
+	Live    map[Kind]int
+	Pending int
+	Deleted int
+}
+
+func (m *Manager) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := Stats{
+		Live:    make(map[Kind]int),
+		Pending: len(m.pending),
+		Deleted: m.deleted,
+	}
+	for _, e := range m.entries {
+		stats.Live[e.info.Kind]++
+	}
+	return stats
+}
+
+// Shutdown deletes pending objects, then reports every resource still
+// referenced as a leak and deletes it too. It returns the leaks.
+func (m *Manager) Shutdown() []Info {
+	m.Collect()
+
+	leaks := m.Live()
+	for _, leak := range leaks {
+		fmt.Printf("Resource leak: %v\n", leak)
+	}
+
+	m.mu.Lock()
+	entries := m.entries
+	m.entries = make(map[ID]*entry)
+	m.mu.Unlock()
+
+	for _, leak := range leaks {
+		entries[leak.ID].object.Delete()
+	}
+	return leaks
+}
+
+// origin returns the first caller outside the renderer's resource-owning
+// packages, which is where a leaked object was asked for
+func origin() string {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !internal(frame.Function) || !more {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+	}
+}
+
+func internal(function string) bool {
+	for _, pkg := range []string{"/renderer/resource.", "/renderer/opengl."} {
+		if strings.Contains(function, pkg) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+package resource_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/resource"
+)
+
+// object counts how often it was deleted
+type object struct {
+	deletes int
+}
+
+func (o *object) Delete() {
+	o.deletes++
+}
+
+func TestRetainRelease(t *testing.T) {
+	tests := []struct {
+		name     string
+		retains  int
+		releases int
+		live     bool
+	}{
+		{"registered", 0, 0, true},
+		{"released", 0, 1, false},
+		{"retained twice released twice", 2, 2, true},
+		{"retained twice released three times", 2, 3, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := resource.NewManager()
+			obj := &object{}
+			id := m.Register(resource.KindTexture, "albedo", obj)
+
+			for i := 0; i < tt.retains; i++ {
+				if err := m.Retain(id); err != nil {
+					t.Fatalf("Retain: %v", err)
+				}
+			}
+			for i := 0; i < tt.releases; i++ {
+				if err := m.Release(id); err != nil {
+					t.Fatalf("Release: %v", err)
+				}
+			}
+
+			info, live := m.Get(id)
+			if live != tt.live {
+				t.Fatalf("live = %v, want %v", live, tt.live)
+			}
+			if live && info.Refs != 1+tt.retains-tt.releases {
+				t.Errorf("refs = %d, want %d", info.Refs, 1+tt.retains-tt.releases)
+			}
+			if obj.deletes != 0 {
+				t.Errorf("deleted before Collect")
+			}
+		})
+	}
+}
+
+func TestUnknownResource(t *testing.T) {
+	m := resource.NewManager()
+	id := m.Register(resource.KindMesh, "cube", &object{})
+	if err := m.Release(id); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+
+	if err := m.Release(id); err == nil {
+		t.Error("double release succeeded")
+	}
+	if err := m.Retain(id); err == nil {
+		t.Error("retain after release succeeded")
+	}
+	if err := m.Retain(resource.ID(99)); err == nil {
+		t.Error("retain of an unregistered id succeeded")
+	}
+}
+
+func TestDeferredCollect(t *testing.T) {
+	m := resource.NewManager()
+	kept := &object{}
+	dropped := &object{}
+	m.Register(resource.KindShader, "kept", kept)
+	id := m.Register(resource.KindBuffer, "dropped", dropped)
+
+	if err := m.Release(id); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if stats := m.Stats(); stats.Pending != 1 || stats.Deleted != 0 || stats.Live[resource.KindBuffer] != 0 {
+		t.Errorf("stats before Collect = %+v", stats)
+	}
+	if dropped.deletes != 0 {
+		t.Fatal("released object deleted before Collect")
+	}
+
+	if n := m.Collect(); n != 1 {
+		t.Errorf("Collect deleted %d, want 1", n)
+	}
+	if dropped.deletes != 1 || kept.deletes != 0 {
+		t.Errorf("deletes: dropped %d, kept %d", dropped.deletes, kept.deletes)
+	}
+	if stats := m.Stats(); stats.Pending != 0 || stats.Deleted != 1 || stats.Live[resource.KindShader] != 1 {
+		t.Errorf("stats after Collect = %+v", stats)
+	}
+
+	if n := m.Collect(); n != 0 || dropped.deletes != 1 {
+		t.Errorf("second Collect deleted %d, object deleted %d times", n, dropped.deletes)
+	}
+}
+
+func TestConcurrentRelease(t *testing.T) {
+	m := resource.NewManager()
+	obj := &object{}
+	id := m.Register(resource.KindTexture, "shared", obj)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		if err := m.Retain(id); err != nil {
+			t.Fatalf("Retain: %v", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := m.Release(id); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if info, _ := m.Get(id); info.Refs != 1 {
+		t.Errorf("refs = %d, want 1", info.Refs)
+	}
+	m.Release(id)
+	if m.Collect() != 1 || obj.deletes != 1 {
+		t.Errorf("object deleted %d times, want 1", obj.deletes)
+	}
+}
+
+func TestShutdownReportsLeaks(t *testing.T) {
+	m := resource.NewManager()
+	released := &object{}
+	leakedMesh := &object{}
+	leakedTexture := &object{}
+	m.Register(resource.KindMesh, "cube", leakedMesh)
+	id := m.Register(resource.KindQuery, "timer", released)
+	textureID := m.Register(resource.KindTexture, "albedo", leakedTexture)
+	m.Retain(textureID)
+	m.Release(id)
+
+	leaks := m.Shutdown()
+	if len(leaks) != 2 {
+		t.Fatalf("got %d leaks, want 2: %v", len(leaks), leaks)
+	}
+	want := []struct {
+		kind resource.Kind
+		name string
+		refs int
+	}{
+		{resource.KindMesh, "cube", 1},
+		{resource.KindTexture, "albedo", 2},
+	}
+	for i, leak := range leaks {
+		if leak.Kind != want[i].kind || leak.Name != want[i].name || leak.Refs != want[i].refs {
+			t.Errorf("leak %d = %v, want %v %q refs=%d", i, leak, want[i].kind, want[i].name, want[i].refs)
+		}
+		// The origin is the caller of Register, not the manager
+		if !strings.Contains(leak.Origin, "manager_test.go:") {
+			t.Errorf("leak %d origin = %q", i, leak.Origin)
+		}
+	}
+
+	for name, obj := range map[string]*object{"released": released, "mesh": leakedMesh, "texture": leakedTexture} {
+		if obj.deletes != 1 {
+			t.Errorf("%s deleted %d times, want 1", name, obj.deletes)
+		}
+	}
+	if live := m.Live(); len(live) != 0 {
+		t.Errorf("live after Shutdown = %v", live)
+	}
+	if leaks := m.Shutdown(); len(leaks) != 0 {
+		t.Errorf("second Shutdown reported %v", leaks)
+	}
+}
@@ -0,0 +1,108 @@
+package software
+
+import (
+	"math"
+)
+
+// DrawLines rasterizes line segments from pairs of vertices in world space
+func (r *Rasterizer) DrawLines(vertices []float32, depthTest bool) {
+	vp := r.view.Multiply(r.projection)
+	for i := 0; i+11 < len(vertices); i += 12 {
+		a := vertices[i : i+6]
+		b := vertices[i+6 : i+12]
+		r.drawLine(
+			clipVertex{pos: transform(vp, a[0], a[1], a[2]), color: [4]float32{a[3], a[4], a[5], 1}},
+			clipVertex{pos: transform(vp, b[0], b[1], b[2]), color: [4]float32{b[3], b[4], b[5], 1}},
+			depthTest,
+		)
+	}
+}
+
+// sidePlanes bound lines to the viewport as well, as dot(plane, pos) >= 0.
+// Triangles are bounded by their pixel bounds, but a line is walked pixel
+// by pixel, so one reaching far off screen would take as many steps.
+var sidePlanes = [][4]float32{
+	{1, 0, 0, 1},  // left: x >= -w
+	{-1, 0, 0, 1}, // right: x <= w
+	{0, 1, 0, 1},  // bottom: y >= -w
+	{0, -1, 0, 1}, // top: y <= w
+}
+
+func (r *Rasterizer) drawLine(a, b clipVertex, depthTest bool) {
+	for i := range clipPlanes {
+		var ok bool
+		if a, b, ok = clipSegment(a, b, clipDistance(i, a.pos), clipDistance(i, b.pos)); !ok {
+			return
+		}
+	}
+	for _, plane := range sidePlanes {
+		var ok bool
+		if a, b, ok = clipSegment(a, b, planeDistance(plane, a.pos), planeDistance(plane, b.pos)); !ok {
+			return
+		}
+	}
+
+	sa := r.toScreen(a)
+	sb := r.toScreen(b)
+
+	dx := sb.x - sa.x
+	dy := sb.y - sa.y
+	steps := int(math.Ceil(float64(max(abs(dx), abs(dy)))))
+	if steps == 0 {
+		steps = 1
+	}
+
+	for step := 0; step <= steps; step++ {
+		t := float32(step) / float32(steps)
+		x := int(math.Floor(float64(sa.x + dx*t)))
+		y := int(math.Floor(float64(sa.y + dy*t)))
+		if x < 0 || y < 0 || x >= r.width || y >= r.height {
+			continue
+		}
+
+		row := r.height - 1 - y
+		index := row*r.width + x
+		depth := sa.z + (sb.z-sa.z)*t - r.depthBias
+		// Like OpenGL, disabling the depth test also disables depth writes
+		if depthTest {
+			if depth >= r.depth[index] {
+				continue
+			}
+			if r.depthWrite {
+				r.depth[index] = depth
+			}
+		}
+
+		invW := sa.invW + (sb.invW-sa.invW)*t
+		var rgba [4]float32
+		for i := range rgba {
+			rgba[i] = (sa.color[i] + (sb.color[i]-sa.color[i])*t) / invW
+		}
+		r.blend(r.color.PixOffset(x, row), rgba)
+	}
+}
+
+// clipSegment cuts a segment to the side of a plane its ends are at
+// distances da and db from, reporting false if none of it is left
+func clipSegment(a, b clipVertex, da, db float32) (clipVertex, clipVertex, bool) {
+	if da < 0 && db < 0 {
+		return a, b, false
+	}
+	if da < 0 {
+		a = lerpVertex(a, b, da/(da-db))
+	} else if db < 0 {
+		b = lerpVertex(b, a, db/(db-da))
+	}
+	return a, b, true
+}
+
+func planeDistance(plane, pos [4]float32) float32 {
+	return plane[0]*pos[0] + plane[1]*pos[1] + plane[2]*pos[2] + plane[3]*pos[3]
+}
+
+func abs(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
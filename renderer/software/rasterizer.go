@@ -0,0 +1,251 @@
+package software
+
+import (
+	"image"
+	"image/color"
+
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/backend"
+)
+
+type mesh struct {
+	vertices []float32
+	indices  []uint32
+	bounds   bmath.AABB
+}
+
+// Rasterizer is a CPU implementation of backend.Backend that renders into
+// an image.RGBA with a depth buffer. It mirrors the OpenGL backend's state
+// (depth test LESS, alpha blending, no face culling) so that scenes render
+// deterministically without a GPU.
+type Rasterizer struct {
+	width       int
+	height      int
+	color       *image.RGBA
+	depth       []float32
+	clearColor  color.RGBA
+	meshes      map[backend.MeshHandle]*mesh
+	nextHandle  backend.MeshHandle
+	view        bmath.Matrix4
+	projection  bmath.Matrix4
+	depthWrite  bool
+	depthTest   bool
+	additive    bool
+	depthBias   float32
+	viewMode    backend.ViewMode
+	cubemaps    map[backend.TextureHandle]backend.CubemapFaces
+	textures    map[backend.TextureHandle]*texture
+	nextTexture backend.TextureHandle
+	sky         *backend.CubemapFaces
+	sprite      *texture
+}
+
+var _ backend.Backend = (*Rasterizer)(nil)
+
+// NewRasterizer creates a software rasterizer with the given framebuffer size
+func NewRasterizer(width, height int) *Rasterizer {
+	r := &Rasterizer{
+		meshes:      make(map[backend.MeshHandle]*mesh),
+		nextHandle:  1,
+		cubemaps:    make(map[backend.TextureHandle]backend.CubemapFaces),
+		textures:    make(map[backend.TextureHandle]*texture),
+		nextTexture: 1,
+		view:        bmath.NewMatrix4Identity(),
+		projection:  bmath.NewMatrix4Identity(),
+		depthWrite:  true,
+		depthTest:   true,
+	}
+	r.SetClearColor(0.1, 0.1, 0.1, 1.0)
+	r.SetViewport(width, height)
+	return r
+}
+
+// CreateMesh copies the vertex data into the rasterizer
+func (r *Rasterizer) CreateMesh(vertices []float32, indices []uint32) backend.MeshHandle {
+	m := &mesh{
+		vertices: append([]float32(nil), vertices...),
+		bounds:   bmath.NewAABBFromVertices(vertices, 6),
+	}
+	if indices != nil {
+		m.indices = append([]uint32(nil), indices...)
+	}
+
+	handle := r.nextHandle
+	r.nextHandle++
+	r.meshes[handle] = m
+	return handle
+}
+
+// DeleteMesh releases a mesh
+func (r *Rasterizer) DeleteMesh(handle backend.MeshHandle) {
+	delete(r.meshes, handle)
+}
+
+// DrawMesh rasterizes every triangle of a mesh with the given model matrix
+func (r *Rasterizer) DrawMesh(handle backend.MeshHandle, model bmath.Matrix4) {
+	m, exists := r.meshes[handle]
+	if !exists {
+		return
+	}
+	r.drawMesh(m, model, [4]float32{1, 1, 1, 1})
+}
+
+// DrawMeshInstanced draws the mesh once per instance, tinted by its color
+func (r *Rasterizer) DrawMeshInstanced(handle backend.MeshHandle, instances []backend.Instance) {
+	m, exists := r.meshes[handle]
+	if !exists {
+		return
+	}
+	for i := range instances {
+		r.drawMesh(m, instances[i].Model, instances[i].Color)
+	}
+}
+
+func (r *Rasterizer) drawMesh(m *mesh, model bmath.Matrix4, tint [4]float32) {
+	// Matrices are laid out for OpenGL, which reads them column-major, so
+	// projection * view * model there is model * view * projection here.
+	mvp := model.Multiply(r.view).Multiply(r.projection)
+
+	vertexCount := len(m.vertices) / 6
+	clipped := make([]clipVertex, vertexCount)
+	for i := 0; i < vertexCount; i++ {
+		v := m.vertices[i*6 : i*6+6]
+		clipped[i] = clipVertex{
+			pos:   transform(mvp, v[0], v[1], v[2]),
+			color: [4]float32{v[3] * tint[0], v[4] * tint[1], v[5] * tint[2], tint[3]},
+		}
+	}
+
+	triangle := func(a, b, c uint32) {
+		if r.viewMode == backend.ViewShaded {
+			r.drawTriangle(clipped[a], clipped[b], clipped[c])
+		} else {
+			r.drawTriangleViewMode(m, model, [3]uint32{a, b, c}, [3]clipVertex{clipped[a], clipped[b], clipped[c]})
+		}
+	}
+
+	if m.indices != nil {
+		for i := 0; i+2 < len(m.indices); i += 3 {
+			a, b, c := m.indices[i], m.indices[i+1], m.indices[i+2]
+			if int(a) >= vertexCount || int(b) >= vertexCount || int(c) >= vertexCount {
+				continue
+			}
+			triangle(a, b, c)
+		}
+		return
+	}
+
+	for i := 0; i+2 < vertexCount; i += 3 {
+		triangle(uint32(i), uint32(i+1), uint32(i+2))
+	}
+}
+
+// MeshBounds returns the local-space bounds of a mesh
+func (r *Rasterizer) MeshBounds(handle backend.MeshHandle) bmath.AABB {
+	m, exists := r.meshes[handle]
+	if !exists {
+		return bmath.AABB{}
+	}
+	return m.bounds
+}
+
+// SetCamera sets the view and projection matrices used by DrawMesh
+func (r *Rasterizer) SetCamera(view, projection bmath.Matrix4) {
+	r.view = view
+	r.projection = projection
+}
+
+// SetViewport resizes the framebuffer, discarding its contents
+func (r *Rasterizer) SetViewport(width, height int) {
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+	if r.color != nil && width == r.width && height == r.height {
+		return
+	}
+
+	r.width = width
+	r.height = height
+	r.color = image.NewRGBA(image.Rect(0, 0, width, height))
+	r.depth = make([]float32, width*height)
+	r.clear()
+}
+
+// SetClearColor sets the color used by BeginFrame
+func (r *Rasterizer) SetClearColor(red, green, blue, alpha float32) {
+	r.clearColor = color.RGBA{
+		R: toByte(red),
+		G: toByte(green),
+		B: toByte(blue),
+		A: toByte(alpha),
+	}
+}
+
+// SetDepthWrite controls whether draws update the depth buffer
+func (r *Rasterizer) SetDepthWrite(enabled bool) {
+	r.depthWrite = enabled
+}
+
+// BeginFrame clears the color and depth buffers
+func (r *Rasterizer) BeginFrame() {
+	r.clear()
+}
+
+// EndFrame is a no-op; the frame is available through Image
+func (r *Rasterizer) EndFrame() {}
+
+// Cleanup releases all meshes and textures
+func (r *Rasterizer) Cleanup() {
+	r.meshes = make(map[backend.MeshHandle]*mesh)
+	r.cubemaps = make(map[backend.TextureHandle]backend.CubemapFaces)
+	r.textures = make(map[backend.TextureHandle]*texture)
+}
+
+// Image returns the color buffer with the origin at the top-left, as it
+// would appear on screen
+func (r *Rasterizer) Image() *image.RGBA {
+	return r.color
+}
+
+// DepthAt returns the window-space depth at a pixel in image coordinates
+func (r *Rasterizer) DepthAt(x, y int) float32 {
+	if x < 0 || y < 0 || x >= r.width || y >= r.height {
+		return 1.0
+	}
+	return r.depth[y*r.width+x]
+}
+
+// Size returns the framebuffer size
+func (r *Rasterizer) Size() (int, int) {
+	return r.width, r.height
+}
+
+func (r *Rasterizer) clear() {
+	pix := r.color.Pix
+	for i := 0; i < len(pix); i += 4 {
+		pix[i] = r.clearColor.R
+		pix[i+1] = r.clearColor.G
+		pix[i+2] = r.clearColor.B
+		pix[i+3] = r.clearColor.A
+	}
+	for i := range r.depth {
+		r.depth[i] = 1.0
+	}
+}
+
+// transform multiplies a point by a column-major matrix
+func transform(m bmath.Matrix4, x, y, z float32) [4]float32 {
+	return [4]float32{
+		m[0]*x + m[4]*y + m[8]*z + m[12],
+		m[1]*x + m[5]*y + m[9]*z + m[13],
+		m[2]*x + m[6]*y + m[10]*z + m[14],
+		m[3]*x + m[7]*y + m[11]*z + m[15],
+	}
+}
+
+func toByte(v float32) uint8 {
+	return uint8(bmath.Clamp(v, 0, 1)*255 + 0.5)
+}
@@ -0,0 +1,262 @@
+package software
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/backend"
+)
+
+// triangle builds position-color vertices for a triangle in normalized
+// device coordinates, all at depth z
+func triangle(points [3][2]float32, z float32) []float32 {
+	var vertices []float32
+	for _, p := range points {
+		vertices = append(vertices, p[0], p[1], z, 1, 1, 1)
+	}
+	return vertices
+}
+
+// quad covers the whole viewport at depth z
+func quad(z float32) []float32 {
+	return append(
+		triangle([3][2]float32{{-1, -1}, {1, -1}, {-1, 1}}, z),
+		triangle([3][2]float32{{1, -1}, {1, 1}, {-1, 1}}, z)...)
+}
+
+func drawTinted(r *Rasterizer, vertices []float32, tint [4]float32) {
+	mesh := r.CreateMesh(vertices, nil)
+	r.DrawMeshInstanced(mesh, []backend.Instance{{Model: bmath.NewMatrix4Identity(), Color: tint}})
+}
+
+// coverage renders the image top-down, '#' where a pixel differs from the
+// clear color
+func coverage(r *Rasterizer) string {
+	img := r.Image()
+	var rows []string
+	for y := 0; y < img.Bounds().Dy(); y++ {
+		var row strings.Builder
+		for x := 0; x < img.Bounds().Dx(); x++ {
+			if img.RGBAAt(x, y) == r.clearColor {
+				row.WriteByte('.')
+			} else {
+				row.WriteByte('#')
+			}
+		}
+		rows = append(rows, row.String())
+	}
+	return strings.Join(rows, "\n")
+}
+
+func TestTriangleCoverage(t *testing.T) {
+	tests := []struct {
+		name   string
+		points [3][2]float32
+		want   []string
+	}{
+		{
+			// The hypotenuse runs through pixel centers and is a right edge,
+			// so the pixels on it are left out
+			name:   "lower left",
+			points: [3][2]float32{{-1, -1}, {1, -1}, {-1, 1}},
+			want:   []string{"....", "#...", "##..", "###."},
+		},
+		{
+			name:   "clockwise",
+			points: [3][2]float32{{-1, -1}, {-1, 1}, {1, -1}},
+			want:   []string{"....", "#...", "##..", "###."},
+		},
+		{
+			// The same hypotenuse is a left edge here, so its pixels are in
+			name:   "upper right",
+			points: [3][2]float32{{1, -1}, {1, 1}, {-1, 1}},
+			want:   []string{"####", ".###", "..##", "...#"},
+		},
+		{
+			name:   "beyond the viewport",
+			points: [3][2]float32{{-1, -1}, {3, -1}, {-1, 3}},
+			want:   []string{"####", "####", "####", "####"},
+		},
+		{
+			name:   "degenerate",
+			points: [3][2]float32{{-1, -1}, {0, 0}, {1, 1}},
+			want:   []string{"....", "....", "....", "...."},
+		},
+		{
+			name:   "between pixel centers",
+			points: [3][2]float32{{-0.9, -0.9}, {-0.6, -0.9}, {-0.9, -0.6}},
+			want:   []string{"....", "....", "....", "...."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRasterizer(4, 4)
+			drawTinted(r, triangle(tt.points, 0), [4]float32{1, 1, 1, 1})
+			if got, want := coverage(r), strings.Join(tt.want, "\n"); got != want {
+				t.Errorf("coverage\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestSharedEdgeDrawnOnce(t *testing.T) {
+	// Pixels drawn twice with half alpha would come out brighter
+	r := NewRasterizer(4, 4)
+	r.SetClearColor(0, 0, 0, 1)
+	r.BeginFrame()
+	drawTinted(r, quad(0), [4]float32{1, 0, 0, 0.5})
+
+	img := r.Image()
+	want := color.RGBA{128, 0, 0, 255}
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			if got := img.RGBAAt(x, y); got != want {
+				t.Errorf("pixel (%d, %d) = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestDepthTest(t *testing.T) {
+	red := [4]float32{1, 0, 0, 1}
+	green := [4]float32{0, 1, 0, 1}
+	tests := []struct {
+		name       string
+		second     float32 // Depth of the green quad drawn after the red one at 0
+		depthWrite bool
+		want       color.RGBA
+		wantDepth  float32
+	}{
+		{"nearer passes", -0.5, true, color.RGBA{0, 255, 0, 255}, 0.25},
+		{"farther fails", 0.5, true, color.RGBA{255, 0, 0, 255}, 0.5},
+		{"equal fails", 0, true, color.RGBA{255, 0, 0, 255}, 0.5},
+		{"nearer without depth write", -0.5, false, color.RGBA{0, 255, 0, 255}, 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRasterizer(4, 4)
+			drawTinted(r, quad(0), red)
+			r.SetDepthWrite(tt.depthWrite)
+			drawTinted(r, quad(tt.second), green)
+
+			if got := r.Image().RGBAAt(1, 1); got != tt.want {
+				t.Errorf("color = %v, want %v", got, tt.want)
+			}
+			if got := r.DepthAt(1, 1); got != tt.wantDepth {
+				t.Errorf("depth = %v, want %v", got, tt.wantDepth)
+			}
+		})
+	}
+}
+
+func TestBlending(t *testing.T) {
+	tests := []struct {
+		name  string
+		clear [4]float32
+		tint  [4]float32
+		want  color.RGBA
+	}{
+		{"opaque", [4]float32{0, 0, 0, 1}, [4]float32{1, 0, 0, 1}, color.RGBA{255, 0, 0, 255}},
+		{"half over black", [4]float32{0, 0, 0, 1}, [4]float32{1, 0, 0, 0.5}, color.RGBA{128, 0, 0, 255}},
+		{"half over white", [4]float32{1, 1, 1, 1}, [4]float32{1, 0, 0, 0.5}, color.RGBA{255, 128, 128, 255}},
+		{"invisible", [4]float32{0, 0, 1, 1}, [4]float32{1, 0, 0, 0}, color.RGBA{0, 0, 255, 255}},
+		{"half over transparent", [4]float32{0, 0, 0, 0}, [4]float32{0, 1, 0, 0.5}, color.RGBA{0, 128, 0, 128}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRasterizer(4, 4)
+			r.SetClearColor(tt.clear[0], tt.clear[1], tt.clear[2], tt.clear[3])
+			r.BeginFrame()
+			drawTinted(r, quad(0), tt.tint)
+			if got := r.Image().RGBAAt(2, 2); got != tt.want {
+				t.Errorf("pixel = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDrawMeshInstanced(t *testing.T) {
+	// A triangle with a different color at each corner, so tints multiply
+	// something other than white
+	base := []float32{
+		-0.5, -0.5, 0, 1, 0.5, 0.25,
+		0.5, -0.5, 0, 0.25, 1, 0.5,
+		0, 0.5, 0, 0.5, 0.25, 1,
+	}
+	// Models are in the GPU's column-major layout. The instances overlap,
+	// at different depths, so the depth test decides what shows.
+	instances := []backend.Instance{
+		{Model: bmath.NewTranslationMatrix(-0.4, 0, 0.2).Transpose(), Color: [4]float32{1, 0, 0, 1}},
+		{Model: bmath.NewTranslationMatrix(0, 0.2, -0.1).Transpose(), Color: [4]float32{0.5, 1, 0.5, 1}},
+		{Model: bmath.NewTranslationMatrix(0.4, -0.3, 0.4).Transpose(), Color: [4]float32{0.2, 0.4, 1, 1}},
+		{Model: bmath.NewScaleMatrix(0.5, 0.5, 1).Multiply(bmath.NewRotationZ(1)).Transpose(), Color: [4]float32{1, 1, 1, 1}},
+	}
+
+	instanced := NewRasterizer(16, 16)
+	instanced.DrawMeshInstanced(instanced.CreateMesh(base, nil), instances)
+
+	// Draw each instance as its own mesh with the tint baked into its colors
+	separate := NewRasterizer(16, 16)
+	for _, instance := range instances {
+		vertices := append([]float32(nil), base...)
+		for i := 0; i < len(vertices); i += 6 {
+			for c := 0; c < 3; c++ {
+				vertices[i+3+c] *= instance.Color[c]
+			}
+		}
+		separate.DrawMesh(separate.CreateMesh(vertices, nil), instance.Model)
+	}
+
+	if coverage(instanced) == coverage(NewRasterizer(16, 16)) {
+		t.Fatal("instanced draw left the image empty")
+	}
+	want, got := separate.Image(), instanced.Image()
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			if got.RGBAAt(x, y) != want.RGBAAt(x, y) {
+				t.Errorf("pixel (%d, %d) = %v, want %v", x, y, got.RGBAAt(x, y), want.RGBAAt(x, y))
+			}
+			if instanced.DepthAt(x, y) != separate.DepthAt(x, y) {
+				t.Errorf("depth (%d, %d) = %v, want %v", x, y, instanced.DepthAt(x, y), separate.DepthAt(x, y))
+			}
+		}
+	}
+}
+
+func TestLineClipping(t *testing.T) {
+	// Ends are in clip space, so a small w puts an end far off screen
+	tests := []struct {
+		name string
+		a, b [4]float32
+		want string // The row the line is on
+	}{
+		{"inside", [4]float32{-0.5, 0.1, 0, 1}, [4]float32{0.5, 0.1, 0, 1}, "..#####."},
+		{"one end far off screen", [4]float32{0, 0.1, 0, 1}, [4]float32{1, 2e-6, 0, 2e-5}, "....####"},
+		{"both ends far off screen", [4]float32{-1, 2e-6, 0, 2e-5}, [4]float32{1, 2e-6, 0, 2e-5}, "########"},
+		{"w too close to zero", [4]float32{-1, 0, 0, 1e-7}, [4]float32{1, 0, 0, 1e-7}, "........"},
+		{"across the viewport", [4]float32{-1e6, 0.1, 0, 1}, [4]float32{1e6, 0.1, 0, 1}, "########"},
+		{"beside the viewport", [4]float32{2, 0.1, 0, 1}, [4]float32{1e6, 0.1, 0, 1}, "........"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRasterizer(8, 8)
+			white := [4]float32{1, 1, 1, 1}
+			r.drawLine(clipVertex{pos: tt.a, color: white}, clipVertex{pos: tt.b, color: white}, false)
+			rows := strings.Split(coverage(r), "\n")
+			if rows[3] != tt.want {
+				t.Errorf("row = %s, want %s", rows[3], tt.want)
+			}
+			for i, row := range rows {
+				if i != 3 && row != "........" {
+					t.Errorf("row %d = %s", i, row)
+				}
+			}
+		})
+	}
+}
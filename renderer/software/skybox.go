@@ -0,0 +1,71 @@
+package software
+
+import (
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/backend"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/environment"
+)
+
+var skyboxCorners = [8][3]float32{
+	{-1, -1, -1}, {1, -1, -1}, {1, 1, -1}, {-1, 1, -1},
+	{-1, -1, 1}, {1, -1, 1}, {1, 1, 1}, {-1, 1, 1},
+}
+
+var skyboxIndices = [36]int{
+	0, 1, 2, 2, 3, 0,
+	4, 6, 5, 6, 4, 7,
+	0, 3, 7, 7, 4, 0,
+	1, 5, 6, 6, 2, 1,
+	3, 2, 6, 6, 7, 3,
+	0, 4, 5, 5, 1, 0,
+}
+
+// CreateCubemap keeps a reference to the face images
+func (r *Rasterizer) CreateCubemap(faces backend.CubemapFaces) backend.TextureHandle {
+	handle := r.nextTexture
+	r.nextTexture++
+	r.cubemaps[handle] = faces
+	return handle
+}
+
+// DeleteTexture releases a texture or cubemap
+func (r *Rasterizer) DeleteTexture(handle backend.TextureHandle) {
+	delete(r.cubemaps, handle)
+	delete(r.textures, handle)
+}
+
+// DrawSkybox draws a cube around the camera with every vertex on the far
+// plane, like the OpenGL backend. The vertex colors carry the view
+// direction, which shade turns into a cubemap lookup per pixel.
+func (r *Rasterizer) DrawSkybox(handle backend.TextureHandle) {
+	faces, exists := r.cubemaps[handle]
+	if !exists {
+		return
+	}
+
+	// Drop the camera translation so the sky stays at infinity
+	rotation := r.view
+	rotation[12], rotation[13], rotation[14] = 0, 0, 0
+	vp := rotation.Multiply(r.projection)
+
+	var corners [8]clipVertex
+	for i, c := range skyboxCorners {
+		pos := transform(vp, c[0], c[1], c[2])
+		pos[2] = pos[3]
+		corners[i] = clipVertex{pos: pos, color: [4]float32{c[0], c[1], c[2], 1}}
+	}
+
+	r.sky = &faces
+	depthWrite := r.depthWrite
+	r.depthWrite = false
+	for i := 0; i < len(skyboxIndices); i += 3 {
+		r.drawTriangle(corners[skyboxIndices[i]], corners[skyboxIndices[i+1]], corners[skyboxIndices[i+2]])
+	}
+	r.depthWrite = depthWrite
+	r.sky = nil
+}
+
+func sampleSky(faces *backend.CubemapFaces, rgba [4]float32) [4]float32 {
+	c := environment.Sample(*faces, bmath.NewVector3(rgba[0], rgba[1], rgba[2]))
+	return [4]float32{float32(c.R) / 255, float32(c.G) / 255, float32(c.B) / 255, 1}
+}
@@ -0,0 +1,118 @@
+package software
+
+import (
+	"image"
+	"image/draw"
+	"math"
+
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/backend"
+)
+
+// texture is a copy of a sprite image with its filtering mode
+type texture struct {
+	img           *image.RGBA
+	smooth        bool
+	distanceField bool
+}
+
+// CreateTexture copies the image into the rasterizer
+func (r *Rasterizer) CreateTexture(img *image.RGBA, smooth bool) backend.TextureHandle {
+	bounds := img.Bounds()
+	copied := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(copied, copied.Bounds(), img, bounds.Min, draw.Src)
+
+	handle := r.nextTexture
+	r.nextTexture++
+	r.textures[handle] = &texture{img: copied, smooth: smooth}
+	return handle
+}
+
+// CreateDistanceFieldTexture copies a signed distance field, sampled
+// bilinearly and thresholded at its edge when drawn
+func (r *Rasterizer) CreateDistanceFieldTexture(img *image.RGBA) backend.TextureHandle {
+	handle := r.CreateTexture(img, true)
+	r.textures[handle].distanceField = true
+	return handle
+}
+
+// UpdateTexture copies new pixels into a texture of the same size
+func (r *Rasterizer) UpdateTexture(handle backend.TextureHandle, img *image.RGBA) {
+	tex, exists := r.textures[handle]
+	if !exists || img.Bounds().Size() != tex.img.Rect.Size() {
+		return
+	}
+	draw.Draw(tex.img, tex.img.Rect, img, img.Bounds().Min, draw.Src)
+}
+
+// DrawSprites rasterizes each quad as two triangles with the depth test
+// off, like the OpenGL backend. Texture coordinates are interpolated with
+// the vertices and shade samples the texture per pixel.
+func (r *Rasterizer) DrawSprites(handle backend.TextureHandle, vertices []backend.SpriteVertex) {
+	tex, exists := r.textures[handle]
+	if !exists {
+		return
+	}
+
+	vp := r.view.Multiply(r.projection)
+	clip := func(v backend.SpriteVertex) clipVertex {
+		return clipVertex{pos: transform(vp, v.X, v.Y, v.Z), color: v.Color, uv: [2]float32{v.U, v.V}}
+	}
+
+	r.sprite = tex
+	depthTest := r.depthTest
+	r.depthTest = false
+	for i := 0; i+3 < len(vertices); i += 4 {
+		a, b, c, d := clip(vertices[i]), clip(vertices[i+1]), clip(vertices[i+2]), clip(vertices[i+3])
+		r.drawTriangle(a, b, c)
+		r.drawTriangle(c, d, a)
+	}
+	r.depthTest = depthTest
+	r.sprite = nil
+}
+
+// mask turns a distance field sample into coverage. Without screen-space
+// derivatives the edge is smoothed over a fixed band.
+func (t *texture) mask(distance float32) float32 {
+	const band = 0.06
+	x := min(max((distance-0.5+band)/(2*band), 0), 1)
+	return x * x * (3 - 2*x)
+}
+
+// sample reads the texture at a texture coordinate with clamp-to-edge
+// wrapping, returning normalized RGBA
+func (t *texture) sample(u, v float32) [4]float32 {
+	width, height := t.img.Rect.Dx(), t.img.Rect.Dy()
+	x := u*float32(width) - 0.5
+	y := v*float32(height) - 0.5
+
+	if !t.smooth {
+		return t.texel(int(math.Round(float64(x))), int(math.Round(float64(y))))
+	}
+
+	x0 := int(math.Floor(float64(x)))
+	y0 := int(math.Floor(float64(y)))
+	fx := x - float32(x0)
+	fy := y - float32(y0)
+
+	a, b := t.texel(x0, y0), t.texel(x0+1, y0)
+	c, d := t.texel(x0, y0+1), t.texel(x0+1, y0+1)
+	var out [4]float32
+	for i := range out {
+		top := a[i] + (b[i]-a[i])*fx
+		bottom := c[i] + (d[i]-c[i])*fx
+		out[i] = top + (bottom-top)*fy
+	}
+	return out
+}
+
+func (t *texture) texel(x, y int) [4]float32 {
+	x = min(max(x, 0), t.img.Rect.Dx()-1)
+	y = min(max(y, 0), t.img.Rect.Dy()-1)
+	pix := t.img.Pix[t.img.PixOffset(x, y):]
+	return [4]float32{
+		float32(pix[0]) / 255,
+		float32(pix[1]) / 255,
+		float32(pix[2]) / 255,
+		float32(pix[3]) / 255,
+	}
+}
@@ -0,0 +1,243 @@
+package software
+
+import (
+	"math"
+
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+)
+
+// clipVertex is a vertex after the model-view-projection transform
+type clipVertex struct {
+	pos   [4]float32
+	color [4]float32
+	uv    [2]float32
+}
+
+// screenVertex is a vertex after the perspective divide and viewport mapping
+type screenVertex struct {
+	x, y, z float32
+	invW    float32
+	color   [4]float32 // pre-divided by w for perspective-correct interpolation
+	uv      [2]float32 // pre-divided by w as well
+}
+
+const minClipW = 1e-5
+
+// clipPlanes are the clip-space half-spaces a vertex must lie in, as
+// dot(plane, pos) >= 0. The side planes are handled by the pixel bounds.
+var clipPlanes = [][4]float32{
+	{0, 0, 0, 1},  // w > 0 (offset by minClipW)
+	{0, 0, 1, 1},  // near: z >= -w
+	{0, 0, -1, 1}, // far: z <= w
+}
+
+func (r *Rasterizer) drawTriangle(a, b, c clipVertex) {
+	polygon := clipPolygon([]clipVertex{a, b, c})
+	if len(polygon) < 3 {
+		return
+	}
+
+	screen := make([]screenVertex, len(polygon))
+	for i, v := range polygon {
+		screen[i] = r.toScreen(v)
+	}
+
+	for i := 1; i+1 < len(screen); i++ {
+		r.rasterize(screen[0], screen[i], screen[i+1])
+	}
+}
+
+func clipPolygon(polygon []clipVertex) []clipVertex {
+	for i := range clipPlanes {
+		var out []clipVertex
+		for j := range polygon {
+			current := polygon[j]
+			next := polygon[(j+1)%len(polygon)]
+
+			dc := clipDistance(i, current.pos)
+			dn := clipDistance(i, next.pos)
+
+			if dc >= 0 {
+				out = append(out, current)
+			}
+			if (dc >= 0) != (dn >= 0) {
+				out = append(out, lerpVertex(current, next, dc/(dc-dn)))
+			}
+		}
+
+		polygon = out
+		if len(polygon) < 3 {
+			return nil
+		}
+	}
+	return polygon
+}
+
+// clipDistance returns the distance of pos inside clip plane i
+func clipDistance(i int, pos [4]float32) float32 {
+	plane := clipPlanes[i]
+	distance := plane[0]*pos[0] + plane[1]*pos[1] + plane[2]*pos[2] + plane[3]*pos[3]
+	if i == 0 {
+		distance -= minClipW
+	}
+	return distance
+}
+
+func lerpVertex(a, b clipVertex, t float32) clipVertex {
+	var v clipVertex
+	for i := range v.pos {
+		v.pos[i] = a.pos[i] + (b.pos[i]-a.pos[i])*t
+	}
+	for i := range v.color {
+		v.color[i] = a.color[i] + (b.color[i]-a.color[i])*t
+	}
+	for i := range v.uv {
+		v.uv[i] = a.uv[i] + (b.uv[i]-a.uv[i])*t
+	}
+	return v
+}
+
+func (r *Rasterizer) toScreen(v clipVertex) screenVertex {
+	invW := 1 / v.pos[3]
+	ndcX := v.pos[0] * invW
+	ndcY := v.pos[1] * invW
+	ndcZ := v.pos[2] * invW
+
+	return screenVertex{
+		x:    (ndcX + 1) * 0.5 * float32(r.width),
+		y:    (ndcY + 1) * 0.5 * float32(r.height),
+		z:    (ndcZ + 1) * 0.5,
+		invW: invW,
+		color: [4]float32{
+			v.color[0] * invW,
+			v.color[1] * invW,
+			v.color[2] * invW,
+			v.color[3] * invW,
+		},
+		uv: [2]float32{v.uv[0] * invW, v.uv[1] * invW},
+	}
+}
+
+// rasterize fills a screen-space triangle using edge functions and the
+// top-left fill rule, so shared edges are drawn exactly once.
+func (r *Rasterizer) rasterize(v0, v1, v2 screenVertex) {
+	area := edge(v0.x, v0.y, v1.x, v1.y, v2.x, v2.y)
+	if area == 0 {
+		return
+	}
+	// Faces are not culled; flip clockwise triangles to counter-clockwise
+	if area < 0 {
+		v1, v2 = v2, v1
+		area = -area
+	}
+
+	minX := int(math.Floor(float64(min3(v0.x, v1.x, v2.x))))
+	maxX := int(math.Ceil(float64(max3(v0.x, v1.x, v2.x))))
+	minY := int(math.Floor(float64(min3(v0.y, v1.y, v2.y))))
+	maxY := int(math.Ceil(float64(max3(v0.y, v1.y, v2.y))))
+
+	minX = max(minX, 0)
+	minY = max(minY, 0)
+	maxX = min(maxX, r.width-1)
+	maxY = min(maxY, r.height-1)
+
+	bias0 := fillBias(v1, v2)
+	bias1 := fillBias(v2, v0)
+	bias2 := fillBias(v0, v1)
+
+	for y := minY; y <= maxY; y++ {
+		py := float32(y) + 0.5
+		for x := minX; x <= maxX; x++ {
+			px := float32(x) + 0.5
+
+			w0 := edge(v1.x, v1.y, v2.x, v2.y, px, py)
+			w1 := edge(v2.x, v2.y, v0.x, v0.y, px, py)
+			w2 := edge(v0.x, v0.y, v1.x, v1.y, px, py)
+			if !inside(w0, bias0) || !inside(w1, bias1) || !inside(w2, bias2) {
+				continue
+			}
+
+			b0, b1, b2 := w0/area, w1/area, w2/area
+
+			// Window depth is affine in screen space
+			depth := b0*v0.z + b1*v1.z + b2*v2.z
+			// The image is stored top-down, OpenGL framebuffers bottom-up
+			row := r.height - 1 - y
+			index := row*r.width + x
+			// The skybox lies exactly on the far plane and passes where the
+			// depth buffer is still cleared, like a LEQUAL test
+			if r.sky != nil {
+				depth = 1
+			}
+			// Like OpenGL, disabling the depth test also disables depth writes
+			if r.depthTest {
+				if depth > r.depth[index] || (depth == r.depth[index] && r.sky == nil) {
+					continue
+				}
+				if r.depthWrite {
+					r.depth[index] = depth
+				}
+			}
+
+			invW := b0*v0.invW + b1*v1.invW + b2*v2.invW
+			var rgba [4]float32
+			for i := range rgba {
+				rgba[i] = (b0*v0.color[i] + b1*v1.color[i] + b2*v2.color[i]) / invW
+			}
+			var uv [2]float32
+			for i := range uv {
+				uv[i] = (b0*v0.uv[i] + b1*v1.uv[i] + b2*v2.uv[i]) / invW
+			}
+
+			r.blend(r.color.PixOffset(x, row), r.shade(rgba, uv))
+		}
+	}
+}
+
+// blend composites a color over the framebuffer with the same
+// SRC_ALPHA, ONE_MINUS_SRC_ALPHA function the OpenGL context enables, or
+// adds it when additive blending is on
+func (r *Rasterizer) blend(offset int, rgba [4]float32) {
+	pix := r.color.Pix[offset : offset+4]
+	if r.additive {
+		for i := range pix {
+			pix[i] = toByte(float32(pix[i])/255 + rgba[i])
+		}
+		return
+	}
+	alpha := bmath.Clamp(rgba[3], 0, 1)
+	for i := 0; i < 3; i++ {
+		dst := float32(pix[i]) / 255
+		pix[i] = toByte(rgba[i]*alpha + dst*(1-alpha))
+	}
+	dstAlpha := float32(pix[3]) / 255
+	pix[3] = toByte(alpha + dstAlpha*(1-alpha))
+}
+
+// edge returns twice the signed area of the triangle (a, b, p)
+func edge(ax, ay, bx, by, px, py float32) float32 {
+	return (bx-ax)*(py-ay) - (by-ay)*(px-ax)
+}
+
+// fillBias reports whether pixels exactly on the edge from a to b belong to
+// the triangle. For counter-clockwise triangles in a y-up space, top edges
+// are horizontal and point left, left edges point down.
+func fillBias(a, b screenVertex) bool {
+	dx := b.x - a.x
+	dy := b.y - a.y
+	top := dy == 0 && dx < 0
+	left := dy < 0
+	return top || left
+}
+
+func inside(w float32, onEdge bool) bool {
+	return w > 0 || (w == 0 && onEdge)
+}
+
+func min3(a, b, c float32) float32 {
+	return min(a, min(b, c))
+}
+
+func max3(a, b, c float32) float32 {
+	return max(a, max(b, c))
+}
@@ -0,0 +1,122 @@
+package software
+
+import (
+	"math"
+
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/backend"
+)
+
+// wireDepthBias pulls wireframe edges towards the camera so they win the
+// depth test against the surface they outline, like glPolygonOffset
+const wireDepthBias = 1e-4
+
+// SetViewMode changes how subsequent mesh draws are shaded
+func (r *Rasterizer) SetViewMode(mode backend.ViewMode) {
+	r.viewMode = mode
+}
+
+// drawTriangleViewMode draws one triangle of a mesh in a debug view mode.
+// The modes that need per-pixel data encode it in the vertex color, which
+// is interpolated perspective-correctly.
+func (r *Rasterizer) drawTriangleViewMode(m *mesh, model bmath.Matrix4, indices [3]uint32, v [3]clipVertex) {
+	modelView := model.Multiply(r.view)
+	var object, world, eye [3]bmath.Vector3
+	for i, index := range indices {
+		p := m.vertices[index*6 : index*6+3]
+		object[i] = bmath.NewVector3(p[0], p[1], p[2])
+		w := transform(model, p[0], p[1], p[2])
+		world[i] = bmath.NewVector3(w[0], w[1], w[2])
+		e := transform(modelView, p[0], p[1], p[2])
+		eye[i] = bmath.NewVector3(e[0], e[1], e[2])
+	}
+
+	switch r.viewMode {
+	case backend.ViewWireframe:
+		r.drawEdges(v, 0)
+	case backend.ViewWireframeShaded:
+		r.drawTriangle(v[0], v[1], v[2])
+		for i := range v {
+			v[i].color = backend.WireframeColor
+		}
+		r.drawEdges(v, wireDepthBias)
+	case backend.ViewNormals:
+		// Show the side facing the camera whatever the winding, as the
+		// screen-space derivatives of the OpenGL shader do
+		normal := faceNormal(world)
+		if faceNormal(eye).Dot(eye[0]) > 0 {
+			normal = normal.Mul(-1)
+		}
+		color := [4]float32{normal.X*0.5 + 0.5, normal.Y*0.5 + 0.5, normal.Z*0.5 + 0.5, 1}
+		r.drawTriangle(withColor(v[0], color), withColor(v[1], color), withColor(v[2], color))
+	case backend.ViewUVChecker:
+		// Box-project along the dominant axis of the object-space normal;
+		// the rasterizer turns the interpolated coordinates into cells
+		n := faceNormal(object)
+		for i, p := range object {
+			u, w := p.X, p.Y
+			if abs(n.X) > abs(n.Y) && abs(n.X) > abs(n.Z) {
+				u, w = p.Z, p.Y
+			} else if abs(n.Y) > abs(n.Z) {
+				u, w = p.X, p.Z
+			}
+			v[i].color = [4]float32{u, w, 0, 1}
+		}
+		r.drawTriangle(v[0], v[1], v[2])
+	case backend.ViewDepth:
+		near, far := backend.NearFar(r.projection)
+		for i, p := range eye {
+			shade := 1 - (-p.Z-near)/(far-near)
+			v[i].color = [4]float32{shade, shade, shade, 1}
+		}
+		r.drawTriangle(v[0], v[1], v[2])
+	case backend.ViewOverdraw:
+		for i := range v {
+			v[i].color = backend.OverdrawColor
+		}
+		r.depthTest, r.additive = false, true
+		r.drawTriangle(v[0], v[1], v[2])
+		r.depthTest, r.additive = true, false
+	}
+}
+
+func (r *Rasterizer) drawEdges(v [3]clipVertex, bias float32) {
+	r.depthBias = bias
+	r.drawLine(v[0], v[1], true)
+	r.drawLine(v[1], v[2], true)
+	r.drawLine(v[2], v[0], true)
+	r.depthBias = 0
+}
+
+// shade post-processes an interpolated fragment color for the skybox and
+// the view modes that encode per-pixel data in it
+func (r *Rasterizer) shade(rgba [4]float32, uv [2]float32) [4]float32 {
+	if r.sky != nil {
+		return sampleSky(r.sky, rgba)
+	}
+	if r.sprite != nil {
+		texel := r.sprite.sample(uv[0], uv[1])
+		if r.sprite.distanceField {
+			return [4]float32{rgba[0], rgba[1], rgba[2], rgba[3] * r.sprite.mask(texel[3])}
+		}
+		return [4]float32{texel[0] * rgba[0], texel[1] * rgba[1], texel[2] * rgba[2], texel[3] * rgba[3]}
+	}
+	if r.viewMode != backend.ViewUVChecker {
+		return rgba
+	}
+	cell := math.Mod(math.Floor(float64(rgba[0]*4))+math.Floor(float64(rgba[1]*4)), 2)
+	shade := float32(0.2)
+	if cell != 0 {
+		shade = 0.9
+	}
+	return [4]float32{shade, shade, shade, 1}
+}
+
+func faceNormal(p [3]bmath.Vector3) bmath.Vector3 {
+	return p[1].Sub(p[0]).Cross(p[2].Sub(p[0])).Normalize()
+}
+
+func withColor(v clipVertex, color [4]float32) clipVertex {
+	v.color = color
+	return v
+}
@@ -0,0 +1,187 @@
+// Package sprite draws 2D textured quads. Sprites are collected between
+// Begin and End, sorted by layer and texture, and submitted to the backend
+// with one draw call per run of sprites sharing a texture.
+package sprite
+
+import (
+	"image"
+	"math"
+	"sort"
+
+	"github.com/javanhut/BifrostEngine/m/v2/camera"
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/backend"
+)
+
+// Sprite is a textured quad in world space; y points up
+type Sprite struct {
+	Texture Texture
+	// Source is the region of the texture in pixels; the zero rectangle
+	// uses the whole texture
+	Source   image.Rectangle
+	Position bmath.Vector2
+	// Size in world units; zero uses the source size in pixels
+	Size bmath.Vector2
+	// Origin is the pivot for position and rotation as a fraction of the
+	// size: (0, 0) is the bottom-left corner, (0.5, 0.5) the center
+	Origin bmath.Vector2
+	// Rotation is counter-clockwise, in radians
+	Rotation     float32
+	Tint         [4]float32
+	FlipX, FlipY bool
+	// Layer orders sprites; lower layers are drawn first
+	Layer int
+}
+
+// NewSprite creates a sprite showing a whole texture at its pixel size,
+// centered on the origin and untinted
+func NewSprite(texture Texture) Sprite {
+	return Sprite{
+		Texture: texture,
+		Origin:  bmath.NewVector2(0.5, 0.5),
+		Tint:    [4]float32{1, 1, 1, 1},
+	}
+}
+
+// Stats describes the last End
+type Stats struct {
+	Sprites   int
+	DrawCalls int
+}
+
+// SpriteBatch collects sprites for a frame and draws them in as few calls
+// as possible. Within a layer sprites with the same texture keep the order
+// they were drawn in.
+type SpriteBatch struct {
+	backend    backend.Backend
+	sprites    []Sprite
+	vertices   []backend.SpriteVertex
+	view       bmath.Matrix4
+	projection bmath.Matrix4
+	drawing    bool
+	stats      Stats
+}
+
+// NewSpriteBatch creates a batch drawing through a backend
+func NewSpriteBatch(b backend.Backend) *SpriteBatch {
+	return &SpriteBatch{
+		backend:    b,
+		view:       bmath.NewMatrix4Identity(),
+		projection: bmath.NewMatrix4Identity(),
+	}
+}
+
+// Begin starts collecting sprites seen through view and projection, given
+// in the GPU layout like backend.Backend.SetCamera
+func (sb *SpriteBatch) Begin(view, projection bmath.Matrix4) {
+	sb.view = view
+	sb.projection = projection
+	sb.sprites = sb.sprites[:0]
+	sb.drawing = true
+}
+
+// BeginCamera2D starts collecting sprites seen through a 2D camera
+func (sb *SpriteBatch) BeginCamera2D(cam *camera.Camera2D) {
+	// Camera2D matrices are row-major
+	sb.Begin(cam.GetViewMatrix().Transpose(), cam.GetProjectionMatrix().Transpose())
+}
+
+// Draw queues a sprite; it is drawn by End
+func (sb *SpriteBatch) Draw(s Sprite) {
+	if !sb.drawing || !s.Texture.Valid() {
+		return
+	}
+	sb.sprites = append(sb.sprites, s)
+}
+
+// End sorts the queued sprites and draws them
+func (sb *SpriteBatch) End() Stats {
+	sb.drawing = false
+	sb.stats = Stats{Sprites: len(sb.sprites)}
+	if len(sb.sprites) == 0 {
+		return sb.stats
+	}
+
+	sort.SliceStable(sb.sprites, func(i, j int) bool {
+		a, b := &sb.sprites[i], &sb.sprites[j]
+		if a.Layer != b.Layer {
+			return a.Layer < b.Layer
+		}
+		return a.Texture.Handle < b.Texture.Handle
+	})
+
+	sb.backend.SetCamera(sb.view, sb.projection)
+	start := 0
+	for i := 1; i <= len(sb.sprites); i++ {
+		if i < len(sb.sprites) && sb.sprites[i].Texture.Handle == sb.sprites[start].Texture.Handle {
+			continue
+		}
+		sb.flush(sb.sprites[start:i])
+		start = i
+	}
+
+	sb.sprites = sb.sprites[:0]
+	return sb.stats
+}
+
+// GetStats returns the statistics of the last End
+func (sb *SpriteBatch) GetStats() Stats {
+	return sb.stats
+}
+
+func (sb *SpriteBatch) flush(sprites []Sprite) {
+	sb.vertices = sb.vertices[:0]
+	for i := range sprites {
+		sb.vertices = appendQuad(sb.vertices, &sprites[i])
+	}
+	sb.backend.DrawSprites(sprites[0].Texture.Handle, sb.vertices)
+	sb.stats.DrawCalls++
+}
+
+// appendQuad adds the corners of a sprite counter-clockwise from the
+// bottom-left
+func appendQuad(vertices []backend.SpriteVertex, s *Sprite) []backend.SpriteVertex {
+	source := s.Source
+	if source.Empty() {
+		source = image.Rect(0, 0, s.Texture.Width, s.Texture.Height)
+	}
+
+	size := s.Size
+	if size.X == 0 && size.Y == 0 {
+		size = bmath.NewVector2(float32(source.Dx()), float32(source.Dy()))
+	}
+
+	// Texture coordinates start at the top-left of the image, so the top
+	// of the quad samples the source's minimum row
+	width, height := float32(s.Texture.Width), float32(s.Texture.Height)
+	u0, u1 := float32(source.Min.X)/width, float32(source.Max.X)/width
+	vTop, vBottom := float32(source.Min.Y)/height, float32(source.Max.Y)/height
+	if s.FlipX {
+		u0, u1 = u1, u0
+	}
+	if s.FlipY {
+		vTop, vBottom = vBottom, vTop
+	}
+
+	sin, cos := math.Sincos(float64(s.Rotation))
+	sinR, cosR := float32(sin), float32(cos)
+	left := -s.Origin.X * size.X
+	bottom := -s.Origin.Y * size.Y
+
+	corners := [4]struct{ x, y, u, v float32 }{
+		{left, bottom, u0, vBottom},
+		{left + size.X, bottom, u1, vBottom},
+		{left + size.X, bottom + size.Y, u1, vTop},
+		{left, bottom + size.Y, u0, vTop},
+	}
+	for _, c := range corners {
+		vertices = append(vertices, backend.SpriteVertex{
+			X:     s.Position.X + c.x*cosR - c.y*sinR,
+			Y:     s.Position.Y + c.x*sinR + c.y*cosR,
+			U:     c.u,
+			V:     c.v,
+			Color: s.Tint,
+		})
+	}
+	return vertices
+}
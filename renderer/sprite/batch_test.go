@@ -0,0 +1,142 @@
+package sprite
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/backend"
+)
+
+// recorder logs the calls End makes. Any other backend call panics on the
+// nil embedded interface.
+type recorder struct {
+	backend.Backend
+	calls []string
+}
+
+func (r *recorder) SetCamera(view, projection bmath.Matrix4) {
+	r.calls = append(r.calls, fmt.Sprintf("camera %g", view[12]))
+}
+
+func (r *recorder) DrawSprites(texture backend.TextureHandle, vertices []backend.SpriteVertex) {
+	// Each quad starts at its sprite's position, which names the sprite
+	call := fmt.Sprintf("texture %d:", texture)
+	for i := 0; i < len(vertices); i += 4 {
+		call += fmt.Sprintf(" %g", vertices[i].X)
+	}
+	r.calls = append(r.calls, call)
+}
+
+var textures = map[backend.TextureHandle]Texture{
+	1: {Handle: 1, Width: 8, Height: 8},
+	2: {Handle: 2, Width: 8, Height: 8},
+	3: {Handle: 3, Width: 16, Height: 4},
+}
+
+// sprite is drawn from its position with id as the x coordinate
+func sprite(id float32, texture backend.TextureHandle, layer int) Sprite {
+	s := NewSprite(textures[texture])
+	s.Position = bmath.NewVector2(id, 0)
+	s.Origin = bmath.Vector2{}
+	s.Layer = layer
+	return s
+}
+
+func TestEnd(t *testing.T) {
+	tests := []struct {
+		name    string
+		sprites []Sprite
+		want    []string
+		stats   Stats
+	}{
+		{
+			name:  "empty",
+			want:  nil,
+			stats: Stats{},
+		},
+		{
+			name:    "one texture in one call",
+			sprites: []Sprite{sprite(1, 1, 0), sprite(2, 1, 0), sprite(3, 1, 0)},
+			want:    []string{"camera 7", "texture 1: 1 2 3"},
+			stats:   Stats{Sprites: 3, DrawCalls: 1},
+		},
+		{
+			name:    "layers in order",
+			sprites: []Sprite{sprite(1, 2, 5), sprite(2, 1, -1), sprite(3, 3, 0)},
+			want:    []string{"camera 7", "texture 1: 2", "texture 3: 3", "texture 2: 1"},
+			stats:   Stats{Sprites: 3, DrawCalls: 3},
+		},
+		{
+			// Layers keep their order inside a batch running across them
+			name:    "one texture across layers",
+			sprites: []Sprite{sprite(1, 1, 5), sprite(2, 1, -1), sprite(3, 1, 0)},
+			want:    []string{"camera 7", "texture 1: 2 3 1"},
+			stats:   Stats{Sprites: 3, DrawCalls: 1},
+		},
+		{
+			// Textures within a layer are grouped, each keeping draw order
+			name: "batched by texture",
+			sprites: []Sprite{
+				sprite(1, 2, 0), sprite(2, 1, 0), sprite(3, 2, 0),
+				sprite(4, 3, 0), sprite(5, 1, 0), sprite(6, 2, 0),
+			},
+			want:  []string{"camera 7", "texture 1: 2 5", "texture 2: 1 3 6", "texture 3: 4"},
+			stats: Stats{Sprites: 6, DrawCalls: 3},
+		},
+		{
+			name: "batches split by layer",
+			sprites: []Sprite{
+				sprite(1, 1, 1), sprite(2, 2, 0), sprite(3, 1, 0),
+				sprite(4, 2, 1), sprite(5, 1, 1), sprite(6, 1, 0),
+			},
+			want:  []string{"camera 7", "texture 1: 3 6", "texture 2: 2", "texture 1: 1 5", "texture 2: 4"},
+			stats: Stats{Sprites: 6, DrawCalls: 4},
+		},
+		{
+			name:    "invalid textures skipped",
+			sprites: []Sprite{sprite(1, 0, 0), sprite(2, 1, 0)},
+			want:    []string{"camera 7", "texture 1: 2"},
+			stats:   Stats{Sprites: 1, DrawCalls: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{}
+			sb := NewSpriteBatch(r)
+			view := bmath.NewTranslationMatrix(7, 0, 0).Transpose()
+			sb.Begin(view, bmath.NewMatrix4Identity())
+			for _, s := range tt.sprites {
+				sb.Draw(s)
+			}
+			stats := sb.End()
+
+			if !reflect.DeepEqual(r.calls, tt.want) {
+				t.Errorf("calls = %q, want %q", r.calls, tt.want)
+			}
+			if stats != tt.stats || sb.GetStats() != tt.stats {
+				t.Errorf("stats = %+v, want %+v", stats, tt.stats)
+			}
+		})
+	}
+}
+
+func TestDrawOutsideBegin(t *testing.T) {
+	r := &recorder{}
+	sb := NewSpriteBatch(r)
+	sb.Draw(sprite(1, 1, 0))
+	sb.Begin(bmath.NewMatrix4Identity(), bmath.NewMatrix4Identity())
+	sb.Draw(sprite(2, 1, 0))
+	sb.End()
+	sb.Draw(sprite(3, 1, 0))
+	if stats := sb.End(); stats.Sprites != 0 {
+		t.Errorf("%d sprites drawn after End", stats.Sprites)
+	}
+
+	want := []string{"camera 0", "texture 1: 2"}
+	if !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %q, want %q", r.calls, want)
+	}
+}
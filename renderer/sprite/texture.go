@@ -0,0 +1,101 @@
+package sprite
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/backend"
+)
+
+// Texture is a backend texture together with its size in pixels, which
+// sprites need to turn source rectangles into texture coordinates
+type Texture struct {
+	Handle backend.TextureHandle
+	Width  int
+	Height int
+}
+
+// Valid reports whether the texture refers to a backend texture
+func (t Texture) Valid() bool {
+	return t.Handle != backend.InvalidTexture && t.Width > 0 && t.Height > 0
+}
+
+// NewTexture uploads an image to the backend
+func NewTexture(b backend.Backend, img image.Image, smooth bool) Texture {
+	rgba, ok := img.(*image.RGBA)
+	if !ok {
+		bounds := img.Bounds()
+		rgba = image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	}
+	bounds := rgba.Bounds()
+	return Texture{
+		Handle: b.CreateTexture(rgba, smooth),
+		Width:  bounds.Dx(),
+		Height: bounds.Dy(),
+	}
+}
+
+// LoadTexture decodes a PNG or JPEG file and uploads it. Pixel art should
+// pass smooth as false to keep hard texel edges.
+func LoadTexture(b backend.Backend, path string, smooth bool) (Texture, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Texture{}, fmt.Errorf("failed to open texture: %w", err)
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return Texture{}, fmt.Errorf("failed to decode texture %s: %w", path, err)
+	}
+	return NewTexture(b, img, smooth), nil
+}
+
+type cachedTexture struct {
+	texture Texture
+	err     error
+}
+
+// TextureCache loads each texture file once and keeps it on the backend
+// until Cleanup. Failed loads are remembered so a missing file is not
+// retried every frame.
+type TextureCache struct {
+	backend  backend.Backend
+	smooth   bool
+	textures map[string]cachedTexture
+}
+
+// NewTextureCache creates a cache uploading textures with the given filtering
+func NewTextureCache(b backend.Backend, smooth bool) *TextureCache {
+	return &TextureCache{
+		backend:  b,
+		smooth:   smooth,
+		textures: make(map[string]cachedTexture),
+	}
+}
+
+// Get returns the texture for a file, loading it on first use
+func (c *TextureCache) Get(path string) (Texture, error) {
+	if cached, exists := c.textures[path]; exists {
+		return cached.texture, cached.err
+	}
+
+	texture, err := LoadTexture(c.backend, path, c.smooth)
+	c.textures[path] = cachedTexture{texture: texture, err: err}
+	return texture, err
+}
+
+// Cleanup deletes every loaded texture from the backend
+func (c *TextureCache) Cleanup() {
+	for _, cached := range c.textures {
+		if cached.err == nil {
+			c.backend.DeleteTexture(cached.texture.Handle)
+		}
+	}
+	c.textures = make(map[string]cachedTexture)
+}
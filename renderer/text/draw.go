@@ -0,0 +1,125 @@
+package text
+
+import (
+	"math"
+
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/backend"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/sprite"
+)
+
+// DrawOptions places and styles text drawn through a sprite batch
+type DrawOptions struct {
+	Position bmath.Vector2
+	// Origin is the pivot for position and rotation as a fraction of the
+	// text block: (0, 0) is the bottom-left corner, (0, 1) the top-left
+	Origin bmath.Vector2
+	// Scale converts face pixels to world units
+	Scale float32
+	// Rotation is counter-clockwise, in radians
+	Rotation float32
+	Color    [4]float32
+	// MaxWidth wraps lines longer than it, in face pixels; zero disables
+	// wrapping
+	MaxWidth float32
+	Align    Align
+	Layer    int
+}
+
+// DefaultDrawOptions returns white text at face size, hanging from its
+// top-left corner
+func DefaultDrawOptions() DrawOptions {
+	return DrawOptions{
+		Origin: bmath.NewVector2(0, 1),
+		Scale:  1,
+		Color:  [4]float32{1, 1, 1, 1},
+	}
+}
+
+// Drawer draws a face's text as sprites, keeping a backend texture for
+// every atlas page up to date
+type Drawer struct {
+	backend  backend.Backend
+	face     *Face
+	textures []sprite.Texture
+	versions []int
+}
+
+// NewDrawer creates a drawer for a face
+func NewDrawer(b backend.Backend, face *Face) *Drawer {
+	return &Drawer{backend: b, face: face}
+}
+
+// Face returns the face the drawer draws with
+func (d *Drawer) Face() *Face {
+	return d.face
+}
+
+// Draw lays out text and queues its glyphs on a batch that has begun
+func (d *Drawer) Draw(batch *sprite.SpriteBatch, s string, options DrawOptions) *Layout {
+	layout := d.face.Layout(s, options.MaxWidth, options.Align)
+	d.upload()
+
+	scale := options.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	sin, cos := math.Sincos(float64(options.Rotation))
+	sinR, cosR := float32(sin), float32(cos)
+	// Glyph corners are measured from the block's top-left; the pivot
+	// moves them so it lands on the position
+	pivotX := options.Origin.X * layout.Width
+	pivotY := (1 - options.Origin.Y) * layout.Height
+
+	for _, g := range layout.Glyphs {
+		quad := sprite.NewSprite(d.textures[g.Page])
+		quad.Source = g.Source
+		quad.Size = bmath.NewVector2(float32(g.Source.Dx())*scale, float32(g.Source.Dy())*scale)
+		quad.Origin = bmath.NewVector2(0, 0)
+		// The bottom-left corner of the glyph, y up from the pivot
+		x := (g.X - pivotX) * scale
+		y := (pivotY - g.Y - float32(g.Source.Dy())) * scale
+		quad.Position = bmath.NewVector2(
+			options.Position.X+x*cosR-y*sinR,
+			options.Position.Y+x*sinR+y*cosR,
+		)
+		quad.Rotation = options.Rotation
+		quad.Tint = options.Color
+		quad.Layer = options.Layer
+		batch.Draw(quad)
+	}
+	return layout
+}
+
+// upload creates textures for new pages and refreshes changed ones. Pages
+// keep their size and glyphs never move, so sprites queued earlier in the
+// frame stay valid.
+func (d *Drawer) upload() {
+	for i, page := range d.face.Pages() {
+		if i == len(d.textures) {
+			var handle backend.TextureHandle
+			if d.face.DistanceField() {
+				handle = d.backend.CreateDistanceFieldTexture(page.Image)
+			} else {
+				handle = d.backend.CreateTexture(page.Image, true)
+			}
+			bounds := page.Image.Bounds()
+			d.textures = append(d.textures, sprite.Texture{Handle: handle, Width: bounds.Dx(), Height: bounds.Dy()})
+			d.versions = append(d.versions, page.Version)
+			continue
+		}
+		if d.versions[i] != page.Version {
+			d.backend.UpdateTexture(d.textures[i].Handle, page.Image)
+			d.versions[i] = page.Version
+		}
+	}
+}
+
+// Cleanup deletes the page textures
+func (d *Drawer) Cleanup() {
+	for _, texture := range d.textures {
+		d.backend.DeleteTexture(texture.Handle)
+	}
+	d.textures = nil
+	d.versions = nil
+}
@@ -0,0 +1,232 @@
+package text
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/atlas"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Options controls how a face rasterizes glyphs
+type Options struct {
+	// Size is the em size in pixels
+	Size float32
+	// DistanceField stores glyphs as signed distance fields, which keep
+	// sharp edges when text is drawn larger or smaller than Size
+	DistanceField bool
+	// Spread is how many pixels around an edge a distance field covers
+	Spread int
+	// PageSize is the width and height of each atlas page
+	PageSize int
+}
+
+// DefaultOptions returns coverage glyphs at a given size
+func DefaultOptions(size float32) Options {
+	return Options{Size: size, Spread: 4, PageSize: 512}
+}
+
+// Page is one atlas image. Glyphs are only ever added to a page, so a
+// changed Version means the image must be uploaded again, but regions
+// handed out earlier stay valid.
+type Page struct {
+	Image   *image.RGBA
+	Version int
+	packer  *atlas.Packer
+}
+
+// glyph is a rasterized rune
+type glyph struct {
+	page    int
+	region  image.Rectangle // In the page; empty for blank glyphs like space
+	offset  image.Point     // Top-left of the region from the pen on the baseline, y down
+	advance float32
+}
+
+// Face is a font at one size with its glyph atlas
+type Face struct {
+	font       *Font
+	face       font.Face
+	options    Options
+	ascent     float32
+	descent    float32
+	lineHeight float32
+	glyphs     map[rune]*glyph
+	pages      []*Page
+}
+
+// glyphPadding keeps filtering from sampling neighbouring glyphs
+const glyphPadding = 1
+
+// NewFace creates a face of a font
+func NewFace(f *Font, options Options) (*Face, error) {
+	if options.Size <= 0 {
+		return nil, fmt.Errorf("invalid font size %v", options.Size)
+	}
+	if options.Spread <= 0 {
+		options.Spread = DefaultOptions(options.Size).Spread
+	}
+	// A page must hold at least the largest glyph
+	largest := int(math.Ceil(float64(options.Size)))*2 + 2*(options.Spread+glyphPadding)
+	options.PageSize = max(options.PageSize, DefaultOptions(options.Size).PageSize, largest)
+
+	face, err := opentype.NewFace(f.sfnt, &opentype.FaceOptions{
+		Size:    float64(options.Size),
+		DPI:     72, // One point per pixel
+		Hinting: font.HintingNone,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %v px face of %s: %w", options.Size, f.name, err)
+	}
+
+	metrics := face.Metrics()
+	return &Face{
+		font:       f,
+		face:       face,
+		options:    options,
+		ascent:     fixedToFloat(metrics.Ascent),
+		descent:    fixedToFloat(metrics.Descent),
+		lineHeight: fixedToFloat(metrics.Height),
+		glyphs:     make(map[rune]*glyph),
+	}, nil
+}
+
+// Font returns the font the face was made from
+func (f *Face) Font() *Font {
+	return f.font
+}
+
+// Size returns the em size in pixels
+func (f *Face) Size() float32 {
+	return f.options.Size
+}
+
+// DistanceField reports whether the atlas pages hold distance fields
+func (f *Face) DistanceField() bool {
+	return f.options.DistanceField
+}
+
+// Ascent returns the height above the baseline of the tallest glyphs
+func (f *Face) Ascent() float32 {
+	return f.ascent
+}
+
+// Descent returns the depth below the baseline of the lowest glyphs
+func (f *Face) Descent() float32 {
+	return f.descent
+}
+
+// LineHeight returns the distance between baselines
+func (f *Face) LineHeight() float32 {
+	return f.lineHeight
+}
+
+// Pages returns the atlas pages. A page's image is white, with the glyph
+// coverage or distance in alpha.
+func (f *Face) Pages() []*Page {
+	return f.pages
+}
+
+// Kern returns the adjustment between two runes drawn next to each other
+func (f *Face) Kern(a, b rune) float32 {
+	return fixedToFloat(f.face.Kern(f.resolve(a), f.resolve(b)))
+}
+
+// Advance returns how far a rune moves the pen
+func (f *Face) Advance(r rune) float32 {
+	return f.glyph(r).advance
+}
+
+// Preload rasterizes the glyphs of a string ahead of drawing, such as the
+// printable ASCII range for a UI
+func (f *Face) Preload(s string) {
+	for _, r := range s {
+		f.glyph(r)
+	}
+}
+
+// resolve substitutes a question mark for runes the font does not have
+func (f *Face) resolve(r rune) rune {
+	if r == ' ' || f.font.HasGlyph(r) {
+		return r
+	}
+	return '?'
+}
+
+// glyph returns a rune's glyph, rasterizing it on first use
+func (f *Face) glyph(r rune) *glyph {
+	if g, exists := f.glyphs[r]; exists {
+		return g
+	}
+
+	dr, mask, maskp, advance, ok := f.face.Glyph(fixed.Point26_6{}, f.resolve(r))
+	g := &glyph{advance: fixedToFloat(advance)}
+	f.glyphs[r] = g
+	if !ok || dr.Empty() {
+		return g
+	}
+
+	// Copy the mask out of the face's shared buffer, with room around it
+	// for the distance field to fade out
+	margin := 0
+	if f.options.DistanceField {
+		margin = f.options.Spread
+	}
+	alpha := image.NewAlpha(image.Rect(0, 0, dr.Dx()+2*margin, dr.Dy()+2*margin))
+	draw.Draw(alpha, alpha.Rect.Inset(margin), mask, maskp, draw.Src)
+	if f.options.DistanceField {
+		alpha = distanceField(alpha, f.options.Spread)
+	}
+
+	g.page, g.region = f.place(alpha)
+	g.offset = dr.Min.Sub(image.Pt(margin, margin))
+	return g
+}
+
+// place copies a glyph into the first page with room for it, opening a new
+// page when all are full
+func (f *Face) place(alpha *image.Alpha) (int, image.Rectangle) {
+	size := alpha.Rect.Size()
+	for i := 0; ; i++ {
+		fresh := i == len(f.pages)
+		if fresh {
+			pageSize := f.options.PageSize
+			f.pages = append(f.pages, &Page{
+				Image:  image.NewRGBA(image.Rect(0, 0, pageSize, pageSize)),
+				packer: atlas.NewPacker(pageSize, pageSize),
+			})
+		}
+		page := f.pages[i]
+		rect, ok := page.packer.Insert(size.X+glyphPadding, size.Y+glyphPadding)
+		if !ok {
+			if fresh {
+				// Too large for an empty page, so it is left blank
+				return i, image.Rectangle{}
+			}
+			continue
+		}
+
+		region := image.Rectangle{Min: rect.Min, Max: rect.Min.Add(size)}
+		// Glyphs are white with their shape in alpha, so tinting the
+		// texture colours them
+		for y := 0; y < size.Y; y++ {
+			for x := 0; x < size.X; x++ {
+				offset := page.Image.PixOffset(region.Min.X+x, region.Min.Y+y)
+				page.Image.Pix[offset+0] = 0xff
+				page.Image.Pix[offset+1] = 0xff
+				page.Image.Pix[offset+2] = 0xff
+				page.Image.Pix[offset+3] = alpha.AlphaAt(x, y).A
+			}
+		}
+		page.Version++
+		return i, region
+	}
+}
+
+func fixedToFloat(v fixed.Int26_6) float32 {
+	return float32(v) / 64
+}
@@ -0,0 +1,89 @@
+// Package text renders TrueType and OpenType fonts. Glyphs are rasterized
+// on first use into atlas pages, either as coverage masks or as signed
+// distance fields that stay sharp when scaled. Faces lay out UTF-8 text
+// with kerning, line breaks, word wrapping and alignment; a Drawer turns
+// the layout into sprites for a sprite batch.
+package text
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+)
+
+// Font is a parsed font file, shared by the faces made from it
+type Font struct {
+	sfnt *sfnt.Font
+	name string
+	buf  sfnt.Buffer
+}
+
+// ParseFont reads a TrueType or OpenType font from memory
+func ParseFont(data []byte) (*Font, error) {
+	parsed, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font: %w", err)
+	}
+	f := &Font{sfnt: parsed}
+	if name, err := parsed.Name(&f.buf, sfnt.NameIDFull); err == nil {
+		f.name = name
+	}
+	return f, nil
+}
+
+// LoadFont reads a .ttf or .otf file
+func LoadFont(path string) (*Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read font: %w", err)
+	}
+	f, err := ParseFont(data)
+	if err != nil {
+		return nil, fmt.Errorf("font %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// Name returns the font's full name, such as "Go Regular"
+func (f *Font) Name() string {
+	return f.name
+}
+
+// HasGlyph reports whether the font has a glyph for a rune
+func (f *Font) HasGlyph(r rune) bool {
+	index, err := f.sfnt.GlyphIndex(&f.buf, r)
+	return err == nil && index != 0
+}
+
+var (
+	defaultOnce sync.Once
+	defaultFont *Font
+	monoFont    *Font
+)
+
+func loadBuiltin() {
+	var err error
+	if defaultFont, err = ParseFont(goregular.TTF); err != nil {
+		panic(err)
+	}
+	if monoFont, err = ParseFont(gomono.TTF); err != nil {
+		panic(err)
+	}
+}
+
+// DefaultFont returns Go Regular, which is built in and needs no files
+func DefaultFont() *Font {
+	defaultOnce.Do(loadBuiltin)
+	return defaultFont
+}
+
+// MonoFont returns the built-in monospaced Go Mono
+func MonoFont() *Font {
+	defaultOnce.Do(loadBuiltin)
+	return monoFont
+}
@@ -0,0 +1,172 @@
+package text
+
+import (
+	"image"
+	"math"
+	"strings"
+)
+
+// Align places lines within the width of a laid out block
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+// Glyph is a glyph placed by Layout. X and Y are its top-left corner in
+// pixels from the top-left of the block, with y pointing down.
+type Glyph struct {
+	Rune   rune
+	Page   int
+	Source image.Rectangle // Region in the atlas page
+	X      float32
+	Y      float32
+}
+
+// Line is a laid out line of text
+type Line struct {
+	Text     string
+	Width    float32
+	Baseline float32 // From the top of the block
+}
+
+// Layout is text broken into lines with its glyphs in place
+type Layout struct {
+	Glyphs []Glyph
+	Lines  []Line
+	Width  float32
+	Height float32
+}
+
+// Layout places a UTF-8 string. Lines break at newlines and, when maxWidth
+// is positive, wrap between words to fit within it; words longer than a
+// line are split between characters.
+func (f *Face) Layout(s string, maxWidth float32, align Align) *Layout {
+	layout := &Layout{}
+	for i, line := range f.wrap(s, maxWidth) {
+		layout.Lines = append(layout.Lines, Line{
+			Text:     string(line),
+			Width:    f.width(line),
+			Baseline: f.ascent + float32(i)*f.lineHeight,
+		})
+		layout.Width = max(layout.Width, layout.Lines[i].Width)
+	}
+	layout.Height = float32(len(layout.Lines)) * f.lineHeight
+
+	// Alignment is within the wrap width when there is one, and within the
+	// widest line otherwise
+	blockWidth := layout.Width
+	if maxWidth > 0 {
+		blockWidth = maxWidth
+	}
+	for _, line := range layout.Lines {
+		pen := float32(0)
+		switch align {
+		case AlignCenter:
+			pen = (blockWidth - line.Width) / 2
+		case AlignRight:
+			pen = blockWidth - line.Width
+		}
+
+		var previous rune
+		for i, r := range line.Text {
+			if i > 0 {
+				pen += f.Kern(previous, r)
+			}
+			previous = r
+
+			g := f.glyph(r)
+			if !g.region.Empty() {
+				// Glyphs start on whole pixels so coverage masks stay crisp
+				layout.Glyphs = append(layout.Glyphs, Glyph{
+					Rune:   r,
+					Page:   g.page,
+					Source: g.region,
+					X:      float32(math.Round(float64(pen))) + float32(g.offset.X),
+					Y:      float32(math.Round(float64(line.Baseline))) + float32(g.offset.Y),
+				})
+			}
+			pen += g.advance
+		}
+	}
+	return layout
+}
+
+// Measure returns the width and height Layout would give a string
+func (f *Face) Measure(s string, maxWidth float32) (float32, float32) {
+	lines := f.wrap(s, maxWidth)
+	width := float32(0)
+	for _, line := range lines {
+		width = max(width, f.width(line))
+	}
+	return width, float32(len(lines)) * f.lineHeight
+}
+
+// wrap breaks a string into lines
+func (f *Face) wrap(s string, maxWidth float32) [][]rune {
+	var lines [][]rune
+	for _, paragraph := range strings.Split(s, "\n") {
+		runes := []rune(strings.TrimSuffix(paragraph, "\r"))
+		if maxWidth <= 0 {
+			lines = append(lines, runes)
+			continue
+		}
+
+		start, space := 0, -1
+		width := float32(0)
+		for i := 0; i < len(runes); i++ {
+			r := runes[i]
+			advance := f.Advance(r)
+			if i > start {
+				advance += f.Kern(runes[i-1], r)
+			}
+
+			if r != ' ' && i > start && width+advance > maxWidth {
+				// Break after the last space, or before this rune when
+				// the line is a single word
+				end := i
+				if space > start {
+					end = space
+				}
+				lines = append(lines, trimSpaces(runes[start:end]))
+				start = end
+				for start < i && runes[start] == ' ' {
+					start++
+				}
+				space = -1
+				width = f.width(runes[start:i])
+				i-- // Place this rune again on the new line
+				continue
+			}
+
+			if r == ' ' {
+				space = i
+			}
+			width += advance
+		}
+		lines = append(lines, trimSpaces(runes[start:]))
+	}
+	return lines
+}
+
+// width measures a line from the first pen position to the last advance
+func (f *Face) width(line []rune) float32 {
+	width := float32(0)
+	for i, r := range line {
+		if i > 0 {
+			width += f.Kern(line[i-1], r)
+		}
+		width += f.Advance(r)
+	}
+	return width
+}
+
+func trimSpaces(line []rune) []rune {
+	end := len(line)
+	for end > 0 && line[end-1] == ' ' {
+		end--
+	}
+	return line[:end]
+}
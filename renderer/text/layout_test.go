@@ -0,0 +1,189 @@
+package text
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newTestFace returns Go Regular, which ships with x/image, at 16 pixels
+func newTestFace(t *testing.T) *Face {
+	t.Helper()
+	face, err := NewFace(DefaultFont(), DefaultOptions(16))
+	if err != nil {
+		t.Fatalf("NewFace: %v", err)
+	}
+	return face
+}
+
+func TestFaceMetrics(t *testing.T) {
+	face := newTestFace(t)
+	if face.Ascent() <= 0 || face.Descent() <= 0 {
+		t.Errorf("ascent %v, descent %v", face.Ascent(), face.Descent())
+	}
+	if face.LineHeight() < face.Ascent()+face.Descent()-1 {
+		t.Errorf("line height %v is less than ascent %v plus descent %v", face.LineHeight(), face.Ascent(), face.Descent())
+	}
+	// Go Regular is proportional: an m is wider than an i
+	if face.Advance('m') <= face.Advance('i') {
+		t.Errorf("advance m = %v, i = %v", face.Advance('m'), face.Advance('i'))
+	}
+	if _, err := NewFace(DefaultFont(), DefaultOptions(0)); err == nil {
+		t.Error("zero size face created")
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	face := newTestFace(t)
+	tests := []struct {
+		name     string
+		s        string
+		maxWidth float32
+		lines    int
+	}{
+		{"empty", "", 0, 1},
+		{"word", "Bifrost", 0, 1},
+		{"sentence", "The quick brown fox", 0, 1},
+		{"newlines", "one\ntwo\nthree", 0, 3},
+		{"wrapped", "The quick brown fox jumps over the lazy dog", 100, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width, height := face.Measure(tt.s, tt.maxWidth)
+			layout := face.Layout(tt.s, tt.maxWidth, AlignLeft)
+			if width != layout.Width || height != layout.Height {
+				t.Errorf("Measure = %vx%v, Layout = %vx%v", width, height, layout.Width, layout.Height)
+			}
+			if tt.lines > 0 && len(layout.Lines) != tt.lines {
+				t.Errorf("got %d lines, want %d", len(layout.Lines), tt.lines)
+			}
+			if want := float32(len(layout.Lines)) * face.LineHeight(); height != want {
+				t.Errorf("height = %v, want %v", height, want)
+			}
+			if tt.maxWidth > 0 && width > tt.maxWidth {
+				t.Errorf("width %v exceeds the wrap width %v", width, tt.maxWidth)
+			}
+		})
+	}
+
+	// A line is as wide as its advances and kerning
+	s := "Typography"
+	want := float32(0)
+	for i, r := range s {
+		if i > 0 {
+			want += face.Kern(rune(s[i-1]), r)
+		}
+		want += face.Advance(r)
+	}
+	if width, _ := face.Measure(s, 0); math.Abs(float64(width-want)) > 1e-3 {
+		t.Errorf("width of %q = %v, want %v", s, width, want)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	face := newTestFace(t)
+	width := func(s string) float32 {
+		w, _ := face.Measure(s, 0)
+		return w
+	}
+
+	tests := []struct {
+		name     string
+		s        string
+		maxWidth float32
+		want     []string
+	}{
+		{"no wrap width", "hello world", 0, []string{"hello world"}},
+		{"fits exactly", "hello world", width("hello world"), []string{"hello world"}},
+		{"breaks at the space", "hello world", width("hello world") - 1, []string{"hello", "world"}},
+		{"drops spaces at the break", "hello   world", max(width("hello"), width("world")), []string{"hello", "world"}},
+		{"keeps words together", "a bb ccc dddd", max(width("a bb"), width("dddd")), []string{"a bb", "ccc", "dddd"}},
+		{"splits long words", "aaaaaaaa", width("aaa"), []string{"aaa", "aaa", "aa"}},
+		{"word before a long word", "hi aaaaaa", width("aaa"), []string{"hi", "aaa", "aaa"}},
+		{"newlines", "a\nb", 0, []string{"a", "b"}},
+		{"windows newlines", "a\r\nb", 0, []string{"a", "b"}},
+		{"blank line", "a\n\nb", 100, []string{"a", "", "b"}},
+		{"narrower than a glyph", "ab", 1, []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := face.Layout(tt.s, tt.maxWidth, AlignLeft)
+			var got []string
+			for i, line := range layout.Lines {
+				got = append(got, line.Text)
+				if want := width(line.Text); line.Width != want {
+					t.Errorf("line %q width = %v, want %v", line.Text, line.Width, want)
+				}
+				if want := face.Ascent() + float32(i)*face.LineHeight(); line.Baseline != want {
+					t.Errorf("line %d baseline = %v, want %v", i, line.Baseline, want)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lines = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLayoutGlyphs(t *testing.T) {
+	face := newTestFace(t)
+	layout := face.Layout("a b\nc", 0, AlignLeft)
+
+	// Spaces advance the pen but place no glyph
+	var runes []rune
+	for _, g := range layout.Glyphs {
+		runes = append(runes, g.Rune)
+	}
+	if string(runes) != "abc" {
+		t.Fatalf("glyphs = %q, want \"abc\"", string(runes))
+	}
+
+	a, b, c := layout.Glyphs[0], layout.Glyphs[1], layout.Glyphs[2]
+	if b.X <= a.X+face.Advance(' ') {
+		t.Errorf("b at %v does not follow a at %v and a space", b.X, a.X)
+	}
+	// The second line starts again at the left, one line lower
+	if c.X >= b.X || c.Y <= a.Y {
+		t.Errorf("c at (%v, %v) is not below a at (%v, %v)", c.X, c.Y, a.X, a.Y)
+	}
+
+	pages := face.Pages()
+	for _, g := range layout.Glyphs {
+		if g.Source.Empty() || !g.Source.In(pages[g.Page].Image.Bounds()) {
+			t.Errorf("glyph %q source %v outside page %d", g.Rune, g.Source, g.Page)
+		}
+	}
+}
+
+func TestAlign(t *testing.T) {
+	face := newTestFace(t)
+	const maxWidth = 200
+	lineWidth, _ := face.Measure("ab", 0)
+	left := face.Layout("ab", maxWidth, AlignLeft).Glyphs[0].X
+
+	tests := []struct {
+		align Align
+		shift float32
+	}{
+		{AlignLeft, 0},
+		{AlignCenter, (maxWidth - lineWidth) / 2},
+		{AlignRight, maxWidth - lineWidth},
+	}
+	for _, tt := range tests {
+		x := face.Layout("ab", maxWidth, tt.align).Glyphs[0].X
+		// Glyphs snap to whole pixels
+		if math.Abs(float64(x-left-tt.shift)) > 1 {
+			t.Errorf("align %d: first glyph at %v, want %v", tt.align, x, left+tt.shift)
+		}
+	}
+
+	// Without a wrap width lines align within the widest one
+	layout := face.Layout(strings.Join([]string{"wide line", "i"}, "\n"), 0, AlignRight)
+	last := layout.Glyphs[len(layout.Glyphs)-1]
+	if right := last.X + float32(last.Source.Dx()); math.Abs(float64(right-layout.Width)) > float64(face.Advance('i')) {
+		t.Errorf("right-aligned i ends at %v, block is %v wide", right, layout.Width)
+	}
+}
@@ -0,0 +1,102 @@
+package text
+
+import (
+	"image"
+	"math"
+)
+
+// distanceField converts a coverage mask into a signed distance field.
+// Alpha 128 lies on the glyph's edge and every step of 128/spread is one
+// pixel further inside (above) or outside (below).
+func distanceField(mask *image.Alpha, spread int) *image.Alpha {
+	width, height := mask.Rect.Dx(), mask.Rect.Dy()
+	inside := make([]bool, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			inside[y*width+x] = mask.AlphaAt(x, y).A >= 128
+		}
+	}
+
+	// Squared distances to the nearest pixel on the other side of the edge
+	toOutside := distanceTransform(inside, width, height, false)
+	toInside := distanceTransform(inside, width, height, true)
+
+	out := image.NewAlpha(mask.Rect)
+	for i, in := range inside {
+		// The edge runs between pixel centers, half a pixel from each
+		var distance float64
+		if in {
+			distance = math.Sqrt(toOutside[i]) - 0.5
+		} else {
+			distance = 0.5 - math.Sqrt(toInside[i])
+		}
+		value := 0.5 + distance/float64(2*spread)
+		out.Pix[i] = uint8(math.Round(math.Max(0, math.Min(1, value)) * 255))
+	}
+	return out
+}
+
+// distanceTransform returns the squared distance from every pixel to the
+// nearest pixel whose inside flag equals target, using the separable exact
+// transform of Felzenszwalb and Huttenlocher
+func distanceTransform(inside []bool, width, height int, target bool) []float64 {
+	const far = 1e20
+	grid := make([]float64, width*height)
+	for i, in := range inside {
+		if in == target {
+			grid[i] = 0
+		} else {
+			grid[i] = far
+		}
+	}
+
+	size := max(width, height)
+	f := make([]float64, size)
+	d := make([]float64, size)
+	v := make([]int, size)
+	z := make([]float64, size+1)
+
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			f[y] = grid[y*width+x]
+		}
+		transform1D(f[:height], d[:height], v, z)
+		for y := 0; y < height; y++ {
+			grid[y*width+x] = d[y]
+		}
+	}
+	for y := 0; y < height; y++ {
+		copy(f[:width], grid[y*width:(y+1)*width])
+		transform1D(f[:width], d[:width], v, z)
+		copy(grid[y*width:(y+1)*width], d[:width])
+	}
+	return grid
+}
+
+// transform1D computes the lower envelope of parabolas rooted at f into d
+func transform1D(f, d []float64, v []int, z []float64) {
+	n := len(f)
+	k := 0
+	v[0] = 0
+	z[0] = math.Inf(-1)
+	z[1] = math.Inf(1)
+	for q := 1; q < n; q++ {
+		s := ((f[q] + float64(q*q)) - (f[v[k]] + float64(v[k]*v[k]))) / float64(2*q-2*v[k])
+		for s <= z[k] {
+			k--
+			s = ((f[q] + float64(q*q)) - (f[v[k]] + float64(v[k]*v[k]))) / float64(2*q-2*v[k])
+		}
+		k++
+		v[k] = q
+		z[k] = s
+		z[k+1] = math.Inf(1)
+	}
+
+	k = 0
+	for q := 0; q < n; q++ {
+		for z[k+1] < float64(q) {
+			k++
+		}
+		d[q] = float64((q-v[k])*(q-v[k])) + f[v[k]]
+	}
+}
@@ -0,0 +1,162 @@
+package tilemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// decodeTiles turns the text of a tile layer's data into global IDs.
+// Tiled writes CSV or little-endian base64, optionally compressed.
+func decodeTiles(text, encoding, compression string, count int) ([]uint32, error) {
+	var tiles []uint32
+	switch encoding {
+	case "csv":
+		for _, field := range strings.Split(text, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			gid, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid tile %q", field)
+			}
+			tiles = append(tiles, uint32(gid))
+		}
+	case "base64":
+		data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 tile data: %w", err)
+		}
+		if data, err = decompress(data, compression); err != nil {
+			return nil, err
+		}
+		if len(data)%4 != 0 {
+			return nil, fmt.Errorf("tile data is %d bytes, not a multiple of 4", len(data))
+		}
+		tiles = make([]uint32, len(data)/4)
+		for i := range tiles {
+			tiles[i] = binary.LittleEndian.Uint32(data[i*4:])
+		}
+	default:
+		return nil, fmt.Errorf("unsupported tile data encoding %q", encoding)
+	}
+
+	if len(tiles) != count {
+		return nil, fmt.Errorf("layer has %d tiles, expected %d", len(tiles), count)
+	}
+	return tiles, nil
+}
+
+func decompress(data []byte, compression string) ([]byte, error) {
+	var reader io.ReadCloser
+	var err error
+	switch compression {
+	case "":
+		return data, nil
+	case "zlib":
+		reader, err = zlib.NewReader(bytes.NewReader(data))
+	case "gzip":
+		reader, err = gzip.NewReader(bytes.NewReader(data))
+	default:
+		return nil, fmt.Errorf("unsupported tile data compression %q", compression)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s tile data: %w", compression, err)
+	}
+	defer reader.Close()
+
+	out, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s tile data: %w", compression, err)
+	}
+	return out, nil
+}
+
+// group carries what a group layer passes on to the layers inside it
+type group struct {
+	prefix  string
+	offsetX float32
+	offsetY float32
+	opacity float32
+	visible bool
+}
+
+var rootGroup = group{opacity: 1, visible: true}
+
+// nest returns the group for layers inside a group layer
+func (g group) nest(name string, offsetX, offsetY, opacity float32, visible bool) group {
+	return group{
+		prefix:  g.prefix + name + "/",
+		offsetX: g.offsetX + offsetX,
+		offsetY: g.offsetY + offsetY,
+		opacity: g.opacity * opacity,
+		visible: g.visible && visible,
+	}
+}
+
+// layer creates a layer inside the group
+func (g group) layer(kind LayerKind, name string, offsetX, offsetY, opacity float32, visible bool) *Layer {
+	return &Layer{
+		Name:       g.prefix + name,
+		Kind:       kind,
+		Visible:    g.visible && visible,
+		Opacity:    g.opacity * opacity,
+		OffsetX:    g.offsetX + offsetX,
+		OffsetY:    g.offsetY + offsetY,
+		Properties: Properties{},
+	}
+}
+
+// resolvePath makes a path from a map or tileset file absolute, or at
+// least relative to the working directory
+func resolvePath(base, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(base), filepath.FromSlash(path))
+}
+
+// finish checks what both formats share and orders the tilesets
+func (m *Map) finish(orientation string, infinite bool) error {
+	if orientation != "" && orientation != "orthogonal" {
+		return fmt.Errorf("%s maps are not supported, only orthogonal", orientation)
+	}
+	if infinite {
+		return fmt.Errorf("infinite maps are not supported")
+	}
+	if m.Width <= 0 || m.Height <= 0 || m.TileWidth <= 0 || m.TileHeight <= 0 {
+		return fmt.Errorf("invalid map size %dx%d with %dx%d tiles", m.Width, m.Height, m.TileWidth, m.TileHeight)
+	}
+	sort.Slice(m.Tilesets, func(i, j int) bool { return m.Tilesets[i].FirstGID < m.Tilesets[j].FirstGID })
+	return nil
+}
+
+// parsePoints reads a TMX point list, "x1,y1 x2,y2 ..."
+func parsePoints(text string) ([][2]float32, error) {
+	var points [][2]float32
+	for _, pair := range strings.Fields(text) {
+		xs, ys, ok := strings.Cut(pair, ",")
+		if !ok {
+			return nil, fmt.Errorf("invalid point %q", pair)
+		}
+		x, err := strconv.ParseFloat(xs, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid point %q", pair)
+		}
+		y, err := strconv.ParseFloat(ys, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid point %q", pair)
+		}
+		points = append(points, [2]float32{float32(x), float32(y)})
+	}
+	return points, nil
+}
@@ -0,0 +1,105 @@
+package tilemap
+
+import (
+	"math"
+
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/sprite"
+)
+
+// TextureSource returns the texture of an image used by a map, such as
+// sprite.TextureCache.Get
+type TextureSource func(path string) (sprite.Texture, error)
+
+// Draw queues the visible tile layers of a map on a batch that has begun.
+// The map's top-left corner is placed at origin, and each layer goes on
+// its own sprite layer counting up from baseLayer in map order, so object
+// layers in between keep their place for sprites drawn with LayerIndex.
+// The first texture that fails to load is returned after drawing the rest.
+func Draw(batch *sprite.SpriteBatch, m *Map, textures TextureSource, origin bmath.Vector2, baseLayer int) error {
+	var firstErr error
+	for index, layer := range m.Layers {
+		if layer.Kind != TileLayer || !layer.Visible || layer.Opacity <= 0 {
+			continue
+		}
+
+		for y := 0; y < layer.Height; y++ {
+			for x := 0; x < layer.Width; x++ {
+				tile, ok := m.TileAt(layer, x, y)
+				if !ok {
+					continue
+				}
+				path, region := tile.Image()
+				texture, err := textures(path)
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+					}
+					continue
+				}
+
+				s := tileSprite(tile, texture)
+				s.Source = region
+				s.Size = bmath.NewVector2(float32(region.Dx()), float32(region.Dy()))
+				width, height := s.Size.X, s.Size.Y
+				if tile.FlipD {
+					width, height = height, width
+				}
+				// Tiles larger than the grid grow up and to the right from
+				// the bottom-left corner of their cell, as in Tiled
+				left := origin.X + layer.OffsetX + float32(x*m.TileWidth)
+				bottom := origin.Y - layer.OffsetY - float32((y+1)*m.TileHeight)
+				s.Position = bmath.NewVector2(left+width/2, bottom+height/2)
+				s.Tint = [4]float32{1, 1, 1, layer.Opacity}
+				s.Layer = baseLayer + index
+				batch.Draw(s)
+			}
+		}
+	}
+	return firstErr
+}
+
+// tileSprite creates a centered sprite showing a tile with its flips. An
+// anti-diagonal flip swaps the tile's axes, which is a quarter turn
+// combined with a mirror.
+func tileSprite(tile Tile, texture sprite.Texture) sprite.Sprite {
+	s := sprite.NewSprite(texture)
+	if !tile.FlipD {
+		s.FlipX = tile.FlipH
+		s.FlipY = tile.FlipV
+		return s
+	}
+
+	quarter := float32(math.Pi / 2)
+	switch {
+	case tile.FlipH && tile.FlipV:
+		s.Rotation = quarter
+		s.FlipY = true
+	case tile.FlipH:
+		s.Rotation = -quarter
+	case tile.FlipV:
+		s.Rotation = quarter
+	default:
+		s.Rotation = quarter
+		s.FlipX = true
+	}
+	return s
+}
+
+// LayerIndex returns the position of a layer in the map, which Draw adds
+// to the base sprite layer, or -1 when it is not part of the map
+func (m *Map) LayerIndex(layer *Layer) int {
+	for i, l := range m.Layers {
+		if l == layer {
+			return i
+		}
+	}
+	return -1
+}
+
+// ObjectPosition returns an object's anchor relative to the map's world
+// origin: the bottom-left corner for tile objects and the top-left corner
+// otherwise, matching the corner Tiled rotates it about
+func (l *Layer) ObjectPosition(obj Object) bmath.Vector2 {
+	return bmath.NewVector2(obj.X+l.OffsetX, -(obj.Y + l.OffsetY))
+}
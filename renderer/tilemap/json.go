@@ -0,0 +1,299 @@
+package tilemap
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type jsonProperty struct {
+	Name  string          `json:"name"`
+	Value json.RawMessage `json:"value"`
+}
+
+type jsonMap struct {
+	Orientation string         `json:"orientation"`
+	Infinite    bool           `json:"infinite"`
+	Width       int            `json:"width"`
+	Height      int            `json:"height"`
+	TileWidth   int            `json:"tilewidth"`
+	TileHeight  int            `json:"tileheight"`
+	Layers      []jsonLayer    `json:"layers"`
+	Tilesets    []jsonTileset  `json:"tilesets"`
+	Properties  []jsonProperty `json:"properties"`
+}
+
+type jsonLayer struct {
+	Type        string          `json:"type"`
+	Name        string          `json:"name"`
+	Width       int             `json:"width"`
+	Height      int             `json:"height"`
+	Data        json.RawMessage `json:"data"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Visible     *bool           `json:"visible"`
+	Opacity     *float32        `json:"opacity"`
+	OffsetX     float32         `json:"offsetx"`
+	OffsetY     float32         `json:"offsety"`
+	Objects     []jsonObject    `json:"objects"`
+	Layers      []jsonLayer     `json:"layers"`
+	Properties  []jsonProperty  `json:"properties"`
+}
+
+type jsonPoint struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+}
+
+type jsonObject struct {
+	ID         int            `json:"id"`
+	Name       string         `json:"name"`
+	Type       string         `json:"type"`
+	Class      string         `json:"class"`
+	X          float32        `json:"x"`
+	Y          float32        `json:"y"`
+	Width      float32        `json:"width"`
+	Height     float32        `json:"height"`
+	Rotation   float32        `json:"rotation"`
+	GID        uint32         `json:"gid"`
+	Visible    *bool          `json:"visible"`
+	Point      bool           `json:"point"`
+	Ellipse    bool           `json:"ellipse"`
+	Polygon    []jsonPoint    `json:"polygon"`
+	Polyline   []jsonPoint    `json:"polyline"`
+	Properties []jsonProperty `json:"properties"`
+}
+
+type jsonTileset struct {
+	FirstGID    uint32         `json:"firstgid"`
+	Source      string         `json:"source"`
+	Name        string         `json:"name"`
+	TileWidth   int            `json:"tilewidth"`
+	TileHeight  int            `json:"tileheight"`
+	Spacing     int            `json:"spacing"`
+	Margin      int            `json:"margin"`
+	Columns     int            `json:"columns"`
+	TileCount   int            `json:"tilecount"`
+	Image       string         `json:"image"`
+	ImageWidth  int            `json:"imagewidth"`
+	ImageHeight int            `json:"imageheight"`
+	Tiles       []jsonTile     `json:"tiles"`
+	Properties  []jsonProperty `json:"properties"`
+}
+
+type jsonTile struct {
+	ID          int            `json:"id"`
+	Type        string         `json:"type"`
+	Class       string         `json:"class"`
+	Image       string         `json:"image"`
+	ImageWidth  int            `json:"imagewidth"`
+	ImageHeight int            `json:"imageheight"`
+	ObjectGroup *jsonLayer     `json:"objectgroup"`
+	Properties  []jsonProperty `json:"properties"`
+}
+
+// LoadJSON reads a map saved in Tiled's JSON format. External tilesets may
+// be JSON (.tsj, .json) or XML (.tsx).
+func LoadJSON(path string) (*Map, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tile map: %w", err)
+	}
+
+	var src jsonMap
+	if err := json.Unmarshal(data, &src); err != nil {
+		return nil, fmt.Errorf("failed to parse tile map %s: %w", path, err)
+	}
+
+	m := &Map{
+		Path:       path,
+		Width:      src.Width,
+		Height:     src.Height,
+		TileWidth:  src.TileWidth,
+		TileHeight: src.TileHeight,
+		Properties: jsonProperties(src.Properties),
+	}
+	for _, ts := range src.Tilesets {
+		var tileset *Tileset
+		if ts.Source != "" {
+			tileset, err = loadTileset(resolvePath(path, ts.Source))
+		} else {
+			tileset = ts.convert(path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("tile map %s: %w", path, err)
+		}
+		tileset.FirstGID = ts.FirstGID
+		m.Tilesets = append(m.Tilesets, tileset)
+	}
+	if err := m.finish(src.Orientation, src.Infinite); err != nil {
+		return nil, fmt.Errorf("tile map %s: %w", path, err)
+	}
+
+	if err := m.addJSONLayers(src.Layers, rootGroup); err != nil {
+		return nil, fmt.Errorf("tile map %s: %w", path, err)
+	}
+	return m, nil
+}
+
+func (m *Map) addJSONLayers(layers []jsonLayer, parent group) error {
+	for _, src := range layers {
+		visible := src.Visible == nil || *src.Visible
+		opacity := float32(1)
+		if src.Opacity != nil {
+			opacity = *src.Opacity
+		}
+
+		switch src.Type {
+		case "group":
+			if err := m.addJSONLayers(src.Layers, parent.nest(src.Name, src.OffsetX, src.OffsetY, opacity, visible)); err != nil {
+				return err
+			}
+		case "tilelayer":
+			layer := parent.layer(TileLayer, src.Name, src.OffsetX, src.OffsetY, opacity, visible)
+			layer.Width, layer.Height = src.Width, src.Height
+			layer.Properties = jsonProperties(src.Properties)
+			tiles, err := src.tiles()
+			if err != nil {
+				return fmt.Errorf("layer %s: %w", layer.Name, err)
+			}
+			layer.Tiles = tiles
+			m.Layers = append(m.Layers, layer)
+		case "objectgroup":
+			layer := parent.layer(ObjectLayer, src.Name, src.OffsetX, src.OffsetY, opacity, visible)
+			layer.Properties = jsonProperties(src.Properties)
+			for _, obj := range src.Objects {
+				layer.Objects = append(layer.Objects, obj.convert())
+			}
+			m.Layers = append(m.Layers, layer)
+		}
+		// Image layers are not drawn
+	}
+	return nil
+}
+
+// tiles reads layer data written either as an array of IDs or as a
+// base64 string
+func (l *jsonLayer) tiles() ([]uint32, error) {
+	count := l.Width * l.Height
+	if l.Encoding == "base64" {
+		var text string
+		if err := json.Unmarshal(l.Data, &text); err != nil {
+			return nil, fmt.Errorf("invalid tile data: %w", err)
+		}
+		return decodeTiles(text, l.Encoding, l.Compression, count)
+	}
+
+	var tiles []uint32
+	if err := json.Unmarshal(l.Data, &tiles); err != nil {
+		return nil, fmt.Errorf("invalid tile data: %w", err)
+	}
+	if len(tiles) != count {
+		return nil, fmt.Errorf("layer has %d tiles, expected %d", len(tiles), count)
+	}
+	return tiles, nil
+}
+
+func (o *jsonObject) convert() Object {
+	obj := Object{
+		ID:         o.ID,
+		Name:       o.Name,
+		Type:       o.Type,
+		X:          o.X,
+		Y:          o.Y,
+		Width:      o.Width,
+		Height:     o.Height,
+		Rotation:   o.Rotation,
+		GID:        o.GID,
+		Visible:    o.Visible == nil || *o.Visible,
+		Point:      o.Point,
+		Ellipse:    o.Ellipse,
+		Polygon:    jsonPoints(o.Polygon),
+		Polyline:   jsonPoints(o.Polyline),
+		Properties: jsonProperties(o.Properties),
+	}
+	if obj.Type == "" {
+		obj.Type = o.Class
+	}
+	return obj
+}
+
+func (ts *jsonTileset) convert(path string) *Tileset {
+	tileset := &Tileset{
+		Name:        ts.Name,
+		TileWidth:   ts.TileWidth,
+		TileHeight:  ts.TileHeight,
+		Spacing:     ts.Spacing,
+		Margin:      ts.Margin,
+		Columns:     ts.Columns,
+		TileCount:   ts.TileCount,
+		Image:       resolvePath(path, ts.Image),
+		ImageWidth:  ts.ImageWidth,
+		ImageHeight: ts.ImageHeight,
+		Tiles:       make(map[int]*TileInfo),
+		Properties:  jsonProperties(ts.Properties),
+	}
+	for _, tile := range ts.Tiles {
+		info := &TileInfo{
+			ID:          tile.ID,
+			Type:        tile.Type,
+			Image:       resolvePath(path, tile.Image),
+			ImageWidth:  tile.ImageWidth,
+			ImageHeight: tile.ImageHeight,
+			Properties:  jsonProperties(tile.Properties),
+		}
+		if info.Type == "" {
+			info.Type = tile.Class
+		}
+		if tile.ObjectGroup != nil {
+			for _, obj := range tile.ObjectGroup.Objects {
+				info.Shapes = append(info.Shapes, obj.convert())
+			}
+		}
+		tileset.Tiles[tile.ID] = info
+	}
+	return tileset
+}
+
+// loadTileset reads an external tileset in either format
+func loadTileset(path string) (*Tileset, error) {
+	if strings.EqualFold(filepath.Ext(path), ".tsx") {
+		return loadTSX(path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tileset: %w", err)
+	}
+	var src jsonTileset
+	if err := json.Unmarshal(data, &src); err != nil {
+		return nil, fmt.Errorf("failed to parse tileset %s: %w", path, err)
+	}
+	return src.convert(path), nil
+}
+
+func jsonProperties(props []jsonProperty) Properties {
+	properties := make(Properties, len(props))
+	for _, prop := range props {
+		// Strings are unquoted; numbers and booleans keep their JSON text
+		var text string
+		if err := json.Unmarshal(prop.Value, &text); err != nil {
+			text = string(prop.Value)
+		}
+		properties[prop.Name] = text
+	}
+	return properties
+}
+
+func jsonPoints(points []jsonPoint) [][2]float32 {
+	if len(points) == 0 {
+		return nil
+	}
+	out := make([][2]float32, len(points))
+	for i, p := range points {
+		out[i] = [2]float32{p.X, p.Y}
+	}
+	return out
+}
@@ -0,0 +1,9 @@
+<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" tiledversion="1.10.2" name="props" tilewidth="16" tileheight="16" tilecount="4" columns="2">
+ <image source="props.png" width="32" height="32"/>
+ <tile id="3" type="chest">
+  <properties>
+   <property name="interactive" type="bool" value="true"/>
+  </properties>
+ </tile>
+</tileset>
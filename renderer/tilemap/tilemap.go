@@ -0,0 +1,286 @@
+// Package tilemap holds orthogonal tile maps made in the Tiled editor and
+// draws their tile layers through a sprite batch. Maps load from Tiled's
+// JSON (.tmj, .json) and XML (.tmx) formats with embedded or external
+// tilesets, tile and object layers, and custom properties.
+//
+// Map coordinates follow Tiled: pixels, with y pointing down from the
+// top-left corner. World coordinates have y pointing up, so a map placed
+// at a world origin extends right and down from it.
+package tilemap
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Flags stored in the high bits of a global tile ID
+const (
+	FlipHorizontal uint32 = 0x80000000
+	FlipVertical   uint32 = 0x40000000
+	FlipDiagonal   uint32 = 0x20000000
+	rotateHex      uint32 = 0x10000000 // Only used by hexagonal maps
+
+	gidMask = ^(FlipHorizontal | FlipVertical | FlipDiagonal | rotateHex)
+)
+
+// Properties are the custom properties of a map, layer, tileset, tile or
+// object, with every value kept as its text
+type Properties map[string]string
+
+// Bool reports whether a property is set to true
+func (p Properties) Bool(name string) bool {
+	value, err := strconv.ParseBool(p[name])
+	return err == nil && value
+}
+
+// Float returns a numeric property, or fallback when it is missing or not
+// a number
+func (p Properties) Float(name string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(p[name], 64)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// Map is a loaded tile map
+type Map struct {
+	Path       string
+	Width      int // In tiles
+	Height     int
+	TileWidth  int // In pixels
+	TileHeight int
+	Tilesets   []*Tileset // Ordered by first global ID
+	Layers     []*Layer   // In drawing order, with groups flattened
+	Properties Properties
+}
+
+// Tileset is a set of tiles cut from one image, or, for image collection
+// tilesets, a set of separate images
+type Tileset struct {
+	FirstGID    uint32
+	Name        string
+	TileWidth   int
+	TileHeight  int
+	Spacing     int
+	Margin      int
+	Columns     int
+	TileCount   int
+	Image       string // Resolved path; empty for image collections
+	ImageWidth  int
+	ImageHeight int
+	Tiles       map[int]*TileInfo // Tiles with properties, shapes or own images
+	Properties  Properties
+}
+
+// TileInfo is the extra data Tiled stores for some tiles of a tileset
+type TileInfo struct {
+	ID          int
+	Type        string
+	Image       string // Resolved path of an image collection tile
+	ImageWidth  int
+	ImageHeight int
+	Properties  Properties
+	// Shapes are the collision shapes drawn in Tiled's collision editor,
+	// relative to the tile's top-left corner
+	Shapes []Object
+}
+
+// LayerKind tells tile layers from object layers
+type LayerKind int
+
+const (
+	TileLayer LayerKind = iota
+	ObjectLayer
+)
+
+// Layer is a tile or object layer. Layers nested in groups are named by
+// their path, "group/layer", and inherit the group's offset, opacity and
+// visibility.
+type Layer struct {
+	Name       string
+	Kind       LayerKind
+	Width      int
+	Height     int
+	Tiles      []uint32 // Global IDs with flip flags, row by row; 0 is empty
+	Objects    []Object
+	Visible    bool
+	Opacity    float32
+	OffsetX    float32
+	OffsetY    float32
+	Properties Properties
+}
+
+// Object is an object of an object layer, or a collision shape of a tile.
+// X and Y are the top-left corner, except for tile objects (GID set) whose
+// position is their bottom-left corner, as in Tiled.
+type Object struct {
+	ID         int
+	Name       string
+	Type       string
+	X          float32
+	Y          float32
+	Width      float32
+	Height     float32
+	Rotation   float32 // Clockwise, in degrees
+	GID        uint32  // Global tile ID with flip flags for tile objects
+	Visible    bool
+	Point      bool
+	Ellipse    bool
+	Polygon    [][2]float32 // Points relative to X, Y
+	Polyline   [][2]float32
+	Properties Properties
+}
+
+// Tile is a decoded tile of a tile layer
+type Tile struct {
+	Tileset *Tileset
+	ID      int // Local to the tileset
+	FlipH   bool
+	FlipV   bool
+	FlipD   bool // Anti-diagonal flip, applied before the others
+}
+
+// Info returns the tile's extra data, or nil when it has none
+func (t Tile) Info() *TileInfo {
+	return t.Tileset.Tiles[t.ID]
+}
+
+// Solid reports whether the tile blocks movement: a "collision" or "solid"
+// property set to true, or collision shapes drawn in Tiled, mark it
+func (t Tile) Solid() bool {
+	info := t.Info()
+	if info == nil {
+		return false
+	}
+	return info.Properties.Bool("collision") || info.Properties.Bool("solid") || len(info.Shapes) > 0
+}
+
+// Image returns the image a tile is drawn from and its region in it
+func (t Tile) Image() (string, image.Rectangle) {
+	if info := t.Info(); info != nil && info.Image != "" {
+		return info.Image, image.Rect(0, 0, info.ImageWidth, info.ImageHeight)
+	}
+	return t.Tileset.Image, t.Tileset.Region(t.ID)
+}
+
+// Region returns the pixel rectangle of a tile in the tileset image
+func (ts *Tileset) Region(id int) image.Rectangle {
+	columns := ts.Columns
+	if columns <= 0 {
+		columns = 1
+	}
+	x := ts.Margin + (id%columns)*(ts.TileWidth+ts.Spacing)
+	y := ts.Margin + (id/columns)*(ts.TileHeight+ts.Spacing)
+	return image.Rect(x, y, x+ts.TileWidth, y+ts.TileHeight)
+}
+
+// Load reads a map, choosing the format by the file extension
+func Load(path string) (*Map, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tmx":
+		return LoadTMX(path)
+	case ".tmj", ".json":
+		return LoadJSON(path)
+	}
+	return nil, fmt.Errorf("unknown tile map format: %s", path)
+}
+
+// Tile decodes a global tile ID, finding the tileset it belongs to. Empty
+// tiles and IDs outside every tileset return false.
+func (m *Map) Tile(gid uint32) (Tile, bool) {
+	id := gid & gidMask
+	if id == 0 {
+		return Tile{}, false
+	}
+
+	var tileset *Tileset
+	for _, ts := range m.Tilesets {
+		if ts.FirstGID <= id {
+			tileset = ts
+		}
+	}
+	if tileset == nil {
+		return Tile{}, false
+	}
+
+	return Tile{
+		Tileset: tileset,
+		ID:      int(id - tileset.FirstGID),
+		FlipH:   gid&FlipHorizontal != 0,
+		FlipV:   gid&FlipVertical != 0,
+		FlipD:   gid&FlipDiagonal != 0,
+	}, true
+}
+
+// TileAt returns the tile of a layer at a tile position
+func (m *Map) TileAt(layer *Layer, x, y int) (Tile, bool) {
+	if layer.Kind != TileLayer || x < 0 || y < 0 || x >= layer.Width || y >= layer.Height {
+		return Tile{}, false
+	}
+	return m.Tile(layer.Tiles[y*layer.Width+x])
+}
+
+// Layer returns a layer by name
+func (m *Map) Layer(name string) *Layer {
+	for _, layer := range m.Layers {
+		if layer.Name == name {
+			return layer
+		}
+	}
+	return nil
+}
+
+// Solid reports whether any tile layer, hidden ones included, has a solid
+// tile at a tile position. A tile layer with a "collision" property set to
+// true makes every tile on it solid. Positions outside the map are not
+// solid, and layer offsets are ignored.
+func (m *Map) Solid(x, y int) bool {
+	for _, layer := range m.Layers {
+		if layer.Kind != TileLayer {
+			continue
+		}
+		tile, ok := m.TileAt(layer, x, y)
+		if !ok {
+			continue
+		}
+		if layer.Properties.Bool("collision") || tile.Solid() {
+			return true
+		}
+	}
+	return false
+}
+
+// CollisionGrid returns the solidity of every tile position, row by row
+func (m *Map) CollisionGrid() []bool {
+	grid := make([]bool, m.Width*m.Height)
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			grid[y*m.Width+x] = m.Solid(x, y)
+		}
+	}
+	return grid
+}
+
+// WorldToTile returns the tile position under a point given relative to
+// the map's world origin
+func (m *Map) WorldToTile(x, y float32) (int, int) {
+	return int(math.Floor(float64(x / float32(m.TileWidth)))), int(math.Floor(float64(-y / float32(m.TileHeight))))
+}
+
+// TileToWorld returns the center of a tile relative to the map's world
+// origin
+func (m *Map) TileToWorld(x, y int) (float32, float32) {
+	return (float32(x) + 0.5) * float32(m.TileWidth), -(float32(y) + 0.5) * float32(m.TileHeight)
+}
+
+// SolidAt reports whether the tile under a point relative to the map's
+// world origin is solid
+func (m *Map) SolidAt(x, y float32) bool {
+	tx, ty := m.WorldToTile(x, y)
+	return m.Solid(tx, ty)
+}
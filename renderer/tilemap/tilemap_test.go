@@ -0,0 +1,241 @@
+package tilemap
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// The fixtures describe the same map in both formats: a grouped ground
+// layer with flipped tiles, a hidden collision layer stored compressed,
+// an object layer and an external tileset shared between them
+var fixtures = []string{"level.tmx", "level.tmj"}
+
+func loadFixture(t *testing.T, name string) *Map {
+	t.Helper()
+	m, err := Load(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return m
+}
+
+func TestLoadLayers(t *testing.T) {
+	type layer struct {
+		name             string
+		kind             LayerKind
+		visible          bool
+		opacity          float32
+		offsetX, offsetY float32
+	}
+	want := []layer{
+		{"world/ground", TileLayer, true, 0.5, 8, 4},
+		{"walls", TileLayer, false, 1, 0, 0},
+		{"objects", ObjectLayer, true, 1, 0, 0},
+	}
+
+	for _, name := range fixtures {
+		t.Run(name, func(t *testing.T) {
+			m := loadFixture(t, name)
+			if m.Width != 4 || m.Height != 3 || m.TileWidth != 16 || m.TileHeight != 16 {
+				t.Errorf("map is %dx%d with %dx%d tiles", m.Width, m.Height, m.TileWidth, m.TileHeight)
+			}
+			if m.Properties["music"] != "forest.ogg" || m.Properties.Float("gravity", 0) != 9.8 {
+				t.Errorf("map properties = %v", m.Properties)
+			}
+
+			var got []layer
+			for _, l := range m.Layers {
+				got = append(got, layer{l.Name, l.Kind, l.Visible, l.Opacity, l.OffsetX, l.OffsetY})
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("layers = %+v, want %+v", got, want)
+			}
+
+			ground := m.Layer("world/ground")
+			wantGround := []uint32{1, 2, 2, 1, 3, 0, 0, 3, 2 | FlipHorizontal, 3 | FlipVertical, 10 | FlipDiagonal, 0}
+			if !reflect.DeepEqual(ground.Tiles, wantGround) {
+				t.Errorf("ground tiles = %v, want %v", ground.Tiles, wantGround)
+			}
+			walls := m.Layer("walls")
+			if wantWalls := []uint32{0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0}; !reflect.DeepEqual(walls.Tiles, wantWalls) {
+				t.Errorf("wall tiles = %v, want %v", walls.Tiles, wantWalls)
+			}
+			if !walls.Properties.Bool("collision") {
+				t.Error("walls are not a collision layer")
+			}
+		})
+	}
+}
+
+func TestLoadTilesets(t *testing.T) {
+	for _, name := range fixtures {
+		t.Run(name, func(t *testing.T) {
+			m := loadFixture(t, name)
+			if len(m.Tilesets) != 2 {
+				t.Fatalf("got %d tilesets, want 2", len(m.Tilesets))
+			}
+
+			terrain, props := m.Tilesets[0], m.Tilesets[1]
+			if terrain.Name != "terrain" || terrain.FirstGID != 1 || terrain.Columns != 4 || terrain.TileCount != 8 {
+				t.Errorf("terrain = %+v", terrain)
+			}
+			if terrain.Image != filepath.Join("testdata", "terrain.png") || terrain.ImageWidth != 64 || terrain.ImageHeight != 32 {
+				t.Errorf("terrain image %s %dx%d", terrain.Image, terrain.ImageWidth, terrain.ImageHeight)
+			}
+			if shapes := terrain.Tiles[2].Shapes; len(shapes) != 1 || shapes[0].Y != 8 || shapes[0].Height != 8 {
+				t.Errorf("terrain tile 2 shapes = %+v", shapes)
+			}
+
+			// The external tileset resolves its image against its own file
+			if props.Name != "props" || props.FirstGID != 9 || props.Image != filepath.Join("testdata", "props.png") {
+				t.Errorf("props = %+v", props)
+			}
+			if info := props.Tiles[3]; info == nil || info.Type != "chest" || !info.Properties.Bool("interactive") {
+				t.Errorf("props tile 3 = %+v", info)
+			}
+			if got, want := props.Region(3), [4]int{16, 16, 32, 32}; [4]int{got.Min.X, got.Min.Y, got.Max.X, got.Max.Y} != want {
+				t.Errorf("props tile 3 region = %v", got)
+			}
+		})
+	}
+}
+
+func TestTileFlags(t *testing.T) {
+	tests := []struct {
+		x, y                int
+		tileset             string
+		id                  int
+		flipH, flipV, flipD bool
+	}{
+		{0, 0, "terrain", 0, false, false, false},
+		{3, 1, "terrain", 2, false, false, false},
+		{0, 2, "terrain", 1, true, false, false},
+		{1, 2, "terrain", 2, false, true, false},
+		{2, 2, "props", 1, false, false, true},
+	}
+
+	for _, name := range fixtures {
+		t.Run(name, func(t *testing.T) {
+			m := loadFixture(t, name)
+			ground := m.Layer("world/ground")
+			for _, tt := range tests {
+				tile, ok := m.TileAt(ground, tt.x, tt.y)
+				if !ok {
+					t.Errorf("no tile at (%d, %d)", tt.x, tt.y)
+					continue
+				}
+				if tile.Tileset.Name != tt.tileset || tile.ID != tt.id || tile.FlipH != tt.flipH || tile.FlipV != tt.flipV || tile.FlipD != tt.flipD {
+					t.Errorf("tile at (%d, %d) = %s %d h=%v v=%v d=%v", tt.x, tt.y, tile.Tileset.Name, tile.ID, tile.FlipH, tile.FlipV, tile.FlipD)
+				}
+			}
+
+			for _, pos := range [][2]int{{1, 1}, {3, 2}, {-1, 0}, {4, 0}, {0, 3}} {
+				if _, ok := m.TileAt(ground, pos[0], pos[1]); ok {
+					t.Errorf("tile at empty or outside position %v", pos)
+				}
+			}
+			if _, ok := m.TileAt(m.Layer("objects"), 0, 0); ok {
+				t.Error("tile on an object layer")
+			}
+		})
+	}
+}
+
+func TestLoadObjects(t *testing.T) {
+	for _, name := range fixtures {
+		t.Run(name, func(t *testing.T) {
+			m := loadFixture(t, name)
+			objects := m.Layer("objects").Objects
+			if len(objects) != 4 {
+				t.Fatalf("got %d objects, want 4", len(objects))
+			}
+			spawn, chest, zone, secret := objects[0], objects[1], objects[2], objects[3]
+
+			if spawn.Name != "spawn" || spawn.Type != "player" || !spawn.Point || spawn.X != 24 || spawn.Y != 40 {
+				t.Errorf("spawn = %+v", spawn)
+			}
+
+			if chest.GID != 12 || chest.Width != 16 || chest.Properties["loot"] != "gold" {
+				t.Errorf("chest = %+v", chest)
+			}
+			tile, ok := m.Tile(chest.GID)
+			if !ok || tile.Tileset.Name != "props" || tile.ID != 3 || tile.Info().Type != "chest" {
+				t.Errorf("chest tile = %+v, %v", tile, ok)
+			}
+
+			if want := [][2]float32{{0, 0}, {32, 0}, {32, 16}}; !reflect.DeepEqual(zone.Polygon, want) {
+				t.Errorf("zone polygon = %v, want %v", zone.Polygon, want)
+			}
+			if !zone.Visible {
+				t.Error("zone hidden")
+			}
+
+			if !secret.Ellipse || secret.Visible {
+				t.Errorf("secret = %+v", secret)
+			}
+		})
+	}
+}
+
+func TestCollision(t *testing.T) {
+	// Tile 1 of terrain has a collision property, tile 2 a collision
+	// shape; the hidden walls layer makes (1, 1) solid
+	want := []bool{
+		false, true, true, false,
+		true, true, false, true,
+		true, true, false, false,
+	}
+
+	for _, name := range fixtures {
+		t.Run(name, func(t *testing.T) {
+			m := loadFixture(t, name)
+			if got := m.CollisionGrid(); !reflect.DeepEqual(got, want) {
+				t.Errorf("collision grid = %v, want %v", got, want)
+			}
+			if m.Solid(-1, 0) || m.Solid(4, 2) {
+				t.Error("positions outside the map are solid")
+			}
+
+			// World y points up from the map's top-left corner
+			x, y := m.TileToWorld(1, 0)
+			if x != 24 || y != -8 || !m.SolidAt(x, y) {
+				t.Errorf("tile (1, 0) center (%v, %v), solid %v", x, y, m.SolidAt(x, y))
+			}
+			if tx, ty := m.WorldToTile(47.9, -16); tx != 2 || ty != 1 {
+				t.Errorf("WorldToTile = (%d, %d), want (2, 1)", tx, ty)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		err     string
+	}{
+		{"unknown format", "level.txt", "", "unknown tile map format"},
+		{"isometric", "iso.tmj", `{"orientation": "isometric", "width": 1, "height": 1, "tilewidth": 16, "tileheight": 16}`, "orthogonal"},
+		{"infinite", "inf.tmx", `<map orientation="orthogonal" infinite="1" width="1" height="1" tilewidth="16" tileheight="16"/>`, "infinite"},
+		{"tile count", "short.tmj", `{"width": 2, "height": 1, "tilewidth": 16, "tileheight": 16, "layers": [{"type": "tilelayer", "name": "a", "width": 2, "height": 1, "data": [1]}]}`, "expected 2"},
+		{"bad csv", "bad.tmx", `<map width="1" height="1" tilewidth="16" tileheight="16"><layer name="a" width="1" height="1"><data encoding="csv">x</data></layer></map>`, "invalid tile"},
+		{"missing tileset", "ext.tmj", `{"width": 1, "height": 1, "tilewidth": 16, "tileheight": 16, "tilesets": [{"firstgid": 1, "source": "missing.tsx"}]}`, "failed to read tileset"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := Load(path)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Load error = %v, want it to mention %q", err, tt.err)
+			}
+		})
+	}
+}
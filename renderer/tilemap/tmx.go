@@ -0,0 +1,305 @@
+package tilemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+)
+
+type tmxProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+	Text  string `xml:",chardata"` // Multiline strings are stored as text
+}
+
+type tmxMap struct {
+	Orientation string        `xml:"orientation,attr"`
+	Infinite    int           `xml:"infinite,attr"`
+	Width       int           `xml:"width,attr"`
+	Height      int           `xml:"height,attr"`
+	TileWidth   int           `xml:"tilewidth,attr"`
+	TileHeight  int           `xml:"tileheight,attr"`
+	Tilesets    []tmxTileset  `xml:"tileset"`
+	Properties  []tmxProperty `xml:"properties>property"`
+	Layers      []tmxLayer    `xml:",any"`
+}
+
+// tmxLayer holds any of the layer elements, told apart by XMLName:
+// layer, objectgroup, group and imagelayer
+type tmxLayer struct {
+	XMLName    xml.Name
+	Name       string        `xml:"name,attr"`
+	Width      int           `xml:"width,attr"`
+	Height     int           `xml:"height,attr"`
+	Visible    *int          `xml:"visible,attr"`
+	Opacity    *float32      `xml:"opacity,attr"`
+	OffsetX    float32       `xml:"offsetx,attr"`
+	OffsetY    float32       `xml:"offsety,attr"`
+	Data       tmxData       `xml:"data"`
+	Objects    []tmxObject   `xml:"object"`
+	Properties []tmxProperty `xml:"properties>property"`
+	Layers     []tmxLayer    `xml:",any"`
+}
+
+type tmxData struct {
+	Encoding    string `xml:"encoding,attr"`
+	Compression string `xml:"compression,attr"`
+	Text        string `xml:",chardata"`
+	Tiles       []struct {
+		GID uint32 `xml:"gid,attr"`
+	} `xml:"tile"`
+}
+
+type tmxPoints struct {
+	Points string `xml:"points,attr"`
+}
+
+type tmxObject struct {
+	ID         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	X          float32       `xml:"x,attr"`
+	Y          float32       `xml:"y,attr"`
+	Width      float32       `xml:"width,attr"`
+	Height     float32       `xml:"height,attr"`
+	Rotation   float32       `xml:"rotation,attr"`
+	GID        uint32        `xml:"gid,attr"`
+	Visible    *int          `xml:"visible,attr"`
+	Point      *struct{}     `xml:"point"`
+	Ellipse    *struct{}     `xml:"ellipse"`
+	Polygon    *tmxPoints    `xml:"polygon"`
+	Polyline   *tmxPoints    `xml:"polyline"`
+	Properties []tmxProperty `xml:"properties>property"`
+}
+
+type tmxImage struct {
+	Source string `xml:"source,attr"`
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+}
+
+type tmxTileset struct {
+	FirstGID   uint32        `xml:"firstgid,attr"`
+	Source     string        `xml:"source,attr"`
+	Name       string        `xml:"name,attr"`
+	TileWidth  int           `xml:"tilewidth,attr"`
+	TileHeight int           `xml:"tileheight,attr"`
+	Spacing    int           `xml:"spacing,attr"`
+	Margin     int           `xml:"margin,attr"`
+	Columns    int           `xml:"columns,attr"`
+	TileCount  int           `xml:"tilecount,attr"`
+	Image      tmxImage      `xml:"image"`
+	Tiles      []tmxTile     `xml:"tile"`
+	Properties []tmxProperty `xml:"properties>property"`
+}
+
+type tmxTile struct {
+	ID          int           `xml:"id,attr"`
+	Type        string        `xml:"type,attr"`
+	Class       string        `xml:"class,attr"`
+	Image       tmxImage      `xml:"image"`
+	ObjectGroup *tmxLayer     `xml:"objectgroup"`
+	Properties  []tmxProperty `xml:"properties>property"`
+}
+
+// LoadTMX reads a map saved in Tiled's XML format. External tilesets may
+// be XML (.tsx) or JSON (.tsj, .json).
+func LoadTMX(path string) (*Map, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tile map: %w", err)
+	}
+
+	var src tmxMap
+	if err := xml.Unmarshal(data, &src); err != nil {
+		return nil, fmt.Errorf("failed to parse tile map %s: %w", path, err)
+	}
+
+	m := &Map{
+		Path:       path,
+		Width:      src.Width,
+		Height:     src.Height,
+		TileWidth:  src.TileWidth,
+		TileHeight: src.TileHeight,
+		Properties: tmxProperties(src.Properties),
+	}
+	for _, ts := range src.Tilesets {
+		var tileset *Tileset
+		if ts.Source != "" {
+			tileset, err = loadTileset(resolvePath(path, ts.Source))
+		} else {
+			tileset, err = ts.convert(path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("tile map %s: %w", path, err)
+		}
+		tileset.FirstGID = ts.FirstGID
+		m.Tilesets = append(m.Tilesets, tileset)
+	}
+	if err := m.finish(src.Orientation, src.Infinite != 0); err != nil {
+		return nil, fmt.Errorf("tile map %s: %w", path, err)
+	}
+
+	if err := m.addTMXLayers(src.Layers, rootGroup); err != nil {
+		return nil, fmt.Errorf("tile map %s: %w", path, err)
+	}
+	return m, nil
+}
+
+func (m *Map) addTMXLayers(layers []tmxLayer, parent group) error {
+	for _, src := range layers {
+		visible := src.Visible == nil || *src.Visible != 0
+		opacity := float32(1)
+		if src.Opacity != nil {
+			opacity = *src.Opacity
+		}
+
+		switch src.XMLName.Local {
+		case "group":
+			if err := m.addTMXLayers(src.Layers, parent.nest(src.Name, src.OffsetX, src.OffsetY, opacity, visible)); err != nil {
+				return err
+			}
+		case "layer":
+			layer := parent.layer(TileLayer, src.Name, src.OffsetX, src.OffsetY, opacity, visible)
+			layer.Width, layer.Height = src.Width, src.Height
+			layer.Properties = tmxProperties(src.Properties)
+			tiles, err := src.Data.tiles(src.Width * src.Height)
+			if err != nil {
+				return fmt.Errorf("layer %s: %w", layer.Name, err)
+			}
+			layer.Tiles = tiles
+			m.Layers = append(m.Layers, layer)
+		case "objectgroup":
+			layer := parent.layer(ObjectLayer, src.Name, src.OffsetX, src.OffsetY, opacity, visible)
+			layer.Properties = tmxProperties(src.Properties)
+			for _, obj := range src.Objects {
+				converted, err := obj.convert()
+				if err != nil {
+					return fmt.Errorf("layer %s: %w", layer.Name, err)
+				}
+				layer.Objects = append(layer.Objects, converted)
+			}
+			m.Layers = append(m.Layers, layer)
+		}
+		// Image layers are not drawn
+	}
+	return nil
+}
+
+// tiles reads layer data written as CSV, base64 or, without an encoding,
+// one tile element per tile
+func (d *tmxData) tiles(count int) ([]uint32, error) {
+	if d.Encoding != "" {
+		return decodeTiles(d.Text, d.Encoding, d.Compression, count)
+	}
+
+	if len(d.Tiles) != count {
+		return nil, fmt.Errorf("layer has %d tiles, expected %d", len(d.Tiles), count)
+	}
+	tiles := make([]uint32, count)
+	for i, tile := range d.Tiles {
+		tiles[i] = tile.GID
+	}
+	return tiles, nil
+}
+
+func (o *tmxObject) convert() (Object, error) {
+	obj := Object{
+		ID:         o.ID,
+		Name:       o.Name,
+		Type:       o.Type,
+		X:          o.X,
+		Y:          o.Y,
+		Width:      o.Width,
+		Height:     o.Height,
+		Rotation:   o.Rotation,
+		GID:        o.GID,
+		Visible:    o.Visible == nil || *o.Visible != 0,
+		Point:      o.Point != nil,
+		Ellipse:    o.Ellipse != nil,
+		Properties: tmxProperties(o.Properties),
+	}
+	if obj.Type == "" {
+		obj.Type = o.Class
+	}
+
+	var err error
+	if o.Polygon != nil {
+		if obj.Polygon, err = parsePoints(o.Polygon.Points); err != nil {
+			return Object{}, fmt.Errorf("object %d: %w", o.ID, err)
+		}
+	}
+	if o.Polyline != nil {
+		if obj.Polyline, err = parsePoints(o.Polyline.Points); err != nil {
+			return Object{}, fmt.Errorf("object %d: %w", o.ID, err)
+		}
+	}
+	return obj, nil
+}
+
+func (ts *tmxTileset) convert(path string) (*Tileset, error) {
+	tileset := &Tileset{
+		Name:        ts.Name,
+		TileWidth:   ts.TileWidth,
+		TileHeight:  ts.TileHeight,
+		Spacing:     ts.Spacing,
+		Margin:      ts.Margin,
+		Columns:     ts.Columns,
+		TileCount:   ts.TileCount,
+		Image:       resolvePath(path, ts.Image.Source),
+		ImageWidth:  ts.Image.Width,
+		ImageHeight: ts.Image.Height,
+		Tiles:       make(map[int]*TileInfo),
+		Properties:  tmxProperties(ts.Properties),
+	}
+	for _, tile := range ts.Tiles {
+		info := &TileInfo{
+			ID:          tile.ID,
+			Type:        tile.Type,
+			Image:       resolvePath(path, tile.Image.Source),
+			ImageWidth:  tile.Image.Width,
+			ImageHeight: tile.Image.Height,
+			Properties:  tmxProperties(tile.Properties),
+		}
+		if info.Type == "" {
+			info.Type = tile.Class
+		}
+		if tile.ObjectGroup != nil {
+			for _, obj := range tile.ObjectGroup.Objects {
+				shape, err := obj.convert()
+				if err != nil {
+					return nil, fmt.Errorf("tileset %s tile %d: %w", ts.Name, tile.ID, err)
+				}
+				info.Shapes = append(info.Shapes, shape)
+			}
+		}
+		tileset.Tiles[tile.ID] = info
+	}
+	return tileset, nil
+}
+
+// loadTSX reads an external tileset in Tiled's XML format
+func loadTSX(path string) (*Tileset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tileset: %w", err)
+	}
+	var src tmxTileset
+	if err := xml.Unmarshal(data, &src); err != nil {
+		return nil, fmt.Errorf("failed to parse tileset %s: %w", path, err)
+	}
+	return src.convert(path)
+}
+
+func tmxProperties(props []tmxProperty) Properties {
+	properties := make(Properties, len(props))
+	for _, prop := range props {
+		value := prop.Value
+		if value == "" {
+			value = prop.Text
+		}
+		properties[prop.Name] = value
+	}
+	return properties
+}
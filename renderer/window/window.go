@@ -19,6 +19,12 @@ func init() {
 }
 
 func New(width, height int, title string) (*Window, error) {
+	return NewWithDebugContext(width, height, title, false)
+}
+
+// NewWithDebugContext creates a window whose OpenGL context optionally
+// has the debug flag set, so the driver reports errors in full
+func NewWithDebugContext(width, height int, title string, debug bool) (*Window, error) {
 	if err := glfw.Init(); err != nil {
 		return nil, fmt.Errorf("failed to initialize GLFW: %w", err)
 	}
@@ -28,6 +34,11 @@ func New(width, height int, title string) (*Window, error) {
 	glfw.WindowHint(glfw.ContextVersionMinor, 1)
 	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
 	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
+	if debug {
+		glfw.WindowHint(glfw.OpenGLDebugContext, glfw.True)
+	} else {
+		glfw.WindowHint(glfw.OpenGLDebugContext, glfw.False)
+	}
 
 	handle, err := glfw.CreateWindow(width, height, title, nil, nil)
 	if err != nil {
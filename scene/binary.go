@@ -0,0 +1,414 @@
+package scene
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// BinaryExtension marks scene files saved in the binary format
+const BinaryExtension = ".bsc"
+
+// binaryMagic starts every binary scene file
+const binaryMagic = "BSCN"
+
+// maxBinaryLength bounds strings and component data, so a corrupt length
+// fails instead of allocating without limit
+const maxBinaryLength = 1 << 26
+
+// The binary format holds the same document as a JSON scene file, for
+// builds that load scenes at runtime. Integers are varints, zig-zag
+// encoded if signed, floats little-endian float32s, and strings are
+// length-prefixed bytes:
+//
+//	magic "BSCN", version, name, skybox, clear color (4 floats)
+//	type count, then per type: name, encoding (1 byte)
+//	entity count, then per entity:
+//	  id, parent id (0 for none), name, active (1 byte)
+//	  position, rotation, scale (9 floats)
+//	  component count, then per component: type index, data
+//
+// Built-in component types are encoded field by field, as componentCodecs
+// lays out. Types registered by games, and unregistered ones, have no
+// binary encoding and keep their compact JSON as a length-prefixed string.
+//
+// Entities can be created as they are read, so loading never holds more
+// than one entity of the file. A binary file must match FormatVersion;
+// files from older versions are converted again from their JSON source.
+
+// Component type encodings
+const (
+	encodingJSON   byte = 0
+	encodingFields byte = 1
+)
+
+// WriteBinary writes the scene as a binary scene file
+func (s *Scene) WriteBinary(w io.Writer) error {
+	document, err := s.document()
+	if err != nil {
+		return err
+	}
+	return writeBinary(document, w)
+}
+
+// ReadBinary adds the entities of a binary scene file to the scene as they
+// are read, and takes on its environment
+func (s *Scene) ReadBinary(r io.Reader) error {
+	stream, err := openBinary(bufio.NewReader(r))
+	if err != nil {
+		return err
+	}
+	return s.read(stream)
+}
+
+// ConvertToBinary converts a JSON scene file, migrating it if needed, to
+// the binary format
+func ConvertToBinary(r io.Reader, w io.Writer) error {
+	stream, err := openScene(r)
+	if err != nil {
+		return err
+	}
+	document, err := stream.readAll()
+	if err != nil {
+		return err
+	}
+	return writeBinary(document, w)
+}
+
+// ConvertToJSON converts a binary scene file to JSON
+func ConvertToJSON(r io.Reader, w io.Writer) error {
+	stream, err := openScene(r)
+	if err != nil {
+		return err
+	}
+	document, err := stream.readAll()
+	if err != nil {
+		return err
+	}
+	if err := document.encodeComponents(); err != nil {
+		return err
+	}
+	return writeJSON(document, w)
+}
+
+// encodeComponents gives the components read field by field from a binary
+// file their JSON data
+func (document *sceneDocument) encodeComponents() error {
+	for i := range document.Entities {
+		entity := &document.Entities[i]
+		for j := range entity.Components {
+			c := &entity.Components[j]
+			if c.component == nil {
+				continue
+			}
+			data, err := json.Marshal(c.component)
+			if err != nil {
+				return fmt.Errorf("failed to encode %s component of entity '%s': %w", c.Type, entity.Name, err)
+			}
+			c.Data, c.component = data, nil
+		}
+	}
+	return nil
+}
+
+// binaryWriter writes the binary format, keeping the first error
+type binaryWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (bw *binaryWriter) bytes(b []byte) {
+	if bw.err == nil {
+		_, bw.err = bw.w.Write(b)
+	}
+}
+
+func (bw *binaryWriter) uint(v uint64) {
+	bw.bytes(binary.AppendUvarint(nil, v))
+}
+
+func (bw *binaryWriter) string(s string) {
+	bw.uint(uint64(len(s)))
+	bw.bytes([]byte(s))
+}
+
+func (bw *binaryWriter) int(v int) {
+	bw.bytes(binary.AppendVarint(nil, int64(v)))
+}
+
+func (bw *binaryWriter) ints(values ...int) {
+	for _, v := range values {
+		bw.int(v)
+	}
+}
+
+func (bw *binaryWriter) bool(v bool) {
+	b := byte(0)
+	if v {
+		b = 1
+	}
+	bw.bytes([]byte{b})
+}
+
+// count writes the length of a slice or map, telling nil apart from empty
+// as encoding/json does
+func (bw *binaryWriter) count(n int, isNil bool) {
+	if isNil {
+		bw.uint(0)
+		return
+	}
+	bw.uint(uint64(n) + 1)
+}
+
+func (bw *binaryWriter) floats(values ...float32) {
+	var b [4]byte
+	for _, v := range values {
+		binary.LittleEndian.PutUint32(b[:], math.Float32bits(v))
+		bw.bytes(b[:])
+	}
+}
+
+func writeBinary(document *sceneDocument, w io.Writer) error {
+	// Type names are written once and referred to by index
+	var types []string
+	typeIndex := make(map[string]uint64)
+	codecs := make(map[string]componentCodec)
+	for _, entity := range document.Entities {
+		for _, c := range entity.Components {
+			if _, exists := typeIndex[c.Type]; !exists {
+				typeIndex[c.Type] = uint64(len(types))
+				types = append(types, c.Type)
+				if codec, fields := codecFor(c.Type); fields {
+					codecs[c.Type] = codec
+				}
+			}
+		}
+	}
+
+	bw := &binaryWriter{w: bufio.NewWriter(w)}
+	bw.bytes([]byte(binaryMagic))
+	bw.uint(uint64(document.Version))
+	bw.string(document.Name)
+	bw.string(document.Environment.Skybox)
+	bw.floats(document.Environment.ClearColor[:]...)
+	bw.uint(uint64(len(types)))
+	for _, name := range types {
+		bw.string(name)
+		encoding := encodingJSON
+		if _, fields := codecs[name]; fields {
+			encoding = encodingFields
+		}
+		bw.bytes([]byte{encoding})
+	}
+
+	bw.uint(uint64(len(document.Entities)))
+	var data bytes.Buffer
+	for _, entity := range document.Entities {
+		bw.uint(entity.ID)
+		bw.uint(entity.Parent)
+		bw.string(entity.Name)
+		bw.bool(entity.Active)
+		t := entity.Transform
+		bw.floats(t.Position[:]...)
+		bw.floats(t.Rotation[:]...)
+		bw.floats(t.Scale[:]...)
+
+		bw.uint(uint64(len(entity.Components)))
+		for _, c := range entity.Components {
+			bw.uint(typeIndex[c.Type])
+			if codec, fields := codecs[c.Type]; fields {
+				component, err := c.decode()
+				if err != nil {
+					return fmt.Errorf("failed to encode %s component of entity '%s': %w", c.Type, entity.Name, err)
+				}
+				codec.write(bw, component)
+				continue
+			}
+			data.Reset()
+			if err := json.Compact(&data, c.Data); err != nil {
+				return fmt.Errorf("invalid %s component data of entity '%s': %w", c.Type, entity.Name, err)
+			}
+			bw.string(data.String())
+		}
+	}
+	if bw.err == nil {
+		bw.err = bw.w.Flush()
+	}
+	if bw.err != nil {
+		return fmt.Errorf("failed to write binary scene: %w", bw.err)
+	}
+	return nil
+}
+
+// binaryReader reads the binary format, keeping the first error
+type binaryReader struct {
+	r   *bufio.Reader
+	err error
+}
+
+func (br *binaryReader) uint() uint64 {
+	if br.err != nil {
+		return 0
+	}
+	var v uint64
+	v, br.err = binary.ReadUvarint(br.r)
+	return v
+}
+
+func (br *binaryReader) bytes(n uint64) []byte {
+	if br.err != nil {
+		return nil
+	}
+	if n > maxBinaryLength {
+		br.err = fmt.Errorf("length %d is too long", n)
+		return nil
+	}
+	b := make([]byte, n)
+	_, br.err = io.ReadFull(br.r, b)
+	return b
+}
+
+func (br *binaryReader) string() string {
+	return string(br.bytes(br.uint()))
+}
+
+func (br *binaryReader) int() int {
+	if br.err != nil {
+		return 0
+	}
+	var v int64
+	v, br.err = binary.ReadVarint(br.r)
+	return int(v)
+}
+
+func (br *binaryReader) ints(values []int) {
+	for i := range values {
+		values[i] = br.int()
+	}
+}
+
+func (br *binaryReader) bool() bool {
+	b := br.bytes(1)
+	return br.err == nil && b[0] == 1
+}
+
+// count reads the length of a slice or map written by binaryWriter.count
+func (br *binaryReader) count() (n int, isNil bool) {
+	v := br.uint()
+	if br.err == nil && v > maxBinaryLength {
+		br.err = fmt.Errorf("count %d is too large", v)
+	}
+	if br.err != nil || v == 0 {
+		return 0, true
+	}
+	return int(v - 1), false
+}
+
+func (br *binaryReader) floats(values []float32) {
+	for i := range values {
+		b := br.bytes(4)
+		if br.err != nil {
+			return
+		}
+		values[i] = math.Float32frombits(binary.LittleEndian.Uint32(b))
+	}
+}
+
+func (br *binaryReader) float() float32 {
+	var v [1]float32
+	br.floats(v[:])
+	return v[0]
+}
+
+// openBinary reads a binary scene file's header, leaving its entities to
+// be streamed
+func openBinary(r *bufio.Reader) (*sceneStream, error) {
+	br := &binaryReader{r: r}
+	if string(br.bytes(uint64(len(binaryMagic)))) != binaryMagic {
+		return nil, fmt.Errorf("not a binary scene file")
+	}
+	version := br.uint()
+	if br.err == nil && version != FormatVersion {
+		return nil, fmt.Errorf("binary scene format version %d does not match version %d; convert it again from JSON", version, FormatVersion)
+	}
+
+	header := &sceneDocument{Format: formatName, Version: FormatVersion}
+	header.Name = br.string()
+	header.Environment.Skybox = br.string()
+	br.floats(header.Environment.ClearColor[:])
+	count := br.uint()
+	types := make([]string, 0, min(count, 1024))
+	codecs := make(map[string]componentCodec)
+	for i := uint64(0); i < count && br.err == nil; i++ {
+		name := br.string()
+		types = append(types, name)
+		switch encoding := br.bytes(1); {
+		case br.err != nil:
+		case encoding[0] == encodingFields:
+			codec, exists := codecFor(name)
+			if !exists {
+				br.err = fmt.Errorf("component type '%s' has no binary encoding", name)
+			}
+			codecs[name] = codec
+		case encoding[0] != encodingJSON:
+			br.err = fmt.Errorf("unknown encoding %d of component type '%s'", encoding[0], name)
+		}
+	}
+	remaining := br.uint()
+	if br.err != nil {
+		return nil, fmt.Errorf("failed to read binary scene: %w", unexpected(br.err))
+	}
+
+	next := func() (entityDocument, bool, error) {
+		if remaining == 0 {
+			return entityDocument{}, false, nil
+		}
+		remaining--
+
+		var entity entityDocument
+		entity.ID = br.uint()
+		entity.Parent = br.uint()
+		entity.Name = br.string()
+		entity.Active = br.bool()
+		br.floats(entity.Transform.Position[:])
+		br.floats(entity.Transform.Rotation[:])
+		br.floats(entity.Transform.Scale[:])
+		for n := br.uint(); n > 0 && br.err == nil; n-- {
+			index := br.uint()
+			if br.err == nil && index >= uint64(len(types)) {
+				br.err = fmt.Errorf("component type index %d out of range", index)
+			}
+			if br.err != nil {
+				break
+			}
+			name := types[index]
+			codec, ok := codecs[name]
+			if !ok {
+				data := br.bytes(br.uint())
+				entity.Components = append(entity.Components, componentDocument{Type: name, Data: data})
+				continue
+			}
+			component, _ := newComponent(name)
+			codec.read(br, component)
+			entity.Components = append(entity.Components, componentDocument{Type: name, component: component})
+		}
+		if br.err != nil {
+			return entity, false, fmt.Errorf("failed to read binary scene: %w", unexpected(br.err))
+		}
+		return entity, true, nil
+	}
+	return &sceneStream{header: header, next: next}, nil
+}
+
+// unexpected reports a file that ends early as truncated
+func unexpected(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,263 @@
+package scene
+
+import "sort"
+
+// componentCodec writes and reads one built-in component type field by
+// field. Fields follow the order the type declares them in, and only those
+// encoding/json saves are written.
+type componentCodec struct {
+	accepts func(component Component) bool
+	write   func(bw *binaryWriter, component Component)
+	read    func(br *binaryReader, component Component)
+}
+
+// codecOf makes a codec of a component type's write and read functions,
+// which read into a component made by the type's registered factory
+func codecOf[T Component](write func(bw *binaryWriter, c T), read func(br *binaryReader, c T)) componentCodec {
+	return componentCodec{
+		accepts: func(component Component) bool {
+			_, ok := component.(T)
+			return ok
+		},
+		write: func(bw *binaryWriter, component Component) { write(bw, component.(T)) },
+		read:  func(br *binaryReader, component Component) { read(br, component.(T)) },
+	}
+}
+
+// codecFor returns the binary encoding of a component type, unless it is
+// not built in or a game has registered its own type under the name
+func codecFor(name string) (componentCodec, bool) {
+	codec, exists := componentCodecs[name]
+	if !exists {
+		return codec, false
+	}
+	component, registered := newComponent(name)
+	return codec, registered && codec.accepts(component)
+}
+
+// componentCodecs holds the binary encodings of the built-in component
+// types by type name. Changing one changes the binary format, so it needs
+// a new FormatVersion.
+var componentCodecs = map[string]componentCodec{
+	"Mesh": codecOf(
+		func(bw *binaryWriter, c *MeshComponent) {
+			bw.string(c.MeshType)
+			bw.bool(c.Visible)
+			bw.floats(c.Color[:]...)
+			bw.floats(c.Opacity)
+			bw.int(c.Layer)
+		},
+		func(br *binaryReader, c *MeshComponent) {
+			c.MeshType = br.string()
+			c.Visible = br.bool()
+			br.floats(c.Color[:])
+			c.Opacity = br.float()
+			c.Layer = br.int()
+		}),
+	"Camera": codecOf(
+		func(bw *binaryWriter, c *CameraComponent) {
+			bw.floats(c.FOV, c.NearPlane, c.FarPlane, c.AspectRatio)
+			bw.bool(c.Active)
+		},
+		func(br *binaryReader, c *CameraComponent) {
+			c.FOV = br.float()
+			c.NearPlane = br.float()
+			c.FarPlane = br.float()
+			c.AspectRatio = br.float()
+			c.Active = br.bool()
+		}),
+	"Light": codecOf(
+		func(bw *binaryWriter, c *LightComponent) {
+			bw.string(c.Type)
+			bw.floats(c.Color[:]...)
+			bw.floats(c.Intensity, c.Range)
+		},
+		func(br *binaryReader, c *LightComponent) {
+			c.Type = br.string()
+			br.floats(c.Color[:])
+			c.Intensity = br.float()
+			c.Range = br.float()
+		}),
+	"Script": codecOf(
+		func(bw *binaryWriter, c *ScriptComponent) {
+			bw.string(c.Name)
+		},
+		func(br *binaryReader, c *ScriptComponent) {
+			c.Name = br.string()
+		}),
+	"Sprite": codecOf(
+		func(bw *binaryWriter, c *SpriteComponent) {
+			bw.string(c.Texture)
+			bw.ints(c.Region[:]...)
+			bw.floats(c.Size[:]...)
+			bw.floats(c.Origin[:]...)
+			bw.floats(c.Tint[:]...)
+			bw.bool(c.FlipX)
+			bw.bool(c.FlipY)
+			bw.int(c.Layer)
+			bw.bool(c.Visible)
+		},
+		func(br *binaryReader, c *SpriteComponent) {
+			c.Texture = br.string()
+			br.ints(c.Region[:])
+			br.floats(c.Size[:])
+			br.floats(c.Origin[:])
+			br.floats(c.Tint[:])
+			c.FlipX = br.bool()
+			c.FlipY = br.bool()
+			c.Layer = br.int()
+			c.Visible = br.bool()
+		}),
+	"SpriteAnimation": codecOf(
+		func(bw *binaryWriter, c *SpriteAnimationComponent) {
+			bw.count(len(c.Animations), c.Animations == nil)
+			for _, name := range sortedKeys(c.Animations) {
+				bw.string(name)
+				animation := c.Animations[name]
+				bw.bool(animation != nil)
+				if animation == nil {
+					continue
+				}
+				bw.count(len(animation.Frames), animation.Frames == nil)
+				for _, frame := range animation.Frames {
+					bw.ints(frame[:]...)
+				}
+				bw.floats(animation.FPS)
+				bw.int(int(animation.Mode))
+			}
+			bw.string(c.Current)
+			bw.bool(c.Playing)
+			bw.floats(c.Speed)
+		},
+		func(br *binaryReader, c *SpriteAnimationComponent) {
+			n, isNil := br.count()
+			c.Animations = nil
+			if !isNil {
+				c.Animations = make(map[string]*SpriteAnimation, min(n, 1024))
+			}
+			for ; n > 0 && br.err == nil; n-- {
+				name := br.string()
+				if !br.bool() {
+					c.Animations[name] = nil
+					continue
+				}
+				animation := &SpriteAnimation{}
+				frames, isNil := br.count()
+				if !isNil {
+					animation.Frames = make([][4]int, 0, min(frames, 1024))
+				}
+				for ; frames > 0 && br.err == nil; frames-- {
+					var frame [4]int
+					br.ints(frame[:])
+					animation.Frames = append(animation.Frames, frame)
+				}
+				animation.FPS = br.float()
+				animation.Mode = AnimationMode(br.int())
+				c.Animations[name] = animation
+			}
+			c.Current = br.string()
+			c.Playing = br.bool()
+			c.Speed = br.float()
+		}),
+	"Tilemap": codecOf(
+		func(bw *binaryWriter, c *TilemapComponent) {
+			bw.string(c.Map)
+			bw.int(c.Layer)
+			bw.bool(c.Visible)
+		},
+		func(br *binaryReader, c *TilemapComponent) {
+			c.Map = br.string()
+			c.Layer = br.int()
+			c.Visible = br.bool()
+		}),
+	"TileObject": codecOf(
+		func(bw *binaryWriter, c *TileObjectComponent) {
+			bw.int(c.ObjectID)
+			bw.string(c.Type)
+			bw.string(c.Layer)
+			bw.floats(c.Size[:]...)
+			bw.count(len(c.Properties), c.Properties == nil)
+			for _, key := range sortedKeys(c.Properties) {
+				bw.string(key)
+				bw.string(c.Properties[key])
+			}
+		},
+		func(br *binaryReader, c *TileObjectComponent) {
+			c.ObjectID = br.int()
+			c.Type = br.string()
+			c.Layer = br.string()
+			br.floats(c.Size[:])
+			n, isNil := br.count()
+			c.Properties = nil
+			if !isNil {
+				c.Properties = make(map[string]string, min(n, 1024))
+			}
+			for ; n > 0 && br.err == nil; n-- {
+				key := br.string()
+				c.Properties[key] = br.string()
+			}
+		}),
+	"Text": codecOf(
+		func(bw *binaryWriter, c *TextComponent) {
+			bw.string(c.Text)
+			bw.string(c.Font)
+			bw.floats(c.Size)
+			bw.floats(c.Color[:]...)
+			bw.floats(c.MaxWidth)
+			bw.int(int(c.Align))
+			bw.floats(c.Origin[:]...)
+			bw.bool(c.DistanceField)
+			bw.int(c.Layer)
+			bw.bool(c.Visible)
+		},
+		func(br *binaryReader, c *TextComponent) {
+			c.Text = br.string()
+			c.Font = br.string()
+			c.Size = br.float()
+			br.floats(c.Color[:])
+			c.MaxWidth = br.float()
+			c.Align = TextAlign(br.int())
+			br.floats(c.Origin[:])
+			c.DistanceField = br.bool()
+			c.Layer = br.int()
+			c.Visible = br.bool()
+		}),
+	prefabInstanceType: codecOf(
+		func(bw *binaryWriter, c *PrefabInstanceComponent) {
+			bw.string(c.Prefab)
+			bw.count(len(c.Overrides), c.Overrides == nil)
+			for _, override := range c.Overrides {
+				bw.string(override.Path)
+				bw.string(override.Component)
+				bw.int(override.Index)
+				bw.string(override.Field)
+			}
+		},
+		func(br *binaryReader, c *PrefabInstanceComponent) {
+			c.Prefab = br.string()
+			n, isNil := br.count()
+			c.Overrides = nil
+			if !isNil {
+				c.Overrides = make([]PrefabOverride, 0, min(n, 1024))
+			}
+			for ; n > 0 && br.err == nil; n-- {
+				var override PrefabOverride
+				override.Path = br.string()
+				override.Component = br.string()
+				override.Index = br.int()
+				override.Field = br.string()
+				c.Overrides = append(c.Overrides, override)
+			}
+		}),
+}
+
+// sortedKeys returns a map's keys in order, so encoding a map gives the
+// same bytes every time
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
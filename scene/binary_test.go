@@ -0,0 +1,382 @@
+package scene
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/iotest"
+
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+)
+
+// newSerializationScene builds a scene using hierarchy, inactive entities,
+// repeated components, every built-in component type and an unregistered
+// one
+func newSerializationScene() *Scene {
+	s := NewScene("Round Trip")
+	s.SetEnvironment(Environment{Skybox: "skies/day", ClearColor: [4]float32{0.2, 0.3, 0.4, 1}})
+
+	player := s.CreateEntity("Player")
+	player.Transform.SetPosition(bmath.NewVector3(1.5, -2, 0.25))
+	player.Transform.SetRotation(bmath.NewVector3(0, 90, 12.5))
+	Add(player, NewMeshComponent("cube")).SetColor(0.1, 0.2, 0.3)
+	Add(player, NewScriptComponent("move"))
+	Add(player, NewScriptComponent("jump"))
+	Add(player, NewCameraComponent(60, 0.1, 500, 16.0/9.0))
+	Add(player, NewLightComponent("point"))
+
+	arm := s.CreateEntity("Arm")
+	s.SetParentKeepLocal(arm, player)
+	arm.Transform.SetScale(bmath.NewVector3(2, 2, 2))
+	arm.SetActive(false)
+	sprite := Add(arm, NewSpriteComponent("sprites/hero.png"))
+	sprite.SetRegion(16, 0, 16, 32)
+	animation := Add(arm, NewSpriteAnimationComponent())
+	animation.AddAnimation("run", [][4]int{{0, 0, 16, 32}, {16, 0, 16, 32}}, 12, AnimationPingPong)
+	animation.Play("run")
+
+	hand := s.CreateEntity("Hand")
+	s.SetParentKeepLocal(hand, arm)
+	Add(hand, NewTextComponent("Hello, wörld", 0.5)).Align = TextAlignCenter
+	hand.AddComponent(&RawComponent{TypeName: "Inventory", Data: []byte(`{"slots": [1, 2, 3]}`)})
+
+	level := s.CreateEntity("Level")
+	Add(level, NewTilemapComponent("maps/level1.tmx"))
+	Add(level, &TileObjectComponent{ObjectID: 7, Type: "spawn", Layer: "objects", Properties: map[string]string{"team": "red"}})
+	Add(level, &TileObjectComponent{ObjectID: -8, Type: "marker", Properties: map[string]string{}})
+	Add(level, &TileObjectComponent{ObjectID: 9, Type: "note"})
+	return s
+}
+
+// weaponComponent is a game's own component type, which the binary format
+// keeps as JSON
+type weaponComponent struct {
+	Damage int    `json:"damage"`
+	Kind   string `json:"kind"`
+}
+
+func (w *weaponComponent) GetType() string          { return "Weapon" }
+func (w *weaponComponent) Update(deltaTime float32) {}
+
+func TestBinaryComponentEncodings(t *testing.T) {
+	defer registerTemporarily("Weapon", func() Component { return &weaponComponent{} })()
+	s := newSerializationScene()
+	Add(s.Find("Player"), &weaponComponent{Damage: 12, Kind: "sword"})
+
+	var binary bytes.Buffer
+	if err := s.WriteBinary(&binary); err != nil {
+		t.Fatal(err)
+	}
+	// Built-in components are written field by field, others as JSON
+	for _, field := range []string{"MeshType", "Texture", "Animations", "Properties", "Visible"} {
+		if bytes.Contains(binary.Bytes(), []byte(field)) {
+			t.Errorf("binary scene holds the JSON field %s", field)
+		}
+	}
+	for _, data := range []string{`{"damage":12,"kind":"sword"}`, `{"slots":[1,2,3]}`} {
+		if !bytes.Contains(binary.Bytes(), []byte(data)) {
+			t.Errorf("binary scene is missing the JSON %s", data)
+		}
+	}
+
+	loaded := NewScene("Round Trip")
+	if err := loaded.ReadBinary(bytes.NewReader(binary.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	player := loaded.Find("Player")
+	if weapon, ok := Get[*weaponComponent](player); !ok || *weapon != (weaponComponent{Damage: 12, Kind: "sword"}) {
+		t.Errorf("weapon = %v", weapon)
+	}
+	if mesh, ok := Get[*MeshComponent](player); !ok || mesh.Color != [3]float32{0.1, 0.2, 0.3} || !mesh.Visible {
+		t.Errorf("mesh = %v", mesh)
+	}
+	objects := GetAll[*TileObjectComponent](loaded.Find("Level"))
+	if len(objects) != 3 || objects[0].Properties["team"] != "red" || objects[1].ObjectID != -8 {
+		t.Fatalf("tile objects = %v", objects)
+	}
+	if objects[1].Properties == nil || objects[2].Properties != nil {
+		t.Errorf("empty and nil properties not kept apart: %v, %v", objects[1].Properties, objects[2].Properties)
+	}
+}
+
+// fill sets every field encoding/json saves to a distinct non-zero value
+func fill(t *testing.T, v reflect.Value, next *int) {
+	t.Helper()
+	*next++
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		fill(t, v.Elem(), next)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.IsExported() && field.Tag.Get("json") != "-" {
+				fill(t, v.Field(i), next)
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			fill(t, v.Index(i), next)
+		}
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 2, 2))
+		for i := 0; i < v.Len(); i++ {
+			fill(t, v.Index(i), next)
+		}
+	case reflect.Map:
+		v.Set(reflect.MakeMap(v.Type()))
+		for i := 0; i < 2; i++ {
+			key, value := reflect.New(v.Type().Key()).Elem(), reflect.New(v.Type().Elem()).Elem()
+			fill(t, key, next)
+			fill(t, value, next)
+			v.SetMapIndex(key, value)
+		}
+	case reflect.String:
+		v.SetString(fmt.Sprint("value ", *next))
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(int64(*next))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(float64(*next) + 0.5)
+	default:
+		t.Fatalf("cannot fill a %s field", v.Type())
+	}
+}
+
+// TestBinaryCodecFields checks every field encoding/json saves survives
+// the binary format, so a field added to a built-in component without
+// its codec learning it is caught
+func TestBinaryCodecFields(t *testing.T) {
+	for _, name := range sortedKeys(componentCodecs) {
+		t.Run(name, func(t *testing.T) {
+			codec, ok := codecFor(name)
+			if !ok {
+				t.Fatal("codec not in use")
+			}
+			original, _ := newComponent(name)
+			next := 0
+			fill(t, reflect.ValueOf(original), &next)
+
+			var loaded Component
+			if name == prefabInstanceType {
+				// Loading a scene would try to open the prefab, so the codec
+				// is run on its own
+				var buf bytes.Buffer
+				bw := &binaryWriter{w: bufio.NewWriter(&buf)}
+				codec.write(bw, original)
+				if bw.err != nil || bw.w.Flush() != nil {
+					t.Fatal("write failed")
+				}
+				loaded, _ = newComponent(name)
+				br := &binaryReader{r: bufio.NewReader(&buf)}
+				codec.read(br, loaded)
+				if br.err != nil {
+					t.Fatal(br.err)
+				}
+			} else {
+				s := NewScene("Fields")
+				s.CreateEntity("e").AddComponent(original)
+				var buf bytes.Buffer
+				if err := s.WriteBinary(&buf); err != nil {
+					t.Fatal(err)
+				}
+				if bytes.Contains(buf.Bytes(), []byte(`"value `)) {
+					t.Error("component written as JSON")
+				}
+				s = NewScene("Fields")
+				if err := s.ReadBinary(bytes.NewReader(buf.Bytes())); err != nil {
+					t.Fatal(err)
+				}
+				loaded = s.Find("e").GetComponent(name)
+			}
+
+			want, err := json.Marshal(original)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := json.Marshal(loaded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("binary round trip gave\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+// chunkedTilemap is a game's own type under a built-in type name
+type chunkedTilemap struct {
+	Chunks int `json:"chunks"`
+}
+
+func (c *chunkedTilemap) GetType() string          { return "Tilemap" }
+func (c *chunkedTilemap) Update(deltaTime float32) {}
+
+func TestBinaryReregisteredBuiltin(t *testing.T) {
+	// The built-in encoding does not fit the game's type, so it is JSON
+	RegisterComponent("Tilemap", func() Component { return &chunkedTilemap{} })
+	defer RegisterComponent("Tilemap", func() Component { return NewTilemapComponent("") })
+
+	s := NewScene("Reregistered")
+	Add(s.CreateEntity("Level"), &chunkedTilemap{Chunks: 3})
+	var original, binary, converted bytes.Buffer
+	if err := s.WriteJSON(&original); err != nil {
+		t.Fatal(err)
+	}
+	if err := ConvertToBinary(bytes.NewReader(original.Bytes()), &binary); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(binary.Bytes(), []byte(`{"chunks":3}`)) {
+		t.Error("reregistered type not written as JSON")
+	}
+	if err := ConvertToJSON(bytes.NewReader(binary.Bytes()), &converted); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(original.Bytes(), converted.Bytes()) {
+		t.Fatalf("JSON changed through the binary format:\n%s\nbecame\n%s", original.String(), converted.String())
+	}
+}
+
+func TestJSONBinaryJSONRoundTrip(t *testing.T) {
+	var original bytes.Buffer
+	if err := newSerializationScene().WriteJSON(&original); err != nil {
+		t.Fatal(err)
+	}
+
+	var binary, converted bytes.Buffer
+	if err := ConvertToBinary(bytes.NewReader(original.Bytes()), &binary); err != nil {
+		t.Fatal(err)
+	}
+	if err := ConvertToJSON(bytes.NewReader(binary.Bytes()), &converted); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(original.Bytes(), converted.Bytes()) {
+		t.Fatalf("JSON changed through the binary format:\n%s\nbecame\n%s", original.String(), converted.String())
+	}
+	if binary.Len() >= original.Len() {
+		t.Errorf("binary scene is %d bytes, JSON %d", binary.Len(), original.Len())
+	}
+}
+
+func TestJSONSceneRoundTrip(t *testing.T) {
+	var original, saved bytes.Buffer
+	if err := newSerializationScene().WriteJSON(&original); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewScene("Round Trip")
+	if err := loaded.ReadJSON(bytes.NewReader(original.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.WriteJSON(&saved); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(original.Bytes(), saved.Bytes()) {
+		t.Fatalf("scene changed through loading:\n%s\nbecame\n%s", original.String(), saved.String())
+	}
+}
+
+func TestBinaryStreamingLoad(t *testing.T) {
+	var original, binary, saved bytes.Buffer
+	if err := newSerializationScene().WriteJSON(&original); err != nil {
+		t.Fatal(err)
+	}
+	if err := newSerializationScene().WriteBinary(&binary); err != nil {
+		t.Fatal(err)
+	}
+
+	// A reader handing out one byte at a time proves nothing relies on
+	// having the whole file
+	loaded := NewScene("Round Trip")
+	if err := loaded.ReadBinary(iotest.OneByteReader(bytes.NewReader(binary.Bytes()))); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.WriteJSON(&saved); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(original.Bytes(), saved.Bytes()) {
+		t.Fatalf("scene changed through the binary format:\n%s\nbecame\n%s", original.String(), saved.String())
+	}
+
+	hand := loaded.Find("Player/Arm/Hand")
+	if hand == nil {
+		t.Fatal("hierarchy was not restored")
+	}
+	if scripts := GetAll[*ScriptComponent](hand.Root()); len(scripts) != 2 || scripts[1].Name != "jump" {
+		t.Errorf("scripts were not restored in order: %v", scripts)
+	}
+}
+
+func TestTruncatedBinaryScene(t *testing.T) {
+	var binary bytes.Buffer
+	if err := newSerializationScene().WriteBinary(&binary); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, length := range []int{2, binary.Len() / 2, binary.Len() - 1} {
+		s := NewScene("Truncated")
+		if err := s.ReadBinary(bytes.NewReader(binary.Bytes()[:length])); err == nil {
+			t.Errorf("reading %d of %d bytes succeeded", length, binary.Len())
+		}
+		if entities := s.GetEntities(); len(entities) != 0 {
+			t.Errorf("reading %d of %d bytes left %d entities behind", length, binary.Len(), len(entities))
+		}
+	}
+}
+
+func TestLoadSceneDetectsFormat(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"level.bifrost", "level" + BinaryExtension} {
+		path := filepath.Join(dir, name)
+		if err := newSerializationScene().SaveFile(path); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := NewSceneManager().LoadScene(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loaded.Name() != "Round Trip" || len(loaded.GetEntities()) != 4 {
+			t.Errorf("%s: loaded scene %q with %d entities", name, loaded.Name(), len(loaded.GetEntities()))
+		}
+	}
+}
+
+func benchmarkLoad(b *testing.B, write func(s *Scene, buf *bytes.Buffer) error, read func(s *Scene, data []byte) error) {
+	s := NewScene("Benchmark")
+	for i := 0; i < benchmarkEntities; i++ {
+		entity := s.CreateEntity(fmt.Sprintf("Entity %d", i))
+		entity.Transform.SetPosition(bmath.NewVector3(float32(i), 0, float32(-i)))
+		Add(entity, NewMeshComponent("cube"))
+	}
+	var buf bytes.Buffer
+	if err := write(s, &buf); err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(buf.Len()))
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if err := read(NewScene("Benchmark"), buf.Bytes()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLoadJSON(b *testing.B) {
+	benchmarkLoad(b,
+		func(s *Scene, buf *bytes.Buffer) error { return s.WriteJSON(buf) },
+		func(s *Scene, data []byte) error { return s.ReadJSON(bytes.NewReader(data)) })
+}
+
+func BenchmarkLoadBinary(b *testing.B) {
+	benchmarkLoad(b,
+		func(s *Scene, buf *bytes.Buffer) error { return s.WriteBinary(buf) },
+		func(s *Scene, data []byte) error { return s.ReadBinary(bytes.NewReader(data)) })
+}
@@ -5,6 +5,8 @@ type MeshComponent struct {
 	MeshType string // "cube", "triangle", "sphere", etc.
 	Visible  bool
 	Color    [3]float32
+	Opacity  float32 // Values below 1 are drawn in the transparent pass
+	Layer    int     // Lower layers are drawn first
 }
 
 // NewMeshComponent creates a new mesh component
@@ -13,6 +15,7 @@ func NewMeshComponent(meshType string) *MeshComponent {
 		MeshType: meshType,
 		Visible:  true,
 		Color:    [3]float32{1.0, 1.0, 1.0},
+		Opacity:  1.0,
 	}
 }
 
@@ -33,6 +36,16 @@ func (m *MeshComponent) SetColor(r, g, b float32) {
 	m.Color[2] = b
 }
 
+// SetOpacity sets the mesh opacity
+func (m *MeshComponent) SetOpacity(opacity float32) {
+	m.Opacity = opacity
+}
+
+// IsTransparent reports whether the mesh needs blending
+func (m *MeshComponent) IsTransparent() bool {
+	return m.Opacity < 1.0
+}
+
 // CameraComponent represents a camera attached to an entity
 type CameraComponent struct {
 	FOV         float32
@@ -91,12 +104,13 @@ func (l *LightComponent) Update(deltaTime float32) {
 	// Light updates handled by render system
 }
 
-// ScriptComponent allows custom behavior
+// ScriptComponent allows custom behavior. Only the name is saved with a
+// scene; game code attaches the functions again after loading.
 type ScriptComponent struct {
 	Name     string
-	OnStart  func(entity *Entity)
-	OnUpdate func(entity *Entity, deltaTime float32)
-	Started  bool
+	OnStart  func(entity *Entity)                    `json:"-"`
+	OnUpdate func(entity *Entity, deltaTime float32) `json:"-"`
+	Started  bool                                    `json:"-"`
 }
 
 // NewScriptComponent creates a new script component
@@ -115,4 +129,259 @@ func (s *ScriptComponent) GetType() string {
 // Update runs the script update function
 func (s *ScriptComponent) Update(deltaTime float32) {
 	// Script execution handled by script system
-}
\ No newline at end of file
+}
+
+// SpriteComponent draws an entity as a textured quad in 2D. The quad
+// follows the entity's world transform: position in x and y, rotation
+// about z, and scale multiplying the size.
+type SpriteComponent struct {
+	Texture string     // Image file path
+	Region  [4]int     // x, y, width, height in pixels; zero for the whole image
+	Size    [2]float32 // World size; zero uses the region size in pixels
+	Origin  [2]float32 // Pivot as a fraction of the size, (0.5, 0.5) is the center
+	Tint    [4]float32
+	FlipX   bool
+	FlipY   bool
+	Layer   int // Lower layers are drawn first
+	Visible bool
+}
+
+// NewSpriteComponent creates a centered, untinted sprite of an image
+func NewSpriteComponent(texture string) *SpriteComponent {
+	return &SpriteComponent{
+		Texture: texture,
+		Origin:  [2]float32{0.5, 0.5},
+		Tint:    [4]float32{1.0, 1.0, 1.0, 1.0},
+		Visible: true,
+	}
+}
+
+// GetType returns the component type
+func (s *SpriteComponent) GetType() string {
+	return "Sprite"
+}
+
+// Update updates the sprite component
+func (s *SpriteComponent) Update(deltaTime float32) {
+	// Sprites are drawn by the sprite render system
+}
+
+// SetRegion selects the part of the image to show, in pixels
+func (s *SpriteComponent) SetRegion(x, y, width, height int) {
+	s.Region = [4]int{x, y, width, height}
+}
+
+// AnimationMode controls what happens after the last frame of an animation
+type AnimationMode int
+
+const (
+	AnimationLoop     AnimationMode = iota // Start over from the first frame
+	AnimationPingPong                      // Play backwards to the first frame, then forwards again
+	AnimationOnce                          // Stop on the last frame
+)
+
+// SpriteAnimation is a sequence of sprite sheet regions played at a fixed rate
+type SpriteAnimation struct {
+	Frames [][4]int // x, y, width, height in pixels, as SpriteComponent.Region
+	FPS    float32
+	Mode   AnimationMode
+}
+
+// SpriteAnimationComponent flips an entity's sprite through the frames of
+// named animations. The sprite render system shows the current frame in
+// place of the sprite component's region.
+type SpriteAnimationComponent struct {
+	Animations map[string]*SpriteAnimation
+	Current    string
+	Playing    bool
+	Speed      float32 // Playback rate multiplier
+	frame      int
+	elapsed    float32
+	backwards  bool
+}
+
+// NewSpriteAnimationComponent creates a component with no animations
+func NewSpriteAnimationComponent() *SpriteAnimationComponent {
+	return &SpriteAnimationComponent{
+		Animations: make(map[string]*SpriteAnimation),
+		Speed:      1.0,
+	}
+}
+
+// GetType returns the component type
+func (a *SpriteAnimationComponent) GetType() string {
+	return "SpriteAnimation"
+}
+
+// AddAnimation registers an animation under a name
+func (a *SpriteAnimationComponent) AddAnimation(name string, frames [][4]int, fps float32, mode AnimationMode) {
+	a.Animations[name] = &SpriteAnimation{Frames: frames, FPS: fps, Mode: mode}
+}
+
+// Play starts an animation from its first frame; playing the current
+// animation again keeps its position
+func (a *SpriteAnimationComponent) Play(name string) {
+	if _, exists := a.Animations[name]; !exists {
+		return
+	}
+	if name == a.Current && a.Playing {
+		return
+	}
+	a.Current = name
+	a.Playing = true
+	a.frame = 0
+	a.elapsed = 0
+	a.backwards = false
+}
+
+// Stop pauses on the current frame
+func (a *SpriteAnimationComponent) Stop() {
+	a.Playing = false
+}
+
+// Frame returns the index of the current frame
+func (a *SpriteAnimationComponent) Frame() int {
+	return a.frame
+}
+
+// CurrentRegion returns the sprite sheet region of the current frame
+func (a *SpriteAnimationComponent) CurrentRegion() ([4]int, bool) {
+	anim, exists := a.Animations[a.Current]
+	if !exists || len(anim.Frames) == 0 {
+		return [4]int{}, false
+	}
+	return anim.Frames[min(a.frame, len(anim.Frames)-1)], true
+}
+
+// Update advances the current animation by the elapsed time
+func (a *SpriteAnimationComponent) Update(deltaTime float32) {
+	anim, exists := a.Animations[a.Current]
+	if !a.Playing || !exists || anim.FPS <= 0 || len(anim.Frames) == 0 {
+		return
+	}
+
+	frameTime := 1.0 / anim.FPS
+	a.elapsed += deltaTime * a.Speed
+	for a.elapsed >= frameTime && a.Playing {
+		a.elapsed -= frameTime
+		a.step(anim)
+	}
+}
+
+func (a *SpriteAnimationComponent) step(anim *SpriteAnimation) {
+	last := len(anim.Frames) - 1
+	switch anim.Mode {
+	case AnimationOnce:
+		if a.frame < last {
+			a.frame++
+		} else {
+			a.Playing = false
+		}
+	case AnimationPingPong:
+		if last == 0 {
+			return
+		}
+		if a.backwards {
+			a.frame--
+		} else {
+			a.frame++
+		}
+		if a.frame <= 0 || a.frame >= last {
+			a.backwards = a.frame >= last
+		}
+	default:
+		a.frame = (a.frame + 1) % len(anim.Frames)
+	}
+}
+
+// TilemapComponent draws the tile layers of a Tiled map with the entity's
+// world position as the map's top-left corner
+type TilemapComponent struct {
+	Map     string // Tiled map file path, .tmx or .tmj
+	Layer   int    // Sprite layer of the first map layer; later layers count up
+	Visible bool
+}
+
+// NewTilemapComponent creates a visible tilemap of a map file
+func NewTilemapComponent(path string) *TilemapComponent {
+	return &TilemapComponent{
+		Map:     path,
+		Visible: true,
+	}
+}
+
+// GetType returns the component type
+func (t *TilemapComponent) GetType() string {
+	return "Tilemap"
+}
+
+// Update updates the tilemap component
+func (t *TilemapComponent) Update(deltaTime float32) {
+	// Tilemaps are drawn by the sprite render system
+}
+
+// TileObjectComponent keeps what a Tiled map object carried besides its
+// position, for game code to act on after the map is loaded
+type TileObjectComponent struct {
+	ObjectID   int
+	Type       string
+	Layer      string     // Name of the object layer
+	Size       [2]float32 // Width and height in pixels
+	Properties map[string]string
+}
+
+// GetType returns the component type
+func (t *TileObjectComponent) GetType() string {
+	return "TileObject"
+}
+
+// Update updates the tile object component
+func (t *TileObjectComponent) Update(deltaTime float32) {
+	// Tile objects only carry data
+}
+
+// TextAlign places the lines of a text component within its block
+type TextAlign int
+
+const (
+	TextAlignLeft TextAlign = iota
+	TextAlignCenter
+	TextAlignRight
+)
+
+// TextComponent draws a string in the world with a TrueType or OpenType
+// font. Like a sprite it follows the entity's position, z rotation and
+// scale.
+type TextComponent struct {
+	Text          string
+	Font          string  // Font file path; empty for the built-in font
+	Size          float32 // Em size in world units
+	Color         [4]float32
+	MaxWidth      float32 // Wrap width in world units; zero disables wrapping
+	Align         TextAlign
+	Origin        [2]float32 // Pivot as a fraction of the text block, (0.5, 0.5) is the center
+	DistanceField bool       // Keeps edges sharp when zoomed or scaled
+	Layer         int        // Lower layers are drawn first
+	Visible       bool
+}
+
+// NewTextComponent creates centered white text with the built-in font
+func NewTextComponent(text string, size float32) *TextComponent {
+	return &TextComponent{
+		Text:    text,
+		Size:    size,
+		Color:   [4]float32{1.0, 1.0, 1.0, 1.0},
+		Origin:  [2]float32{0.5, 0.5},
+		Visible: true,
+	}
+}
+
+// GetType returns the component type
+func (t *TextComponent) GetType() string {
+	return "Text"
+}
+
+// Update updates the text component
+func (t *TextComponent) Update(deltaTime float32) {
+	// Text is drawn by the sprite render system
+}
@@ -0,0 +1,119 @@
+package scene
+
+import (
+	"reflect"
+	"testing"
+)
+
+// frames makes n distinct regions, frame i at x = i
+func frames(n int) [][4]int {
+	regions := make([][4]int, n)
+	for i := range regions {
+		regions[i] = [4]int{i, 0, 16, 16}
+	}
+	return regions
+}
+
+func TestSpriteAnimationStepping(t *testing.T) {
+	tests := []struct {
+		name   string
+		frames int
+		mode   AnimationMode
+		// want is the frame after each tick of one frame time
+		want        []int
+		wantPlaying bool
+	}{
+		{"loop", 3, AnimationLoop, []int{1, 2, 0, 1, 2, 0}, true},
+		{"loop single frame", 1, AnimationLoop, []int{0, 0, 0}, true},
+		{"ping-pong", 4, AnimationPingPong, []int{1, 2, 3, 2, 1, 0, 1, 2, 3, 2}, true},
+		{"ping-pong two frames", 2, AnimationPingPong, []int{1, 0, 1, 0}, true},
+		{"ping-pong single frame", 1, AnimationPingPong, []int{0, 0}, true},
+		{"once", 3, AnimationOnce, []int{1, 2, 2, 2}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewSpriteAnimationComponent()
+			a.AddAnimation("walk", frames(tt.frames), 10, tt.mode)
+			a.Play("walk")
+
+			var got []int
+			for range tt.want {
+				a.Update(0.1)
+				got = append(got, a.Frame())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("frames = %v, want %v", got, tt.want)
+			}
+			if a.Playing != tt.wantPlaying {
+				t.Errorf("playing = %v, want %v", a.Playing, tt.wantPlaying)
+			}
+			region, ok := a.CurrentRegion()
+			if !ok || region != frames(tt.frames)[a.Frame()] {
+				t.Errorf("region = %v, %v for frame %d", region, ok, a.Frame())
+			}
+		})
+	}
+}
+
+func TestSpriteAnimationTiming(t *testing.T) {
+	tests := []struct {
+		name  string
+		speed float32
+		ticks []float32
+		want  int
+	}{
+		{"short ticks accumulate", 1, []float32{0.04, 0.04, 0.04}, 1},
+		{"long tick skips frames", 1, []float32{0.35}, 3},
+		{"long tick wraps", 1, []float32{0.55}, 0},
+		{"double speed", 2, []float32{0.1}, 2},
+		{"paused at zero speed", 0, []float32{1}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewSpriteAnimationComponent()
+			a.AddAnimation("walk", frames(5), 10, AnimationLoop)
+			a.Speed = tt.speed
+			a.Play("walk")
+			for _, dt := range tt.ticks {
+				a.Update(dt)
+			}
+			if a.Frame() != tt.want {
+				t.Errorf("frame = %d, want %d", a.Frame(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSpriteAnimationPlay(t *testing.T) {
+	a := NewSpriteAnimationComponent()
+	a.AddAnimation("walk", frames(4), 10, AnimationLoop)
+	a.AddAnimation("run", frames(4), 10, AnimationLoop)
+
+	if _, ok := a.CurrentRegion(); ok {
+		t.Error("region before any animation plays")
+	}
+	a.Play("jump")
+	if a.Playing || a.Current != "" {
+		t.Error("playing an unknown animation")
+	}
+
+	a.Play("walk")
+	a.Update(0.2)
+	// Playing the current animation again keeps its position
+	a.Play("walk")
+	if a.Frame() != 2 {
+		t.Errorf("frame after replay = %d, want 2", a.Frame())
+	}
+	a.Play("run")
+	if a.Current != "run" || a.Frame() != 0 {
+		t.Errorf("switched to %q at frame %d", a.Current, a.Frame())
+	}
+
+	a.Stop()
+	a.Update(1)
+	if a.Frame() != 0 {
+		t.Errorf("stopped animation advanced to %d", a.Frame())
+	}
+}
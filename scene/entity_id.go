@@ -0,0 +1,96 @@
+package scene
+
+import "fmt"
+
+// EntityID is a generational handle to an entity. The low 32 bits index a
+// slot the scene reuses once its entity is removed and the high 32 bits
+// count how many entities have held the slot, so a handle kept past its
+// entity's removal never finds the entity that replaced it. Zero is never
+// assigned.
+type EntityID uint64
+
+func newEntityID(index, generation uint32) EntityID {
+	return EntityID(generation)<<32 | EntityID(index)
+}
+
+// Index returns the slot the entity occupies
+func (id EntityID) Index() uint32 {
+	return uint32(id)
+}
+
+// Generation returns how many entities have held the slot up to this one
+func (id EntityID) Generation() uint32 {
+	return uint32(id >> 32)
+}
+
+func (id EntityID) String() string {
+	return fmt.Sprintf("%d:%d", id.Index(), id.Generation())
+}
+
+// allocateID hands out a handle, reusing free slots first
+func (s *Scene) allocateID() EntityID {
+	if n := len(s.free); n > 0 {
+		index := s.free[n-1]
+		s.free = s.free[:n-1]
+		return newEntityID(index, s.generations[index])
+	}
+	// Slot zero is never used, so neither is handle zero
+	if len(s.generations) == 0 {
+		s.generations = append(s.generations, 0)
+	}
+	index := uint32(len(s.generations))
+	s.generations = append(s.generations, 1)
+	return newEntityID(index, 1)
+}
+
+// releaseID frees a handle's slot, invalidating the handle
+func (s *Scene) releaseID(id EntityID) {
+	index := id.Index()
+	s.generations[index]++
+	if s.generations[index] == 0 {
+		s.generations[index] = 1
+	}
+	s.free = append(s.free, index)
+}
+
+// IsValid reports whether a handle refers to an entity still in the scene
+func (s *Scene) IsValid(id EntityID) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.entities[id] != nil
+}
+
+// Destroy queues an entity and its descendants for removal at the end of
+// the frame, when FlushDestroyed runs. Systems can call it while iterating
+// entities or queries; the entity stays usable until then.
+func (s *Scene) Destroy(id EntityID) {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+	s.pending = append(s.pending, id)
+}
+
+// FlushDestroyed removes the entities queued by Destroy. The engine calls
+// it once a frame has been drawn.
+func (s *Scene) FlushDestroyed() {
+	s.pendingMu.Lock()
+	pending := s.pending
+	s.pending = nil
+	s.pendingMu.Unlock()
+
+	for _, id := range pending {
+		s.RemoveEntity(id)
+	}
+}
+
+// Alive reports whether the entity is still in its scene. Components
+// cannot be added to or removed from an entity once it has been removed.
+func (e *Entity) Alive() bool {
+	return e.scene != nil
+}
+
+// Destroy queues the entity for removal at the end of the frame
+func (e *Entity) Destroy() {
+	if e.scene != nil {
+		e.scene.Destroy(e.ID)
+	}
+}
@@ -0,0 +1,102 @@
+package scene
+
+import "testing"
+
+func TestStaleHandles(t *testing.T) {
+	s := NewScene("handles")
+	first := s.CreateEntity("first")
+	old := first.ID
+	s.RemoveEntity(old)
+
+	// The slot is reused with a new generation
+	second := s.CreateEntity("second")
+	if second.ID.Index() != old.Index() {
+		t.Fatalf("slot %d not reused, got %d", old.Index(), second.ID.Index())
+	}
+	if second.ID.Generation() == old.Generation() || second.ID == old {
+		t.Fatalf("reused slot kept generation %d", old.Generation())
+	}
+
+	if s.IsValid(old) || s.GetEntity(old) != nil {
+		t.Error("stale handle resolves")
+	}
+	if !s.IsValid(second.ID) || s.GetEntity(second.ID) != second {
+		t.Error("new handle does not resolve")
+	}
+	if first.Alive() {
+		t.Error("removed entity alive")
+	}
+
+	// Removing through a stale handle leaves the slot's new entity alone
+	s.RemoveEntity(old)
+	if !second.Alive() {
+		t.Error("stale handle removed the slot's new entity")
+	}
+
+	// Components cannot be attached to a removed entity
+	first.AddComponent(NewMeshComponent("cube"))
+	if first.HasComponent("Mesh") {
+		t.Error("component added to a removed entity")
+	}
+	if meshes := Query[*MeshComponent](s); meshes.Next() {
+		t.Error("removed entity's component queried")
+	}
+}
+
+func TestHandleZeroIsNeverValid(t *testing.T) {
+	s := NewScene("handles")
+	e := s.CreateEntity("e")
+	if e.ID == 0 || e.ID.Index() == 0 {
+		t.Errorf("handle %v uses slot zero", e.ID)
+	}
+	if s.IsValid(0) {
+		t.Error("handle zero is valid")
+	}
+}
+
+func TestDeferredDestroy(t *testing.T) {
+	s := NewScene("handles")
+	parent := s.CreateEntity("parent")
+	child := s.CreateEntity("child")
+	s.SetParent(child, parent)
+	keep := s.CreateEntity("keep")
+
+	// Destroying while iterating leaves the entities usable until the flush
+	for _, e := range s.GetEntities() {
+		if e != keep {
+			e.Destroy()
+		}
+	}
+	s.Destroy(parent.ID) // Twice is harmless
+	if !s.IsValid(parent.ID) || !s.IsValid(child.ID) {
+		t.Fatal("entities removed before the flush")
+	}
+
+	s.FlushDestroyed()
+	if s.IsValid(parent.ID) || s.IsValid(child.ID) || parent.Alive() || child.Alive() {
+		t.Error("destroyed entities still in the scene")
+	}
+	if !s.IsValid(keep.ID) {
+		t.Error("kept entity removed")
+	}
+
+	// Nothing is left queued
+	replacement := s.CreateEntity("replacement")
+	s.FlushDestroyed()
+	if !replacement.Alive() {
+		t.Error("a later flush removed an entity that reused a destroyed slot")
+	}
+}
+
+func TestGenerationWraps(t *testing.T) {
+	s := NewScene("handles")
+	e := s.CreateEntity("e")
+	index := e.ID.Index()
+	s.generations[index] = ^uint32(0)
+	s.RemoveEntity(e.ID)
+
+	// Generation zero is skipped, as it is for fresh slots
+	if got := s.generations[index]; got != 1 {
+		t.Errorf("generation after wrapping = %d, want 1", got)
+	}
+}
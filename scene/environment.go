@@ -0,0 +1,16 @@
+package scene
+
+// Environment describes what a scene shows behind its geometry
+type Environment struct {
+	// Skybox is a directory of six cubemap faces or an equirectangular
+	// panorama (.hdr, .png, .jpg); empty shows the clear color
+	Skybox     string
+	ClearColor [4]float32
+}
+
+// DefaultEnvironment returns the flat gray background new scenes start with
+func DefaultEnvironment() Environment {
+	return Environment{
+		ClearColor: [4]float32{0.1, 0.1, 0.1, 1.0},
+	}
+}
@@ -0,0 +1,250 @@
+package scene
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+)
+
+// Parent returns the entity's parent, or nil for a root entity
+func (e *Entity) Parent() *Entity {
+	return e.parent
+}
+
+// Children returns the entity's children in the order they were attached.
+// The slice must not be modified.
+func (e *Entity) Children() []*Entity {
+	return e.children
+}
+
+// Root returns the topmost ancestor of the entity, or the entity itself
+func (e *Entity) Root() *Entity {
+	root := e
+	for root.parent != nil {
+		root = root.parent
+	}
+	return root
+}
+
+// IsDescendantOf reports whether an entity sits anywhere below another
+func (e *Entity) IsDescendantOf(ancestor *Entity) bool {
+	for p := e.parent; p != nil; p = p.parent {
+		if p == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// Path returns the names from the root down to the entity joined by
+// slashes, such as "Player/Arm/Hand"
+func (e *Entity) Path() string {
+	names := []string{e.Name}
+	for p := e.parent; p != nil; p = p.parent {
+		names = append(names, p.Name)
+	}
+	for i, j := 0, len(names)-1; i < j; i, j = i+1, j-1 {
+		names[i], names[j] = names[j], names[i]
+	}
+	return strings.Join(names, "/")
+}
+
+// FindChild returns the first child with a name
+func (e *Entity) FindChild(name string) *Entity {
+	for _, child := range e.children {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// Find follows a slash separated path of child names below the entity
+func (e *Entity) Find(path string) *Entity {
+	current := e
+	for _, name := range strings.Split(strings.Trim(path, "/"), "/") {
+		if name == "" {
+			continue
+		}
+		if current = current.FindChild(name); current == nil {
+			return nil
+		}
+	}
+	return current
+}
+
+// Walk visits the entity and its descendants depth first, parents before
+// their children. Returning false from visit skips an entity's children.
+func (e *Entity) Walk(visit func(entity *Entity) bool) {
+	if !visit(e) {
+		return
+	}
+	for _, child := range e.children {
+		child.Walk(visit)
+	}
+}
+
+// Roots returns the entities without a parent, ordered by ID
+func (s *Scene) Roots() []*Entity {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var roots []*Entity
+	for _, entity := range s.entities {
+		if entity.parent == nil {
+			roots = append(roots, entity)
+		}
+	}
+	sort.Slice(roots, func(i, j int) bool { return roots[i].ID < roots[j].ID })
+	return roots
+}
+
+// Find returns the entity at a path of names from a root entity, such as
+// "Player/Arm/Hand". Where siblings share a name the first is followed.
+func (s *Scene) Find(path string) *Entity {
+	path = strings.Trim(path, "/")
+	rootName, rest, _ := strings.Cut(path, "/")
+	for _, root := range s.Roots() {
+		if root.Name == rootName {
+			return root.Find(rest)
+		}
+	}
+	return nil
+}
+
+// SetParent moves an entity under a new parent, or to the root when parent
+// is nil, keeping where it is in the world. Use it rather than
+// Transform.SetParent so the scene's hierarchy and the transforms agree.
+// A parent scaled to nothing along an axis cannot keep a child in place,
+// so SetParent fails without changing anything; SetParentKeepLocal can
+// still parent to it.
+func (s *Scene) SetParent(child, parent *Entity) error {
+	return s.setParent(child, parent, true)
+}
+
+// SetParentKeepLocal moves an entity under a new parent, or to the root
+// when parent is nil, keeping its local transform so it moves with the
+// parent's placement
+func (s *Scene) SetParentKeepLocal(child, parent *Entity) error {
+	return s.setParent(child, parent, false)
+}
+
+func (s *Scene) setParent(child, parent *Entity, keepWorld bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if child == nil || s.entities[child.ID] != child {
+		return fmt.Errorf("entity is not in scene '%s'", s.name)
+	}
+	if parent != nil {
+		if s.entities[parent.ID] != parent {
+			return fmt.Errorf("parent '%s' is not in scene '%s'", parent.Name, s.name)
+		}
+		if parent == child || parent.IsDescendantOf(child) {
+			return fmt.Errorf("cannot parent '%s' to itself or its descendant '%s'", child.Name, parent.Name)
+		}
+	}
+	if child.parent == parent {
+		return nil
+	}
+	inverse := bmath.NewMatrix4Identity()
+	if keepWorld && parent != nil {
+		var ok bool
+		if inverse, ok = parent.Transform.GetWorldMatrix().Inverse(); !ok {
+			return fmt.Errorf("cannot keep '%s' in place under '%s', which is scaled to nothing", child.Name, parent.Name)
+		}
+	}
+
+	world := child.Transform.GetWorldMatrix()
+	child.unlink()
+	child.parent = parent
+	if parent != nil {
+		parent.children = append(parent.children, child)
+		child.Transform.SetParent(parent.Transform)
+	} else {
+		child.Transform.SetParent(nil)
+	}
+
+	if keepWorld {
+		position, rotation, scale := inverse.Multiply(world).Decompose()
+		child.Transform.SetPosition(position)
+		child.Transform.SetRotation(bmath.NewVector3(
+			bmath.Degrees(rotation.X), bmath.Degrees(rotation.Y), bmath.Degrees(rotation.Z)))
+		child.Transform.SetScale(scale)
+	}
+	return nil
+}
+
+// unlink takes an entity out of its parent's children
+func (e *Entity) unlink() {
+	if e.parent == nil {
+		return
+	}
+	siblings := e.parent.children
+	for i, sibling := range siblings {
+		if sibling == e {
+			e.parent.children = append(siblings[:i:i], siblings[i+1:]...)
+			break
+		}
+	}
+	e.parent = nil
+}
+
+// MoveEntity moves a root entity and its descendants to another scene, or
+// just the entity's subtree when it has a parent, which it leaves keeping
+// its place in the world. The entities get new handles in the target
+// scene. Their components stay attached without running hooks; observers
+// see the entities destroyed here and created there.
+func (s *Scene) MoveEntity(entity *Entity, target *Scene) error {
+	if entity == nil || entity.scene != s {
+		return fmt.Errorf("entity is not in scene '%s'", s.name)
+	}
+	if target == s {
+		return nil
+	}
+	if entity.parent != nil {
+		if err := s.SetParent(entity, nil); err != nil {
+			return err
+		}
+	}
+
+	var moved []*Entity
+	entity.Walk(func(e *Entity) bool {
+		moved = append(moved, e)
+		return true
+	})
+	for _, e := range moved {
+		s.notify(Event{Kind: EntityDestroyed, Entity: e})
+	}
+
+	s.mu.Lock()
+	for _, e := range moved {
+		for key := range e.typed {
+			s.poolOf(key).remove(e.ID)
+		}
+		delete(s.entities, e.ID)
+		s.releaseID(e.ID)
+	}
+	s.mu.Unlock()
+
+	target.mu.Lock()
+	for _, e := range moved {
+		e.ID = target.allocateID()
+		e.scene = target
+		target.entities[e.ID] = e
+		for key, list := range e.typed {
+			target.poolFor(key).set(e, list)
+		}
+	}
+	target.mu.Unlock()
+
+	for _, e := range moved {
+		target.notify(Event{Kind: EntityCreated, Entity: e})
+		for _, component := range e.order {
+			target.notify(Event{Kind: ComponentAdded, Entity: e, Component: component})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package scene
+
+import (
+	"math"
+	"testing"
+
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+)
+
+func near(a, b bmath.Vector3) bool {
+	const epsilon = 1e-4
+	return math.Abs(float64(a.X-b.X)) < epsilon && math.Abs(float64(a.Y-b.Y)) < epsilon && math.Abs(float64(a.Z-b.Z)) < epsilon
+}
+
+func nearMatrix(a, b bmath.Matrix4) bool {
+	for i := range a {
+		if math.Abs(float64(a[i]-b[i])) > 1e-4 {
+			return false
+		}
+	}
+	return true
+}
+
+// placed creates an entity with a local transform
+func placed(s *Scene, name string, position, rotation, scale bmath.Vector3) *Entity {
+	e := s.CreateEntity(name)
+	e.Transform.SetPosition(position)
+	e.Transform.SetRotation(rotation)
+	e.Transform.SetScale(scale)
+	return e
+}
+
+func TestSetParent(t *testing.T) {
+	one := bmath.NewVector3(1, 1, 1)
+	tests := []struct {
+		name      string
+		position  bmath.Vector3
+		rotation  bmath.Vector3
+		scale     bmath.Vector3
+		keepWorld bool
+	}{
+		{"translated keeping world", bmath.NewVector3(10, 0, 0), bmath.Vector3{}, one, true},
+		{"rotated keeping world", bmath.NewVector3(0, 5, 0), bmath.NewVector3(0, 90, 0), one, true},
+		{"scaled keeping world", bmath.NewVector3(-3, 2, 1), bmath.NewVector3(0, 0, 45), bmath.NewVector3(2, 2, 2), true},
+		{"translated keeping local", bmath.NewVector3(10, 0, 0), bmath.Vector3{}, one, false},
+		{"rotated keeping local", bmath.NewVector3(0, 5, 0), bmath.NewVector3(0, 90, 0), bmath.NewVector3(2, 2, 2), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScene("hierarchy")
+			parent := placed(s, "parent", tt.position, tt.rotation, tt.scale)
+			child := placed(s, "child", bmath.NewVector3(1, 2, 3), bmath.NewVector3(0, 30, 0), one)
+			world := child.Transform.GetWorldMatrix()
+			local := child.Transform.GetLocalMatrix()
+
+			var err error
+			if tt.keepWorld {
+				err = s.SetParent(child, parent)
+			} else {
+				err = s.SetParentKeepLocal(child, parent)
+			}
+			if err != nil {
+				t.Fatalf("SetParent: %v", err)
+			}
+
+			if child.Parent() != parent || len(parent.Children()) != 1 || parent.Children()[0] != child {
+				t.Fatal("hierarchy not linked")
+			}
+			if child.Transform.Parent != parent.Transform {
+				t.Fatal("transforms not linked")
+			}
+			if tt.keepWorld {
+				if got := child.Transform.GetWorldMatrix(); !nearMatrix(got, world) {
+					t.Errorf("world matrix moved from %v to %v", world, got)
+				}
+			} else {
+				if got := child.Transform.GetLocalMatrix(); !nearMatrix(got, local) {
+					t.Errorf("local matrix changed from %v to %v", local, got)
+				}
+				want := parent.Transform.GetWorldMatrix().Multiply(local)
+				if got := child.Transform.GetWorldMatrix(); !nearMatrix(got, want) {
+					t.Errorf("world matrix = %v, want parent * local %v", got, want)
+				}
+			}
+
+			// The child follows its parent from now on
+			before := child.Transform.GetWorldPosition()
+			parent.Transform.Translate(bmath.NewVector3(0, 0, 4))
+			if got, want := child.Transform.GetWorldPosition(), before.Add(bmath.NewVector3(0, 0, 4)); !near(got, want) {
+				t.Errorf("child at %v after the parent moved, want %v", got, want)
+			}
+
+			// Back to the root keeps the world placement either way
+			world = child.Transform.GetWorldMatrix()
+			if err := s.SetParent(child, nil); err != nil {
+				t.Fatalf("SetParent nil: %v", err)
+			}
+			if child.Parent() != nil || len(parent.Children()) != 0 || child.Transform.Parent != nil {
+				t.Error("child still linked to the parent")
+			}
+			if got := child.Transform.GetWorldMatrix(); !nearMatrix(got, world) {
+				t.Errorf("world matrix moved from %v to %v at the root", world, got)
+			}
+		})
+	}
+}
+
+func TestSetParentErrors(t *testing.T) {
+	s := NewScene("hierarchy")
+	other := NewScene("other")
+	a := s.CreateEntity("a")
+	b := s.CreateEntity("b")
+	c := s.CreateEntity("c")
+	stranger := other.CreateEntity("stranger")
+	flat := s.CreateEntity("flat")
+	flat.Transform.SetScale(bmath.NewVector3(1, 0, 1))
+	if err := s.SetParent(b, a); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetParent(c, b); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		child, parent *Entity
+	}{
+		{"itself", a, a},
+		{"its child", a, b},
+		{"its grandchild", a, c},
+		{"parent in another scene", a, stranger},
+		{"child in another scene", stranger, a},
+		{"nil child", nil, a},
+		{"parent scaled to nothing", a, flat},
+	}
+	for _, tt := range tests {
+		if err := s.SetParent(tt.child, tt.parent); err == nil {
+			t.Errorf("%s: SetParent succeeded", tt.name)
+		}
+	}
+	if a.Parent() != nil || b.Parent() != a || c.Parent() != b || len(flat.Children()) != 0 {
+		t.Error("a failed SetParent changed the hierarchy")
+	}
+
+	// Keeping the local transform needs no inverse
+	if err := s.SetParentKeepLocal(a, flat); err != nil || a.Parent() != flat {
+		t.Errorf("SetParentKeepLocal under a flat parent: %v", err)
+	}
+}
+
+func TestHierarchyLookup(t *testing.T) {
+	s := NewScene("hierarchy")
+	player := s.CreateEntity("Player")
+	arm := s.CreateEntity("Arm")
+	hand := s.CreateEntity("Hand")
+	other := s.CreateEntity("Arm")
+	s.SetParent(arm, player)
+	s.SetParent(hand, arm)
+	s.SetParent(other, player)
+
+	if got := hand.Path(); got != "Player/Arm/Hand" {
+		t.Errorf("path = %q", got)
+	}
+	if hand.Root() != player || !hand.IsDescendantOf(player) || player.IsDescendantOf(hand) {
+		t.Error("ancestry wrong")
+	}
+
+	tests := []struct {
+		path string
+		want *Entity
+	}{
+		{"Player", player},
+		{"Player/Arm", arm}, // The first of two siblings named Arm
+		{"/Player/Arm/Hand/", hand},
+		{"Player/Leg", nil},
+		{"Hand", nil},
+	}
+	for _, tt := range tests {
+		if got := s.Find(tt.path); got != tt.want {
+			t.Errorf("Find(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	var walked []string
+	player.Walk(func(e *Entity) bool {
+		walked = append(walked, e.Name)
+		return e != arm // Skip the arm's children
+	})
+	if len(walked) != 3 || walked[0] != "Player" || walked[1] != "Arm" || walked[2] != "Arm" {
+		t.Errorf("walked %v", walked)
+	}
+	if roots := s.Roots(); len(roots) != 1 || roots[0] != player {
+		t.Errorf("roots = %v", roots)
+	}
+}
+
+func TestRemoveEntityRemovesDescendants(t *testing.T) {
+	s := NewScene("hierarchy")
+	root := s.CreateEntity("root")
+	parent := s.CreateEntity("parent")
+	child := s.CreateEntity("child")
+	sibling := s.CreateEntity("sibling")
+	s.SetParent(parent, root)
+	s.SetParent(child, parent)
+	s.SetParent(sibling, root)
+	child.AddComponent(NewMeshComponent("cube"))
+
+	s.RemoveEntity(parent.ID)
+
+	for _, e := range []*Entity{parent, child} {
+		if s.IsValid(e.ID) || e.Alive() {
+			t.Errorf("%s still in the scene", e.Name)
+		}
+	}
+	if len(root.Children()) != 1 || root.Children()[0] != sibling {
+		t.Errorf("root children = %v", root.Children())
+	}
+	if len(root.Transform.Children) != 1 {
+		t.Errorf("root transform has %d children", len(root.Transform.Children))
+	}
+	if meshes := Query[*MeshComponent](s); meshes.Next() {
+		t.Error("removed child's mesh still queried")
+	}
+}
@@ -0,0 +1,174 @@
+package scene
+
+import "sync"
+
+// Components can implement any of the hook interfaces below to hear about
+// their lifecycle. The scene calls them synchronously on the thread making
+// the change.
+
+// AddHook is called once a component has been added to an entity
+type AddHook interface {
+	OnAdd(entity *Entity)
+}
+
+// RemoveHook is called before a component is removed from an entity that
+// stays in the scene
+type RemoveHook interface {
+	OnRemove(entity *Entity)
+}
+
+// EnableHook is called when a component starts running: after OnAdd on an
+// active entity, and when its entity is activated with SetActive
+type EnableHook interface {
+	OnEnable(entity *Entity)
+}
+
+// DisableHook is called when a component stops running: before OnRemove or
+// OnDestroy on an active entity, and when its entity is deactivated with
+// SetActive
+type DisableHook interface {
+	OnDisable(entity *Entity)
+}
+
+// DestroyHook is called before a component's entity is removed from the
+// scene. OnRemove is not called in that case.
+type DestroyHook interface {
+	OnDestroy(entity *Entity)
+}
+
+// EventKind is the kind of change an Event reports
+type EventKind int
+
+const (
+	EntityCreated EventKind = iota
+	EntityDestroyed
+	ComponentAdded
+	ComponentRemoved
+	ComponentFailed
+)
+
+var eventKindNames = [...]string{"entity created", "entity destroyed", "component added", "component removed", "component failed"}
+
+func (k EventKind) String() string {
+	if k < 0 || int(k) >= len(eventKindNames) {
+		return "unknown"
+	}
+	return eventKindNames[k]
+}
+
+// Event is a change to a scene's entities. Component is nil for entity
+// events. EntityDestroyed is sent while the entity and its components can
+// still be inspected, and stands for the removal of all its components.
+// ComponentFailed carries Err, for a component that could not set itself
+// up as it was added; it stays on the entity.
+type Event struct {
+	Kind      EventKind
+	Entity    *Entity
+	Component Component
+	Err       error
+}
+
+// observers are the functions subscribed to a scene's events
+type observers struct {
+	mu     sync.Mutex
+	list   []observer
+	nextID int
+}
+
+type observer struct {
+	id     int
+	notify func(Event)
+}
+
+// Subscribe calls notify for every event in the scene until the returned
+// function is called
+func (s *Scene) Subscribe(notify func(event Event)) func() {
+	s.observers.mu.Lock()
+	defer s.observers.mu.Unlock()
+
+	s.observers.nextID++
+	id := s.observers.nextID
+	s.observers.list = append(s.observers.list, observer{id: id, notify: notify})
+	return func() {
+		s.observers.mu.Lock()
+		defer s.observers.mu.Unlock()
+		for i, o := range s.observers.list {
+			if o.id == id {
+				s.observers.list = append(s.observers.list[:i:i], s.observers.list[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// notify sends an event to the observers subscribed when it happened
+func (s *Scene) notify(event Event) {
+	s.observers.mu.Lock()
+	list := s.observers.list
+	s.observers.mu.Unlock()
+
+	for _, o := range list {
+		o.notify(event)
+	}
+}
+
+// SetActive activates or deactivates the entity, enabling or disabling its
+// components
+func (e *Entity) SetActive(active bool) {
+	if e.Active == active {
+		return
+	}
+	e.Active = active
+	for _, component := range e.order {
+		if active {
+			if hook, ok := component.(EnableHook); ok {
+				hook.OnEnable(e)
+			}
+		} else if hook, ok := component.(DisableHook); ok {
+			hook.OnDisable(e)
+		}
+	}
+}
+
+// added runs the hooks and observers for a component just attached
+func (e *Entity) added(component Component) {
+	if hook, ok := component.(AddHook); ok {
+		hook.OnAdd(e)
+	}
+	if hook, ok := component.(EnableHook); ok && e.Active {
+		hook.OnEnable(e)
+	}
+	e.scene.notify(Event{Kind: ComponentAdded, Entity: e, Component: component})
+}
+
+// failed tells observers a component could not set itself up
+func (e *Entity) failed(component Component, err error) {
+	e.scene.notify(Event{Kind: ComponentFailed, Entity: e, Component: component, Err: err})
+}
+
+// removing runs the hooks for a component about to be detached
+func (e *Entity) removing(component Component) {
+	if hook, ok := component.(DisableHook); ok && e.Active {
+		hook.OnDisable(e)
+	}
+	if hook, ok := component.(RemoveHook); ok {
+		hook.OnRemove(e)
+	}
+}
+
+// destroying runs the hooks and observers for an entity about to leave the
+// scene
+func (e *Entity) destroying() {
+	// A hook may move the entity to another scene, so observers are told
+	// through the scene it is leaving
+	scene := e.scene
+	for _, component := range e.order {
+		if hook, ok := component.(DisableHook); ok && e.Active {
+			hook.OnDisable(e)
+		}
+		if hook, ok := component.(DestroyHook); ok {
+			hook.OnDestroy(e)
+		}
+	}
+	scene.notify(Event{Kind: EntityDestroyed, Entity: e})
+}
@@ -0,0 +1,230 @@
+package scene
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// hooked records its hooks, and those of every other hooked component
+// sharing the log, as "name:Hook"
+type hooked struct {
+	name string
+	log  *[]string
+	// onDestroy runs from OnDestroy, after logging
+	onDestroy func(entity *Entity)
+}
+
+func (h *hooked) GetType() string          { return "Hooked" }
+func (h *hooked) Update(deltaTime float32) {}
+
+func (h *hooked) record(hook string) {
+	*h.log = append(*h.log, h.name+":"+hook)
+}
+
+func (h *hooked) OnAdd(entity *Entity)     { h.record("OnAdd") }
+func (h *hooked) OnRemove(entity *Entity)  { h.record("OnRemove") }
+func (h *hooked) OnEnable(entity *Entity)  { h.record("OnEnable") }
+func (h *hooked) OnDisable(entity *Entity) { h.record("OnDisable") }
+
+func (h *hooked) OnDestroy(entity *Entity) {
+	h.record("OnDestroy")
+	if h.onDestroy != nil {
+		h.onDestroy(entity)
+	}
+}
+
+// observe logs a scene's events next to the hooks
+func observe(s *Scene, log *[]string) {
+	s.Subscribe(func(event Event) {
+		name := event.Entity.Name
+		if h, ok := event.Component.(*hooked); ok {
+			name = h.name
+		}
+		*log = append(*log, fmt.Sprintf("%s:%s", name, event.Kind))
+	})
+}
+
+func TestHookOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(s *Scene, e *Entity, log *[]string)
+		want []string
+	}{
+		{
+			name: "add to active entity",
+			run: func(s *Scene, e *Entity, log *[]string) {
+				e.AddComponent(&hooked{name: "a", log: log})
+			},
+			want: []string{"a:OnAdd", "a:OnEnable", "a:component added"},
+		},
+		{
+			name: "add to inactive entity",
+			run: func(s *Scene, e *Entity, log *[]string) {
+				e.SetActive(false)
+				e.AddComponent(&hooked{name: "a", log: log})
+			},
+			want: []string{"a:OnAdd", "a:component added"},
+		},
+		{
+			name: "remove",
+			run: func(s *Scene, e *Entity, log *[]string) {
+				e.AddComponent(&hooked{name: "a", log: log})
+				*log = (*log)[:0]
+				e.RemoveComponent("Hooked")
+			},
+			want: []string{"a:OnDisable", "a:OnRemove", "a:component removed"},
+		},
+		{
+			name: "deactivate and activate",
+			run: func(s *Scene, e *Entity, log *[]string) {
+				e.AddComponent(&hooked{name: "a", log: log})
+				e.AddComponent(&hooked{name: "b", log: log})
+				*log = (*log)[:0]
+				e.SetActive(false)
+				e.SetActive(false) // No change, no hooks
+				e.SetActive(true)
+			},
+			want: []string{"a:OnDisable", "b:OnDisable", "a:OnEnable", "b:OnEnable"},
+		},
+		{
+			name: "destroy",
+			run: func(s *Scene, e *Entity, log *[]string) {
+				e.AddComponent(&hooked{name: "a", log: log})
+				e.AddComponent(&hooked{name: "b", log: log})
+				*log = (*log)[:0]
+				s.RemoveEntity(e.ID)
+			},
+			want: []string{"a:OnDisable", "a:OnDestroy", "b:OnDisable", "b:OnDestroy", "e:entity destroyed"},
+		},
+		{
+			name: "destroy inactive",
+			run: func(s *Scene, e *Entity, log *[]string) {
+				e.AddComponent(&hooked{name: "a", log: log})
+				e.SetActive(false)
+				*log = (*log)[:0]
+				s.RemoveEntity(e.ID)
+			},
+			want: []string{"a:OnDestroy", "e:entity destroyed"},
+		},
+		{
+			name: "destroy with children",
+			run: func(s *Scene, e *Entity, log *[]string) {
+				child := s.CreateEntity("child")
+				s.SetParent(child, e)
+				e.AddComponent(&hooked{name: "a", log: log})
+				child.AddComponent(&hooked{name: "c", log: log})
+				*log = (*log)[:0]
+				s.RemoveEntity(e.ID)
+			},
+			// Parents are destroyed before their children
+			want: []string{"a:OnDisable", "a:OnDestroy", "e:entity destroyed", "c:OnDisable", "c:OnDestroy", "child:entity destroyed"},
+		},
+		{
+			name: "replace type name",
+			run: func(s *Scene, e *Entity, log *[]string) {
+				e.AddComponent(&hooked{name: "a", log: log})
+				*log = (*log)[:0]
+				// Another Go type answering to "Hooked" replaces it
+				e.AddComponent(&otherHooked{})
+			},
+			want: []string{"a:OnDisable", "a:OnRemove", "a:component removed", "e:component added"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var log []string
+			s := NewScene("hooks")
+			e := s.CreateEntity("e")
+			observe(s, &log)
+			tt.run(s, e, &log)
+			if !reflect.DeepEqual(log, tt.want) {
+				t.Errorf("log = %q\nwant  %q", log, tt.want)
+			}
+		})
+	}
+}
+
+// otherHooked shares hooked's type name
+type otherHooked struct{}
+
+func (o *otherHooked) GetType() string          { return "Hooked" }
+func (o *otherHooked) Update(deltaTime float32) {}
+
+func TestDestroyHookRemovingEntities(t *testing.T) {
+	tests := []struct {
+		name string
+		// remove is what the parent's OnDestroy removes
+		remove func(s *Scene, parent, child, other *Entity)
+		gone   []string
+	}{
+		{"itself", func(s *Scene, parent, child, other *Entity) { s.RemoveEntity(parent.ID) }, []string{"parent", "child"}},
+		{"its child", func(s *Scene, parent, child, other *Entity) { s.RemoveEntity(child.ID) }, []string{"parent", "child"}},
+		{"another entity", func(s *Scene, parent, child, other *Entity) { s.RemoveEntity(other.ID) }, []string{"parent", "child", "other"}},
+		{"deferred", func(s *Scene, parent, child, other *Entity) { parent.Destroy() }, []string{"parent", "child"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var log []string
+			s := NewScene("hooks")
+			parent := s.CreateEntity("parent")
+			child := s.CreateEntity("child")
+			other := s.CreateEntity("other")
+			s.SetParent(child, parent)
+			parent.AddComponent(&hooked{name: "p", log: &log, onDestroy: func(*Entity) {
+				tt.remove(s, parent, child, other)
+			}})
+			child.AddComponent(&hooked{name: "c", log: &log})
+			other.AddComponent(&hooked{name: "o", log: &log})
+			destroyed := map[string]int{}
+			s.Subscribe(func(event Event) {
+				if event.Kind == EntityDestroyed {
+					destroyed[event.Entity.Name]++
+				}
+			})
+
+			s.RemoveEntity(parent.ID)
+			s.FlushDestroyed()
+
+			for _, name := range tt.gone {
+				if destroyed[name] != 1 {
+					t.Errorf("%s destroyed %d times, want once", name, destroyed[name])
+				}
+			}
+			for _, e := range []*Entity{parent, child, other} {
+				want := false
+				for _, name := range tt.gone {
+					want = want || name == e.Name
+				}
+				if e.Alive() == want {
+					t.Errorf("%s alive = %v", e.Name, e.Alive())
+				}
+			}
+			// Every destroy hook ran exactly once
+			counts := map[string]int{}
+			for _, entry := range log {
+				counts[entry]++
+			}
+			for entry, n := range counts {
+				if n != 1 {
+					t.Errorf("%s ran %d times", entry, n)
+				}
+			}
+		})
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	s := NewScene("events")
+	events := 0
+	unsubscribe := s.Subscribe(func(Event) { events++ })
+	s.CreateEntity("a")
+	unsubscribe()
+	unsubscribe() // Twice is harmless
+	s.CreateEntity("b")
+	if events != 1 {
+		t.Errorf("got %d events, want 1", events)
+	}
+}
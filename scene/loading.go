@@ -0,0 +1,448 @@
+package scene
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// PersistentSceneName names the scene holding the entities kept through
+// single loads
+const PersistentSceneName = "DontDestroyOnLoad"
+
+// LoadMode says what becomes of the loaded scenes when another loads
+type LoadMode int
+
+const (
+	// LoadSingle unloads the other scenes, apart from the persistent
+	// scene, and makes the new one active
+	LoadSingle LoadMode = iota
+	// LoadAdditive loads the scene alongside the others. It becomes
+	// active only if no scene was.
+	LoadAdditive
+)
+
+var loadModeNames = [...]string{"single", "additive"}
+
+func (m LoadMode) String() string {
+	if m < 0 || int(m) >= len(loadModeNames) {
+		return "unknown"
+	}
+	return loadModeNames[m]
+}
+
+// SceneEventKind is the kind of transition a SceneEvent reports
+type SceneEventKind int
+
+const (
+	SceneLoaded SceneEventKind = iota
+	SceneUnloaded
+	ActiveSceneChanged
+)
+
+var sceneEventKindNames = [...]string{"scene loaded", "scene unloaded", "active scene changed"}
+
+func (k SceneEventKind) String() string {
+	if k < 0 || int(k) >= len(sceneEventKindNames) {
+		return "unknown"
+	}
+	return sceneEventKindNames[k]
+}
+
+// SceneEvent is a change to the scenes a manager has loaded. A single load
+// sends SceneLoaded for the new scene, then ActiveSceneChanged, then
+// SceneUnloaded for each scene it replaced. SceneUnloaded is sent while the
+// scene's entities can still be inspected; they are removed right after,
+// unless SetActiveScene unloaded the scene, which keeps it in the manager.
+type SceneEvent struct {
+	Kind     SceneEventKind
+	Scene    *Scene
+	Previous *Scene   // The scene that was active, for ActiveSceneChanged
+	Mode     LoadMode // For SceneLoaded
+}
+
+// sceneListeners are the functions subscribed to a manager's transitions
+type sceneListeners struct {
+	mu     sync.Mutex
+	list   []sceneListener
+	nextID int
+}
+
+type sceneListener struct {
+	id     int
+	notify func(SceneEvent)
+}
+
+// Subscribe calls notify for every scene transition until the returned
+// function is called
+func (sm *SceneManager) Subscribe(notify func(event SceneEvent)) func() {
+	sm.listeners.mu.Lock()
+	defer sm.listeners.mu.Unlock()
+
+	sm.listeners.nextID++
+	id := sm.listeners.nextID
+	sm.listeners.list = append(sm.listeners.list, sceneListener{id: id, notify: notify})
+	return func() {
+		sm.listeners.mu.Lock()
+		defer sm.listeners.mu.Unlock()
+		for i, l := range sm.listeners.list {
+			if l.id == id {
+				sm.listeners.list = append(sm.listeners.list[:i:i], sm.listeners.list[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// emit sends an event to the listeners subscribed when it happened
+func (sm *SceneManager) emit(event SceneEvent) {
+	sm.listeners.mu.Lock()
+	list := sm.listeners.list
+	sm.listeners.mu.Unlock()
+
+	for _, l := range list {
+		l.notify(event)
+	}
+}
+
+// LoadedScenes returns the scenes being updated and drawn, in the order
+// they loaded
+func (sm *SceneManager) LoadedScenes() []*Scene {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return append([]*Scene(nil), sm.loaded...)
+}
+
+// IsLoaded reports whether the named scene is loaded
+func (sm *SceneManager) IsLoaded(name string) bool {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	scene, exists := sm.scenes[name]
+	return exists && sm.isLoaded(scene)
+}
+
+// OpenScene loads a JSON or binary scene file as mode says
+func (sm *SceneManager) OpenScene(path string, mode LoadMode) (*Scene, error) {
+	scene, err := readSceneFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sm.install(scene, mode)
+	return scene, nil
+}
+
+// UnloadScene removes a scene from the manager along with its entities. If
+// it was active, the scene loaded most recently after it becomes active.
+func (sm *SceneManager) UnloadScene(name string) error {
+	sm.mu.Lock()
+	scene, exists := sm.scenes[name]
+	if !exists {
+		sm.mu.Unlock()
+		return fmt.Errorf("scene '%s' not found", name)
+	}
+
+	previous := sm.activeScene
+	sm.forget(scene)
+	for i, s := range sm.loaded {
+		if s == scene {
+			sm.loaded = append(sm.loaded[:i:i], sm.loaded[i+1:]...)
+			break
+		}
+	}
+	if scene == sm.persistent {
+		sm.persistent = nil
+	}
+	if scene == previous {
+		sm.activeScene = nil
+		for i := len(sm.loaded) - 1; i >= 0; i-- {
+			if sm.loaded[i] != sm.persistent {
+				sm.activate(sm.loaded[i])
+				break
+			}
+		}
+	}
+	active := sm.activeScene
+	sm.mu.Unlock()
+
+	if active != previous {
+		sm.emit(SceneEvent{Kind: ActiveSceneChanged, Scene: active, Previous: previous})
+	}
+	sm.emit(SceneEvent{Kind: SceneUnloaded, Scene: scene})
+	scene.removeAll()
+	return nil
+}
+
+// DontDestroyOnLoad moves an entity and its descendants to the persistent
+// scene, which stays loaded through single loads, and returns the entity's
+// handle there. The moved entities get new handles, as MoveEntity gives
+// them, so EntityIDs held from before the move go stale while the *Entity
+// values stay in use.
+func (sm *SceneManager) DontDestroyOnLoad(entity *Entity) (EntityID, error) {
+	if entity == nil || !entity.Alive() {
+		return 0, fmt.Errorf("entity is not in a scene")
+	}
+	sm.mu.Lock()
+	created := sm.persistent == nil
+	if created {
+		sm.persistent = NewScene(PersistentSceneName)
+		sm.scenes[PersistentSceneName] = sm.persistent
+		sm.loaded = append([]*Scene{sm.persistent}, sm.loaded...)
+	}
+	persistent := sm.persistent
+	sm.mu.Unlock()
+
+	if created {
+		sm.emit(SceneEvent{Kind: SceneLoaded, Scene: persistent, Mode: LoadAdditive})
+	}
+	if err := entity.scene.MoveEntity(entity, persistent); err != nil {
+		return 0, err
+	}
+	return entity.ID, nil
+}
+
+// PersistentScene returns the scene holding the entities kept through
+// single loads, or nil before DontDestroyOnLoad is first used
+func (sm *SceneManager) PersistentScene() *Scene {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.persistent
+}
+
+// SceneLoad is a scene file loading in the background. The file is read
+// and its components decoded in the background; ProcessLoads creates the
+// entities and adds the scene to the manager, so component hooks run on
+// the thread updating the scenes and the loaded scenes never change in the
+// middle of a frame.
+type SceneLoad struct {
+	Path string
+	Mode LoadMode
+
+	size     int64
+	read     atomic.Int64
+	ready    chan struct{} // Closed once the file is decoded
+	document *sceneDocument
+	scene    *Scene
+	err      error
+	finished atomic.Bool
+}
+
+// LoadSceneAsync starts loading a JSON or binary scene file in the
+// background
+func (sm *SceneManager) LoadSceneAsync(path string, mode LoadMode) *SceneLoad {
+	load := &SceneLoad{Path: path, Mode: mode, ready: make(chan struct{})}
+	if info, err := os.Stat(path); err == nil {
+		load.size = info.Size()
+	}
+	go func() {
+		defer close(load.ready)
+		load.document, load.err = decodeSceneFile(path, &load.read)
+	}()
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.loads = append(sm.loads, load)
+	return load
+}
+
+// Progress returns how much of the load is done, from 0 to 1
+func (l *SceneLoad) Progress() float32 {
+	select {
+	case <-l.ready:
+		return 1
+	default:
+	}
+	if l.size <= 0 {
+		return 0
+	}
+	// Decoding the components comes after the last byte is read
+	return min(float32(l.read.Load())/float32(l.size), 0.99)
+}
+
+// Done reports whether the load has finished: the scene is loaded in the
+// manager, or the load failed
+func (l *SceneLoad) Done() bool {
+	return l.finished.Load()
+}
+
+// Err returns why the load failed, once it is done
+func (l *SceneLoad) Err() error {
+	if !l.Done() {
+		return nil
+	}
+	return l.err
+}
+
+// Scene returns the loaded scene, once the load is done
+func (l *SceneLoad) Scene() *Scene {
+	if !l.Done() {
+		return nil
+	}
+	return l.scene
+}
+
+// ProcessLoads adds the scenes built in the background to the manager, in
+// the order their loads started. Update calls it; engines that update
+// scenes themselves call it once a frame.
+func (sm *SceneManager) ProcessLoads() {
+	for {
+		sm.mu.Lock()
+		if len(sm.loads) == 0 {
+			sm.mu.Unlock()
+			return
+		}
+		load := sm.loads[0]
+		select {
+		case <-load.ready:
+		default:
+			sm.mu.Unlock()
+			return
+		}
+		sm.loads = sm.loads[1:]
+		sm.mu.Unlock()
+
+		if load.err == nil {
+			load.scene = NewScene(load.document.Name)
+			if err := load.scene.read(documentStream(load.document)); err != nil {
+				load.scene, load.err = nil, fmt.Errorf("%s: %w", load.Path, err)
+			}
+			load.document = nil
+		}
+		if load.err == nil {
+			sm.install(load.scene, load.Mode)
+		}
+		load.finished.Store(true)
+	}
+}
+
+// install adds a freshly read scene to the loaded scenes as mode says
+func (sm *SceneManager) install(scene *Scene, mode LoadMode) {
+	sm.mu.Lock()
+	previous := sm.activeScene
+	var replaced []*Scene
+	if mode == LoadSingle {
+		kept := sm.kept()
+		for _, s := range sm.loaded {
+			if s != sm.persistent {
+				replaced = append(replaced, s)
+				sm.forget(s)
+			}
+		}
+		sm.loaded = kept
+	}
+	sm.scenes[scene.name] = scene
+	sm.loaded = append(sm.loaded, scene)
+	if mode == LoadSingle || sm.activeScene == nil {
+		sm.activate(scene)
+	}
+	active := sm.activeScene
+	sm.mu.Unlock()
+
+	sm.emit(SceneEvent{Kind: SceneLoaded, Scene: scene, Mode: mode})
+	if active != previous {
+		sm.emit(SceneEvent{Kind: ActiveSceneChanged, Scene: active, Previous: previous})
+	}
+	for _, s := range replaced {
+		sm.emit(SceneEvent{Kind: SceneUnloaded, Scene: s})
+		s.removeAll()
+	}
+}
+
+// kept returns the loaded scenes that survive a single load
+func (sm *SceneManager) kept() []*Scene {
+	if sm.persistent != nil && sm.isLoaded(sm.persistent) {
+		return []*Scene{sm.persistent}
+	}
+	return nil
+}
+
+func (sm *SceneManager) isLoaded(scene *Scene) bool {
+	for _, s := range sm.loaded {
+		if s == scene {
+			return true
+		}
+	}
+	return false
+}
+
+// activate makes a scene the active one
+func (sm *SceneManager) activate(scene *Scene) {
+	if sm.activeScene != nil {
+		sm.activeScene.active = false
+	}
+	sm.activeScene = scene
+	scene.active = true
+}
+
+// forget drops a scene from the manager, unless a scene of the same name
+// has taken its place
+func (sm *SceneManager) forget(scene *Scene) {
+	if sm.scenes[scene.name] == scene {
+		delete(sm.scenes, scene.name)
+	}
+	if sm.activeScene == scene {
+		sm.activeScene = nil
+	}
+	scene.active = false
+}
+
+// removeAll removes every entity from the scene, running their hooks
+func (s *Scene) removeAll() {
+	for _, root := range s.Roots() {
+		s.RemoveEntity(root.ID)
+	}
+}
+
+// decodeSceneFile reads a whole scene file and decodes its components,
+// creating no entities, so that it can run off the thread updating the
+// scenes. It counts the bytes read into read.
+func decodeSceneFile(path string, read *atomic.Int64) (*sceneDocument, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open scene: %w", err)
+	}
+	defer file.Close()
+
+	stream, err := openScene(&countingReader{r: file, n: read})
+	var document *sceneDocument
+	if err == nil {
+		document, err = stream.readAll()
+	}
+	if err == nil {
+		err = document.decodeComponents()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return document, nil
+}
+
+// decodeComponents decodes each component's data, so that loading the
+// document only has to attach them
+func (document *sceneDocument) decodeComponents() error {
+	for i := range document.Entities {
+		entity := &document.Entities[i]
+		for j := range entity.Components {
+			c := &entity.Components[j]
+			component, err := c.decode()
+			if err != nil {
+				return fmt.Errorf("failed to decode %s component of entity '%s': %w", c.Type, entity.Name, err)
+			}
+			c.component = component
+		}
+	}
+	return nil
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n *atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,306 @@
+package scene
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// saveScenes saves a scene with one entity of the same name for each name,
+// returning their paths
+func saveScenes(t *testing.T, names ...string) map[string]string {
+	t.Helper()
+	dir := t.TempDir()
+	paths := make(map[string]string)
+	for _, name := range names {
+		s := NewScene(name)
+		Add(s.CreateEntity(name), NewMeshComponent("cube"))
+		paths[name] = filepath.Join(dir, name+".bifrost")
+		if err := s.SaveFile(paths[name]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return paths
+}
+
+// record logs a manager's transitions as "kind name"
+func record(sm *SceneManager) *[]string {
+	var log []string
+	sm.Subscribe(func(event SceneEvent) {
+		log = append(log, fmt.Sprintf("%s %s", event.Kind, event.Scene.Name()))
+	})
+	return &log
+}
+
+func sceneNames(scenes []*Scene) []string {
+	var names []string
+	for _, s := range scenes {
+		names = append(names, s.Name())
+	}
+	return names
+}
+
+func open(t *testing.T, sm *SceneManager, path string, mode LoadMode) *Scene {
+	t.Helper()
+	s, err := sm.OpenScene(path, mode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestLoadSingle(t *testing.T) {
+	paths := saveScenes(t, "Menu", "Level")
+	sm := NewSceneManager()
+	menu := open(t, sm, paths["Menu"], LoadSingle)
+	player := menu.CreateEntity("Player")
+	if _, err := sm.DontDestroyOnLoad(player); err != nil {
+		t.Fatal(err)
+	}
+	left := menu.Find("Menu")
+	log := record(sm)
+
+	level := open(t, sm, paths["Level"], LoadSingle)
+
+	want := []string{"scene loaded Level", "active scene changed Level", "scene unloaded Menu"}
+	if !reflect.DeepEqual(*log, want) {
+		t.Errorf("events = %q, want %q", *log, want)
+	}
+	if got := sceneNames(sm.LoadedScenes()); !reflect.DeepEqual(got, []string{PersistentSceneName, "Level"}) {
+		t.Errorf("loaded scenes = %v", got)
+	}
+	if sm.GetActiveScene() != level || sm.IsLoaded("Menu") {
+		t.Error("menu still loaded or active")
+	}
+	if left.Alive() {
+		t.Error("menu entity survived the single load")
+	}
+	if !player.Alive() || sm.PersistentScene().Find("Player") != player {
+		t.Error("persistent entity not kept in the persistent scene")
+	}
+}
+
+func TestLoadAdditive(t *testing.T) {
+	paths := saveScenes(t, "World", "Town", "Dungeon")
+	sm := NewSceneManager()
+	world := open(t, sm, paths["World"], LoadAdditive)
+	town := open(t, sm, paths["Town"], LoadAdditive)
+	dungeon := open(t, sm, paths["Dungeon"], LoadAdditive)
+	if sm.GetActiveScene() != world {
+		t.Fatalf("active scene = %v, want the first loaded", sm.GetActiveScene().Name())
+	}
+	if got := sceneNames(sm.LoadedScenes()); !reflect.DeepEqual(got, []string{"World", "Town", "Dungeon"}) {
+		t.Errorf("loaded scenes = %v", got)
+	}
+
+	// Unloading the active scene activates the one loaded last
+	log := record(sm)
+	entity := world.Find("World")
+	if err := sm.UnloadScene("World"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"active scene changed Dungeon", "scene unloaded World"}
+	if !reflect.DeepEqual(*log, want) {
+		t.Errorf("events = %q, want %q", *log, want)
+	}
+	if sm.GetActiveScene() != dungeon || entity.Alive() {
+		t.Error("unloaded scene still active or holding entities")
+	}
+
+	// Unloading another scene leaves the active one be
+	*log = nil
+	if err := sm.UnloadScene("Town"); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*log, []string{"scene unloaded Town"}) || sm.GetActiveScene() != dungeon {
+		t.Errorf("events = %q, active %s", *log, sm.GetActiveScene().Name())
+	}
+	if town.Find("Town") != nil {
+		t.Error("unloaded scene kept its entities")
+	}
+	if err := sm.UnloadScene("Town"); err == nil {
+		t.Error("unloaded a scene twice")
+	}
+}
+
+func TestDontDestroyOnLoadHandles(t *testing.T) {
+	sm := NewSceneManager()
+	level := sm.CreateScene("Level")
+	// Fill slots so the persistent scene hands out different handles
+	for i := 0; i < 3; i++ {
+		level.CreateEntity("filler")
+	}
+	player := level.CreateEntity("Player")
+	hat := level.CreateEntity("Hat")
+	if err := level.SetParent(hat, player); err != nil {
+		t.Fatal(err)
+	}
+	oldPlayer, oldHat := player.ID, hat.ID
+
+	id, err := sm.DontDestroyOnLoad(player)
+	if err != nil {
+		t.Fatal(err)
+	}
+	persistent := sm.PersistentScene()
+	if id != player.ID || persistent.GetEntity(id) != player {
+		t.Errorf("returned handle %v does not find the player, whose handle is %v", id, player.ID)
+	}
+	if id == oldPlayer || hat.ID == oldHat {
+		t.Error("moved entities kept handles from the level")
+	}
+	if persistent.GetEntity(hat.ID) != hat || hat.Parent() != player {
+		t.Error("child not moved along with its handle")
+	}
+	if level.GetEntity(oldPlayer) != nil || level.GetEntity(oldHat) != nil || persistent.GetEntity(oldPlayer) == player {
+		t.Error("old handles still find the moved entities")
+	}
+
+	if _, err := sm.DontDestroyOnLoad(nil); err == nil {
+		t.Error("kept a nil entity")
+	}
+}
+
+func TestUnloadPersistentScene(t *testing.T) {
+	paths := saveScenes(t, "Level")
+	sm := NewSceneManager()
+	level := open(t, sm, paths["Level"], LoadSingle)
+	if _, err := sm.DontDestroyOnLoad(level.CreateEntity("Music")); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.UnloadScene(PersistentSceneName); err != nil {
+		t.Fatal(err)
+	}
+	if sm.PersistentScene() != nil || sm.GetActiveScene() != level {
+		t.Error("persistent scene not unloaded cleanly")
+	}
+	if got := sceneNames(sm.LoadedScenes()); !reflect.DeepEqual(got, []string{"Level"}) {
+		t.Errorf("loaded scenes = %v", got)
+	}
+}
+
+func TestSetActiveScene(t *testing.T) {
+	sm := NewSceneManager()
+	a := sm.CreateScene("A")
+	b := sm.CreateScene("B")
+	entity := a.CreateEntity("e")
+	if err := sm.SetActiveScene("A"); err != nil {
+		t.Fatal(err)
+	}
+	log := record(sm)
+
+	if err := sm.SetActiveScene("B"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"scene loaded B", "active scene changed B", "scene unloaded A"}
+	if !reflect.DeepEqual(*log, want) {
+		t.Errorf("events = %q, want %q", *log, want)
+	}
+	if sm.IsLoaded("A") || !sm.IsLoaded("B") || sm.GetActiveScene() != b {
+		t.Error("scenes not swapped")
+	}
+	// The replaced scene stays held, entities and all
+	if !entity.Alive() {
+		t.Error("held scene lost its entities")
+	}
+	if err := sm.SetActiveScene("A"); err != nil || sm.GetActiveScene() != a {
+		t.Errorf("reactivating A: %v", err)
+	}
+	if err := sm.SetActiveScene("C"); err == nil {
+		t.Error("activated a scene the manager does not hold")
+	}
+}
+
+// addCounter counts OnAdd calls
+type addCounter struct{}
+
+var addCalls atomic.Int32
+
+func (c *addCounter) GetType() string          { return "AddCounter" }
+func (c *addCounter) Update(deltaTime float32) {}
+func (c *addCounter) OnAdd(entity *Entity)     { addCalls.Add(1) }
+
+// wait blocks until a load has read its file
+func wait(t *testing.T, load *SceneLoad) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for load.Progress() < 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("load did not finish reading")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLoadSceneAsync(t *testing.T) {
+	RegisterComponent("AddCounter", func() Component { return &addCounter{} })
+	paths := saveScenes(t, "Menu")
+	s := NewScene("Level")
+	for i := 0; i < 3; i++ {
+		s.CreateEntity(fmt.Sprint(i)).AddComponent(&addCounter{})
+	}
+	dir := t.TempDir()
+	for _, name := range []string{"level.bifrost", "level" + BinaryExtension} {
+		paths[name] = filepath.Join(dir, name)
+		if err := s.SaveFile(paths[name]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{"level.bifrost", "level" + BinaryExtension} {
+		t.Run(name, func(t *testing.T) {
+			sm := NewSceneManager()
+			open(t, sm, paths["Menu"], LoadSingle)
+			log := record(sm)
+			addCalls.Store(0)
+
+			load := sm.LoadSceneAsync(paths[name], LoadSingle)
+			wait(t, load)
+			if load.Done() || load.Scene() != nil {
+				t.Error("load done before ProcessLoads")
+			}
+			if n := addCalls.Load(); n != 0 {
+				t.Errorf("%d hooks ran in the background", n)
+			}
+
+			sm.ProcessLoads()
+			if !load.Done() || load.Err() != nil {
+				t.Fatalf("load done %v, error %v", load.Done(), load.Err())
+			}
+			if n := addCalls.Load(); n != 3 {
+				t.Errorf("%d hooks ran in ProcessLoads, want 3", n)
+			}
+			if level := load.Scene(); level == nil || sm.GetActiveScene() != level || len(level.GetEntities()) != 3 {
+				t.Error("loaded scene not active")
+			}
+			want := []string{"scene loaded Level", "active scene changed Level", "scene unloaded Menu"}
+			if !reflect.DeepEqual(*log, want) {
+				t.Errorf("events = %q, want %q", *log, want)
+			}
+		})
+	}
+}
+
+func TestLoadSceneAsyncOrder(t *testing.T) {
+	paths := saveScenes(t, "A", "B")
+	sm := NewSceneManager()
+	missing := sm.LoadSceneAsync(filepath.Join(t.TempDir(), "missing.bifrost"), LoadAdditive)
+	a := sm.LoadSceneAsync(paths["A"], LoadAdditive)
+	b := sm.LoadSceneAsync(paths["B"], LoadAdditive)
+	wait(t, missing)
+	wait(t, a)
+	wait(t, b)
+	sm.ProcessLoads()
+
+	if !missing.Done() || missing.Err() == nil || missing.Scene() != nil {
+		t.Errorf("missing file: done %v, error %v", missing.Done(), missing.Err())
+	}
+	if got := sceneNames(sm.LoadedScenes()); !reflect.DeepEqual(got, []string{"A", "B"}) {
+		t.Errorf("loaded scenes = %v, want them in the order the loads started", got)
+	}
+	if sm.GetActiveScene() != a.Scene() {
+		t.Error("first additive load not active")
+	}
+}
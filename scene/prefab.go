@@ -0,0 +1,786 @@
+package scene
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A prefab file holds an entity subtree in the form scene files use, its
+// root first:
+//
+//	{"format": "bifrost-prefab", "version": 1, "name": "Tree", "entities": [...]}
+//
+// Instances are plain entities whose root carries a PrefabInstance
+// component. Edits to the prefab reach every instance field that still
+// holds the prefab's value; fields an instance has changed are its
+// overrides and are kept. Only field values propagate: entities and
+// components added to or removed from a prefab show up in new instances.
+
+// prefabFormatName identifies prefab files
+const prefabFormatName = "bifrost-prefab"
+
+// prefabInstanceType is the type name of PrefabInstanceComponent
+const prefabInstanceType = "PrefabInstance"
+
+type prefabDocument struct {
+	Format   string           `json:"format"`
+	Version  int              `json:"version"`
+	Name     string           `json:"name"`
+	Entities []entityDocument `json:"entities"`
+}
+
+// Prefab is a reusable entity subtree
+type Prefab struct {
+	Name string
+	Path string // File the prefab was loaded from or last saved to
+
+	mu        sync.Mutex
+	entities  []entityDocument // Replaced whole, never modified
+	instances []*Entity
+	stamp     fileStamp // Of the file as last read or saved
+}
+
+// fileStamp tells whether a file changed since it was read
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func stampOf(info os.FileInfo) fileStamp {
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}
+}
+
+// PrefabOverride names a field of an instance that differs from its prefab
+type PrefabOverride struct {
+	Path      string `json:"path"`            // Entity below the instance root, "" for the root
+	Component string `json:"component"`       // Component type, or "Transform" or "Entity"
+	Index     int    `json:"index,omitempty"` // Which of the entity's components of that type
+	Field     string `json:"field"`           // Empty when the component data is not an object
+}
+
+// prefabs caches loaded prefabs by path, so instances share them. A
+// prefab whose file has changed since is read again by LoadPrefab.
+var prefabs = struct {
+	sync.Mutex
+	byPath map[string]*Prefab
+}{byPath: make(map[string]*Prefab)}
+
+// NewPrefab creates a prefab from the subtree under root. If root is itself
+// a prefab instance, the new prefab does not keep the link.
+func NewPrefab(root *Entity) (*Prefab, error) {
+	entities, err := capturePrefab(root)
+	if err != nil {
+		return nil, err
+	}
+	return &Prefab{Name: root.Name, entities: entities}, nil
+}
+
+// LoadPrefab reads a prefab file, or returns the prefab already loaded
+// from path. If the file has changed since, the loaded prefab takes its new
+// contents, reaching its instances as with Update, so call it from the
+// thread that updates their scenes.
+func LoadPrefab(path string) (*Prefab, error) {
+	path = filepath.Clean(path)
+	prefabs.Lock()
+	cached := prefabs.byPath[path]
+	prefabs.Unlock()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if cached != nil {
+			return cached, nil
+		}
+		return nil, fmt.Errorf("failed to open prefab: %w", err)
+	}
+	stamp := stampOf(info)
+	if cached != nil && cached.stampIs(stamp) {
+		return cached, nil
+	}
+
+	document, err := readPrefab(path)
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil {
+		if err := cached.replace(document.Entities); err != nil {
+			return nil, fmt.Errorf("failed to reload prefab '%s': %w", path, err)
+		}
+		cached.mu.Lock()
+		cached.Name = document.Name
+		cached.stamp = stamp
+		cached.mu.Unlock()
+		return cached, nil
+	}
+
+	prefabs.Lock()
+	defer prefabs.Unlock()
+	if p, exists := prefabs.byPath[path]; exists {
+		return p, nil
+	}
+	p := &Prefab{Name: document.Name, Path: path, entities: document.Entities, stamp: stamp}
+	prefabs.byPath[path] = p
+	return p, nil
+}
+
+// UnloadPrefab drops the prefab loaded from path, so the next LoadPrefab
+// reads the file again. Its instances keep the prefab they link to.
+func UnloadPrefab(path string) {
+	path = filepath.Clean(path)
+	prefabs.Lock()
+	defer prefabs.Unlock()
+	delete(prefabs.byPath, path)
+}
+
+func readPrefab(path string) (*prefabDocument, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open prefab: %w", err)
+	}
+	var document prefabDocument
+	if err := decodeVersioned(data, prefabFormatName, "prefab", &document); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i, entity := range document.Entities {
+		if (i == 0) != (entity.Parent == 0) {
+			return nil, fmt.Errorf("%s: prefab must have exactly one root entity, first", path)
+		}
+	}
+	if len(document.Entities) == 0 {
+		return nil, fmt.Errorf("%s: prefab has no entities", path)
+	}
+	return &document, nil
+}
+
+func (p *Prefab) stampIs(stamp fileStamp) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stamp.modTime.Equal(stamp.modTime) && p.stamp.size == stamp.size
+}
+
+// Save writes the prefab to a prefab file. Instances and nesting prefabs
+// find it again by this path.
+func (p *Prefab) Save(path string) error {
+	path = filepath.Clean(path)
+	document := prefabDocument{
+		Format:   prefabFormatName,
+		Version:  FormatVersion,
+		Name:     p.Name,
+		Entities: p.snapshot(),
+	}
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode prefab '%s': %w", p.Name, err)
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create prefab directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to save prefab: %w", err)
+	}
+	if info, err := os.Stat(path); err == nil {
+		p.mu.Lock()
+		p.stamp = stampOf(info)
+		p.mu.Unlock()
+	}
+
+	prefabs.Lock()
+	defer prefabs.Unlock()
+	if prefabs.byPath[p.Path] == p {
+		delete(prefabs.byPath, p.Path)
+	}
+	prefabs.byPath[path] = p
+	p.Path = path
+	return nil
+}
+
+// Instances returns the live instances of the prefab, including those
+// nested in other instances
+func (p *Prefab) Instances() []*Entity {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	live := p.instances[:0]
+	for _, instance := range p.instances {
+		if instance.Alive() {
+			live = append(live, instance)
+		}
+	}
+	p.instances = live
+	return append([]*Entity(nil), live...)
+}
+
+// Instantiate creates an instance of the prefab under parent, or at the
+// scene root when parent is nil, and returns its root
+func (s *Scene) Instantiate(p *Prefab, parent *Entity) (*Entity, error) {
+	roots, err := s.load(documentStream(&sceneDocument{Name: p.Name, Entities: p.snapshot()}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to instantiate prefab '%s': %w", p.Name, err)
+	}
+	root := roots[0]
+	if parent != nil {
+		if err := s.SetParentKeepLocal(root, parent); err != nil {
+			s.RemoveEntity(root.ID)
+			return nil, err
+		}
+	}
+	root.AddComponent(&PrefabInstanceComponent{Prefab: p.Path, prefab: p})
+	return root, nil
+}
+
+// Update replaces the prefab's contents with the subtree under root, often
+// an edited instance, and carries each changed field to the instances that
+// have not overridden it. Loaded prefabs that nest this one are updated the
+// same way. Save writes the new contents out.
+func (p *Prefab) Update(root *Entity) error {
+	entities, err := capturePrefab(root)
+	if err != nil {
+		return err
+	}
+	return p.replace(entities)
+}
+
+// replace gives the prefab new contents, carrying each changed field to
+// the instances and nesting prefabs as Update describes
+func (p *Prefab) replace(entities []entityDocument) error {
+	// Nesting prefabs are edited through instances in a scratch scene,
+	// which link to this prefab like any other
+	scratch := NewScene(p.Name)
+	var nesting []*Prefab
+	var scratchRoots []*Entity
+	defer func() {
+		for _, r := range scratchRoots {
+			scratch.RemoveEntity(r.ID)
+		}
+	}()
+	for _, other := range loadedPrefabs() {
+		if other == p || p.Path == "" || !other.nests(p.Path) {
+			continue
+		}
+		instance, err := scratch.Instantiate(other, nil)
+		if err != nil {
+			return err
+		}
+		nesting = append(nesting, other)
+		scratchRoots = append(scratchRoots, instance)
+	}
+
+	// Overrides are what each instance changed from the old contents
+	old := p.snapshot()
+	instances := p.Instances()
+	overridden := make([]map[PrefabOverride]bool, len(instances))
+	for i, instance := range instances {
+		overrides, err := overridesAgainst(instance, old)
+		if err != nil {
+			return err
+		}
+		overridden[i] = overrideSet(overrides)
+	}
+
+	p.mu.Lock()
+	p.entities = entities
+	p.mu.Unlock()
+	for i, instance := range instances {
+		if err := p.sync(instance, overridden[i]); err != nil {
+			return fmt.Errorf("failed to update instance '%s' of prefab '%s': %w", instance.Name, p.Name, err)
+		}
+	}
+
+	for i, other := range nesting {
+		entities, err := capturePrefab(scratchRoots[i])
+		if err != nil {
+			return err
+		}
+		other.mu.Lock()
+		other.entities = entities
+		other.mu.Unlock()
+	}
+	return nil
+}
+
+// PrefabOverrides returns the fields an instance root and its descendants
+// have changed from the prefab
+func PrefabOverrides(instance *Entity) ([]PrefabOverride, error) {
+	c, err := linkedInstance(instance)
+	if err != nil {
+		return nil, err
+	}
+	return overridesAgainst(instance, c.prefab.snapshot())
+}
+
+// RevertPrefabOverride sets an overridden field back to the prefab's value
+func RevertPrefabOverride(instance *Entity, override PrefabOverride) error {
+	c, err := linkedInstance(instance)
+	if err != nil {
+		return err
+	}
+	value, exists := prefabFields(c.prefab.snapshot())[override]
+	if !exists {
+		return fmt.Errorf("prefab '%s' has no field %s", c.prefab.Name, override)
+	}
+	entities, documents, err := captureSubtree(instance)
+	if err != nil {
+		return err
+	}
+	for i, path := range prefabPaths(documents) {
+		if path == override.Path {
+			return applyField(entities[i], override, value)
+		}
+	}
+	return fmt.Errorf("instance '%s' has no entity '%s'", instance.Name, override.Path)
+}
+
+// String returns the override as path:component[index].field
+func (o PrefabOverride) String() string {
+	return fmt.Sprintf("%s:%s[%d].%s", o.Path, o.Component, o.Index, o.Field)
+}
+
+// PrefabInstanceComponent marks the root of a prefab instance. Scene files
+// keep the whole instance along with its overrides, so edits made to the
+// prefab file since reach the instance when it loads. Instances find their
+// prefab by path, so a prefab must be saved before instances of it are
+// saved in scenes or nested in other prefabs.
+type PrefabInstanceComponent struct {
+	Prefab    string           // Path of the prefab file
+	Overrides []PrefabOverride // As of the last save
+
+	prefab    *Prefab
+	err       error // Why the prefab could not be loaded
+	entity    *Entity
+	capturing bool // Capturing the instance marshals this component too
+}
+
+// GetType returns the component type
+func (c *PrefabInstanceComponent) GetType() string {
+	return prefabInstanceType
+}
+
+// Update updates the prefab instance component
+func (c *PrefabInstanceComponent) Update(deltaTime float32) {
+	// Prefab instances only carry data
+}
+
+// Source returns the prefab, or nil if it could not be loaded
+func (c *PrefabInstanceComponent) Source() *Prefab {
+	return c.prefab
+}
+
+// Err returns why the prefab could not be loaded, if it could not
+func (c *PrefabInstanceComponent) Err() error {
+	return c.err
+}
+
+// OnAdd links the instance to its prefab. A prefab that fails to load is
+// reported as a ComponentFailed event, and by the scene file load that
+// added the instance.
+func (c *PrefabInstanceComponent) OnAdd(entity *Entity) {
+	c.entity = entity
+	if c.prefab == nil && c.Prefab != "" {
+		p, err := LoadPrefab(c.Prefab)
+		if err != nil {
+			c.err = fmt.Errorf("prefab instance '%s': %w", entity.Name, err)
+			entity.failed(c, c.err)
+			return
+		}
+		c.prefab = p
+	}
+	if c.prefab != nil {
+		c.prefab.mu.Lock()
+		c.prefab.instances = append(c.prefab.instances, entity)
+		c.prefab.mu.Unlock()
+	}
+}
+
+// OnRemove unlinks the instance from its prefab
+func (c *PrefabInstanceComponent) OnRemove(entity *Entity) {
+	if c.prefab == nil {
+		return
+	}
+	c.prefab.mu.Lock()
+	defer c.prefab.mu.Unlock()
+	for i, instance := range c.prefab.instances {
+		if instance == entity {
+			c.prefab.instances = append(c.prefab.instances[:i:i], c.prefab.instances[i+1:]...)
+			break
+		}
+	}
+}
+
+// OnDestroy unlinks the instance from its prefab
+func (c *PrefabInstanceComponent) OnDestroy(entity *Entity) {
+	c.OnRemove(entity)
+}
+
+// MarshalJSON records the prefab's current path and the instance's
+// current overrides
+func (c *PrefabInstanceComponent) MarshalJSON() ([]byte, error) {
+	type document PrefabInstanceComponent
+	if c.prefab != nil && c.entity != nil && c.entity.Alive() && !c.capturing {
+		c.capturing = true
+		overrides, err := overridesAgainst(c.entity, c.prefab.snapshot())
+		c.capturing = false
+		if err != nil {
+			return nil, err
+		}
+		c.Prefab, c.Overrides = c.prefab.Path, overrides
+	}
+	return json.Marshal((*document)(c))
+}
+
+func linkedInstance(instance *Entity) (*PrefabInstanceComponent, error) {
+	for _, component := range instance.GetComponents(prefabInstanceType) {
+		if c, ok := component.(*PrefabInstanceComponent); ok && c.prefab != nil {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("entity '%s' is not a linked prefab instance", instance.Name)
+}
+
+func (p *Prefab) snapshot() []entityDocument {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.entities
+}
+
+// nests reports whether the prefab holds an instance of the prefab at path
+func (p *Prefab) nests(path string) bool {
+	for _, entity := range p.snapshot() {
+		for _, c := range entity.Components {
+			var instance struct{ Prefab string }
+			if c.Type == prefabInstanceType && json.Unmarshal(c.Data, &instance) == nil && filepath.Clean(instance.Prefab) == path {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func loadedPrefabs() []*Prefab {
+	prefabs.Lock()
+	defer prefabs.Unlock()
+	loaded := make([]*Prefab, 0, len(prefabs.byPath))
+	for _, p := range prefabs.byPath {
+		loaded = append(loaded, p)
+	}
+	return loaded
+}
+
+// sync sets each field of the instance that differs from the prefab to the
+// prefab's value, unless it is overridden
+func (p *Prefab) sync(instance *Entity, overridden map[PrefabOverride]bool) error {
+	entities, current, err := captureSubtree(instance)
+	if err != nil {
+		return err
+	}
+	byPath := make(map[string]*Entity)
+	for i, path := range prefabPaths(current) {
+		byPath[path] = entities[i]
+	}
+
+	have := prefabFields(current)
+	want := prefabFields(p.snapshot())
+	for _, key := range sortedOverrides(want) {
+		value, exists := have[key]
+		if !exists || overridden[key] || bytes.Equal(value, want[key]) {
+			continue
+		}
+		if err := applyField(byPath[key.Path], key, want[key]); err != nil {
+			return fmt.Errorf("failed to set %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// syncPrefabInstances brings freshly loaded instances up to date with their
+// prefabs, outer instances before the ones nested in them
+func syncPrefabInstances(roots []*Entity) error {
+	var err error
+	for _, root := range roots {
+		root.Walk(func(e *Entity) bool {
+			for _, component := range e.GetComponents(prefabInstanceType) {
+				c, ok := component.(*PrefabInstanceComponent)
+				if !ok || err != nil {
+					continue
+				}
+				if c.prefab == nil {
+					err = c.err
+					continue
+				}
+				err = c.prefab.sync(e, overrideSet(c.Overrides))
+			}
+			return err == nil
+		})
+	}
+	return err
+}
+
+// overridesAgainst lists the fields of an instance that differ from a
+// version of its prefab
+func overridesAgainst(instance *Entity, prefab []entityDocument) ([]PrefabOverride, error) {
+	_, current, err := captureSubtree(instance)
+	if err != nil {
+		return nil, err
+	}
+	have := prefabFields(current)
+	base := prefabFields(prefab)
+	var overrides []PrefabOverride
+	for _, key := range sortedOverrides(have) {
+		if value, exists := base[key]; exists && !bytes.Equal(value, have[key]) {
+			overrides = append(overrides, key)
+		}
+	}
+	return overrides, nil
+}
+
+func overrideSet(overrides []PrefabOverride) map[PrefabOverride]bool {
+	set := make(map[PrefabOverride]bool, len(overrides))
+	for _, o := range overrides {
+		set[o] = true
+	}
+	return set
+}
+
+func sortedOverrides(fields map[PrefabOverride]json.RawMessage) []PrefabOverride {
+	keys := make([]PrefabOverride, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Component != b.Component {
+			return a.Component < b.Component
+		}
+		if a.Index != b.Index {
+			return a.Index < b.Index
+		}
+		return a.Field < b.Field
+	})
+	return keys
+}
+
+// captureSubtree documents the entities under root, root first and parents
+// before their children, returning the entities in the same order
+func captureSubtree(root *Entity) ([]*Entity, []entityDocument, error) {
+	var entities []*Entity
+	var documents []entityDocument
+	ids := make(map[*Entity]uint64)
+	var err error
+	root.Walk(func(e *Entity) bool {
+		if err != nil {
+			return false
+		}
+		ids[e] = uint64(len(ids) + 1)
+		var document entityDocument
+		if document, err = entityToDocument(e, ids[e], ids[e.parent]); err != nil {
+			return false
+		}
+		entities = append(entities, e)
+		documents = append(documents, document)
+		return true
+	})
+	return entities, documents, err
+}
+
+// capturePrefab documents a subtree as prefab contents, dropping the root's
+// own instance link
+func capturePrefab(root *Entity) ([]entityDocument, error) {
+	_, documents, err := captureSubtree(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture prefab '%s': %w", root.Name, err)
+	}
+	components := documents[0].Components[:0:0]
+	for _, c := range documents[0].Components {
+		if c.Type != prefabInstanceType {
+			components = append(components, c)
+		}
+	}
+	documents[0].Components = components
+	return documents, nil
+}
+
+// prefabPaths names each entity by its path below the first, which is "".
+// A name repeated among siblings gets its place among them, as in
+// "Wheels/Wheel[1]".
+func prefabPaths(entities []entityDocument) []string {
+	paths := make([]string, len(entities))
+	byID := make(map[uint64]string)
+	seen := make(map[string]int)
+	for i, entity := range entities {
+		if i > 0 {
+			path := entity.Name
+			if parent := byID[entity.Parent]; parent != "" {
+				path = parent + "/" + entity.Name
+			}
+			n := seen[path]
+			seen[path]++
+			if n > 0 {
+				path = fmt.Sprintf("%s[%d]", path, n)
+			}
+			paths[i] = path
+		}
+		byID[entity.ID] = paths[i]
+	}
+	return paths
+}
+
+// prefabFields flattens entities into their overridable fields, each in a
+// canonical JSON form. The root's placement and activity belong to each
+// instance, and instance links are bookkeeping, so neither are fields.
+func prefabFields(entities []entityDocument) map[PrefabOverride]json.RawMessage {
+	fields := make(map[PrefabOverride]json.RawMessage)
+	for i, path := range prefabPaths(entities) {
+		entity := entities[i]
+		if path != "" {
+			t := entity.Transform
+			fields[PrefabOverride{Path: path, Component: "Entity", Field: "active"}] = canonicalJSON(entity.Active)
+			fields[PrefabOverride{Path: path, Component: "Transform", Field: "position"}] = canonicalJSON(t.Position)
+			fields[PrefabOverride{Path: path, Component: "Transform", Field: "rotation"}] = canonicalJSON(t.Rotation)
+			fields[PrefabOverride{Path: path, Component: "Transform", Field: "scale"}] = canonicalJSON(t.Scale)
+		}
+
+		counts := make(map[string]int)
+		for _, c := range entity.Components {
+			if c.Type == prefabInstanceType {
+				continue
+			}
+			key := PrefabOverride{Path: path, Component: c.Type, Index: counts[c.Type]}
+			counts[c.Type]++
+			var object map[string]json.RawMessage
+			if err := json.Unmarshal(c.Data, &object); err != nil || object == nil {
+				fields[key] = canonicalJSON(c.Data)
+				continue
+			}
+			for name, value := range object {
+				key.Field = name
+				fields[key] = canonicalJSON(value)
+			}
+		}
+	}
+	return fields
+}
+
+// canonicalJSON encodes a value so that equal values compare equal as
+// bytes, however the file spelled them
+func canonicalJSON(value any) json.RawMessage {
+	var generic any
+	if raw, ok := value.(json.RawMessage); ok {
+		if err := json.Unmarshal(raw, &generic); err != nil {
+			return raw
+		}
+	} else {
+		generic = value
+	}
+	data, err := json.Marshal(generic)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// applyField sets one field of an entity from its JSON value
+func applyField(entity *Entity, key PrefabOverride, value json.RawMessage) error {
+	switch key.Component {
+	case "Entity":
+		var active bool
+		if err := json.Unmarshal(value, &active); err != nil {
+			return err
+		}
+		entity.SetActive(active)
+		return nil
+	case "Transform":
+		var v [3]float32
+		if err := json.Unmarshal(value, &v); err != nil {
+			return err
+		}
+		switch key.Field {
+		case "position":
+			entity.Transform.SetPosition(vector3(v))
+		case "rotation":
+			entity.Transform.SetRotation(vector3(v))
+		case "scale":
+			entity.Transform.SetScale(vector3(v))
+		}
+		return nil
+	}
+
+	components := entity.GetComponents(key.Component)
+	if key.Index >= len(components) {
+		return fmt.Errorf("entity '%s' has no %s component %d", entity.Name, key.Component, key.Index)
+	}
+	component := components[key.Index]
+	if raw, ok := component.(*RawComponent); ok {
+		if key.Field == "" {
+			raw.Data = value
+			return nil
+		}
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(raw.Data, &object); err != nil {
+			return err
+		}
+		object[key.Field] = value
+		data, err := json.Marshal(object)
+		if err != nil {
+			return err
+		}
+		raw.Data = data
+		return nil
+	}
+
+	data := []byte(value)
+	if key.Field != "" {
+		// Decoding into a map or pointer merges with what it holds, so
+		// start it empty
+		if f := jsonField(component, key.Field); f.IsValid() && f.CanSet() && (f.Kind() == reflect.Map || f.Kind() == reflect.Ptr) {
+			f.Set(reflect.Zero(f.Type()))
+		}
+		var err error
+		if data, err = json.Marshal(map[string]json.RawMessage{key.Field: value}); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(data, component)
+}
+
+// jsonField returns the field of a component that encoding/json decodes an
+// object key into: the one tagged or named as the key, else the first that
+// matches it ignoring case
+func jsonField(component Component, key string) reflect.Value {
+	v := reflect.ValueOf(component)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	v = v.Elem()
+	var folded reflect.Value
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		if name == key {
+			return v.Field(i)
+		}
+		if !folded.IsValid() && strings.EqualFold(name, key) {
+			folded = v.Field(i)
+		}
+	}
+	return folded
+}
@@ -0,0 +1,246 @@
+package scene
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newTree builds a tree entity with a leaf below it, and saves it as a
+// prefab in a temporary directory
+func newTree(t *testing.T, s *Scene) (*Prefab, *Entity) {
+	t.Helper()
+	root := s.CreateEntity("Tree")
+	Add(root, NewMeshComponent("cube"))
+	leaf := s.CreateEntity("Leaf")
+	s.SetParentKeepLocal(leaf, root)
+	Add(leaf, NewMeshComponent("sphere"))
+
+	p, err := NewPrefab(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Save(filepath.Join(t.TempDir(), "tree.prefab")); err != nil {
+		t.Fatal(err)
+	}
+	return p, root
+}
+
+func instantiate(t *testing.T, s *Scene, p *Prefab) *Entity {
+	t.Helper()
+	instance, err := s.Instantiate(p, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return instance
+}
+
+// leafMesh returns the mesh of an instance's leaf
+func leafMesh(instance *Entity) *MeshComponent {
+	mesh, _ := Get[*MeshComponent](instance.Children()[0])
+	return mesh
+}
+
+func TestPrefabUpdate(t *testing.T) {
+	s := NewScene("prefabs")
+	p, source := newTree(t, s)
+	a := instantiate(t, s, p)
+	b := instantiate(t, s, p)
+	if instances := p.Instances(); len(instances) != 2 {
+		t.Fatalf("prefab has %d instances, want 2", len(instances))
+	}
+
+	leafMesh(a).SetColor(1, 0, 0)
+	overrides, err := PrefabOverrides(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	color := PrefabOverride{Path: "Leaf", Component: "Mesh", Field: "Color"}
+	if !reflect.DeepEqual(overrides, []PrefabOverride{color}) {
+		t.Fatalf("overrides = %v, want [%v]", overrides, color)
+	}
+
+	// Edits reach every instance field not overridden
+	sourceLeaf, _ := Get[*MeshComponent](source.Children()[0])
+	sourceLeaf.MeshType = "cone"
+	sourceLeaf.SetColor(0, 1, 0)
+	if err := p.Update(source); err != nil {
+		t.Fatal(err)
+	}
+	if mesh := leafMesh(a); mesh.MeshType != "cone" || mesh.Color != [3]float32{1, 0, 0} {
+		t.Errorf("overriding instance leaf = %v", mesh)
+	}
+	if mesh := leafMesh(b); mesh.MeshType != "cone" || mesh.Color != [3]float32{0, 1, 0} {
+		t.Errorf("instance leaf = %v", mesh)
+	}
+
+	// Overrides survive saving and loading the scene
+	var saved bytes.Buffer
+	if err := s.WriteJSON(&saved); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewScene("prefabs")
+	if err := loaded.ReadJSON(&saved); err != nil {
+		t.Fatal(err)
+	}
+	var reloaded *Entity
+	for _, root := range loaded.Roots() {
+		if c, ok := Get[*PrefabInstanceComponent](root); ok && len(c.Overrides) > 0 {
+			reloaded = root
+		}
+	}
+	if reloaded == nil || leafMesh(reloaded).Color != [3]float32{1, 0, 0} {
+		t.Fatal("override lost through the scene file")
+	}
+	if c, _ := Get[*PrefabInstanceComponent](reloaded); c.Source() != p {
+		t.Error("loaded instance is not linked to the prefab")
+	}
+
+	if err := RevertPrefabOverride(a, color); err != nil {
+		t.Fatal(err)
+	}
+	if mesh := leafMesh(a); mesh.Color != [3]float32{0, 1, 0} {
+		t.Errorf("reverted color = %v", mesh.Color)
+	}
+	if overrides, _ := PrefabOverrides(a); len(overrides) != 0 {
+		t.Errorf("overrides after revert = %v", overrides)
+	}
+}
+
+// statsComponent has fields saved under other names than their own
+type statsComponent struct {
+	Health int             `json:"hp"`
+	Tags   map[string]bool `json:"tags"`
+}
+
+func (c *statsComponent) GetType() string          { return "Stats" }
+func (c *statsComponent) Update(deltaTime float32) {}
+
+func TestPrefabTaggedFields(t *testing.T) {
+	RegisterComponent("Stats", func() Component { return &statsComponent{} })
+	s := NewScene("prefabs")
+	source := s.CreateEntity("Enemy")
+	stats := Add(source, &statsComponent{Health: 10, Tags: map[string]bool{"slow": true}})
+	p, err := NewPrefab(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	instance := instantiate(t, s, p)
+
+	stats.Health = 20
+	stats.Tags = map[string]bool{"fast": true}
+	if err := p.Update(source); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := Get[*statsComponent](instance)
+	if got.Health != 20 || !reflect.DeepEqual(got.Tags, map[string]bool{"fast": true}) {
+		t.Errorf("instance stats = %+v, want the prefab's", got)
+	}
+}
+
+func TestPrefabRawComponents(t *testing.T) {
+	s := NewScene("prefabs")
+	source := s.CreateEntity("Crate")
+	source.AddComponent(&RawComponent{TypeName: "Loot", Data: json.RawMessage(`{"gold":1}`)})
+	source.AddComponent(&RawComponent{TypeName: "Breakable", Data: json.RawMessage(`{"health":3}`)})
+	p, err := NewPrefab(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	instance := instantiate(t, s, p)
+
+	source.GetComponent("Breakable").(*RawComponent).Data = json.RawMessage(`{"health":5}`)
+	if err := p.Update(source); err != nil {
+		t.Fatal(err)
+	}
+	if data := string(instance.GetComponent("Breakable").(*RawComponent).Data); data != `{"health":5}` {
+		t.Errorf("Breakable = %s", data)
+	}
+	if data := string(instance.GetComponent("Loot").(*RawComponent).Data); data != `{"gold":1}` {
+		t.Errorf("Loot = %s", data)
+	}
+}
+
+func TestPrefabMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.prefab")
+	s := NewScene("prefabs")
+	var failed []Event
+	s.Subscribe(func(event Event) {
+		if event.Kind == ComponentFailed {
+			failed = append(failed, event)
+		}
+	})
+
+	e := s.CreateEntity("Ghost")
+	c := Add(e, &PrefabInstanceComponent{Prefab: path})
+	if c.Source() != nil || c.Err() == nil {
+		t.Fatalf("source %v, error %v", c.Source(), c.Err())
+	}
+	if len(failed) != 1 || failed[0].Component != c || failed[0].Err != c.Err() {
+		t.Errorf("failure events = %v", failed)
+	}
+
+	// Loading a scene with the instance fails instead
+	var saved bytes.Buffer
+	if err := s.WriteJSON(&saved); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewScene("prefabs")
+	if err := loaded.ReadJSON(&saved); err == nil {
+		t.Error("scene with a missing prefab loaded")
+	}
+	if entities := loaded.GetEntities(); len(entities) != 0 {
+		t.Errorf("failed load left %d entities", len(entities))
+	}
+}
+
+func TestPrefabReload(t *testing.T) {
+	s := NewScene("prefabs")
+	p, _ := newTree(t, s)
+	if loaded, err := LoadPrefab(p.Path); err != nil || loaded != p {
+		t.Fatalf("LoadPrefab = %v, %v, want the saved prefab", loaded, err)
+	}
+	instance := instantiate(t, s, p)
+	leafMesh(instance).SetColor(1, 0, 0)
+
+	// Another program edits the file
+	data, err := os.ReadFile(p.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = bytes.Replace(data, []byte(`"sphere"`), []byte(`"cone"`), 1)
+	if err := os.WriteFile(p.Path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(p.Path, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadPrefab(p.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded != p {
+		t.Fatal("reloading replaced the prefab its instances link to")
+	}
+	if mesh := leafMesh(instance); mesh.MeshType != "cone" || mesh.Color != [3]float32{1, 0, 0} {
+		t.Errorf("instance leaf after reload = %v", mesh)
+	}
+
+	UnloadPrefab(p.Path)
+	fresh, err := LoadPrefab(p.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fresh == p {
+		t.Error("unloaded prefab still cached")
+	}
+	if c, _ := Get[*PrefabInstanceComponent](instance); c.Source() != p {
+		t.Error("instance lost its prefab when it was unloaded")
+	}
+}
@@ -0,0 +1,208 @@
+package scene
+
+// pool is the sparse set holding every component of one Go type in a
+// scene. Entities and their components of the type sit in dense slices
+// that queries walk directly, and sparse maps an entity ID to its dense
+// index plus one.
+type pool struct {
+	sparse     []int
+	entities   []*Entity
+	components [][]Component
+}
+
+// index returns where an entity's components sit in the dense slices.
+// A stale handle finds the slot's new entity, or nothing.
+func (p *pool) index(id EntityID) (int, bool) {
+	slot := int(id.Index())
+	if slot >= len(p.sparse) || p.sparse[slot] == 0 {
+		return 0, false
+	}
+	i := p.sparse[slot] - 1
+	return i, p.entities[i].ID == id
+}
+
+// set stores an entity's components, replacing those it had
+func (p *pool) set(e *Entity, components []Component) {
+	if i, exists := p.index(e.ID); exists {
+		p.components[i] = components
+		return
+	}
+	slot := int(e.ID.Index())
+	if slot >= len(p.sparse) {
+		sparse := make([]int, max(slot+1, 2*len(p.sparse)))
+		copy(sparse, p.sparse)
+		p.sparse = sparse
+	}
+	p.entities = append(p.entities, e)
+	p.components = append(p.components, components)
+	p.sparse[slot] = len(p.entities)
+}
+
+// remove drops an entity's components, moving the last one into its slot
+func (p *pool) remove(id EntityID) {
+	i, exists := p.index(id)
+	if !exists {
+		return
+	}
+	last := len(p.entities) - 1
+	moved := p.entities[last]
+	p.entities[i] = moved
+	p.components[i] = p.components[last]
+	p.sparse[moved.ID.Index()] = i + 1
+	p.sparse[id.Index()] = 0
+
+	p.entities[last] = nil
+	p.components[last] = nil
+	p.entities = p.entities[:last]
+	p.components = p.components[:last]
+}
+
+// Iter walks the entities of a scene that have a component of type A.
+// Components may be added or removed while iterating; removing the
+// current entity's components never skips another entity.
+type Iter[A Component] struct {
+	pool   *pool
+	i      int
+	entity *Entity
+	list   []Component
+}
+
+// Query returns an iterator over the entities with a component of type A:
+//
+//	q := scene.Query[*MeshComponent](s)
+//	for q.Next() {
+//		entity, mesh := q.Entity(), q.Get()
+//	}
+func Query[A Component](s *Scene) Iter[A] {
+	p := s.poolOf(keyOf[A]())
+	if p == nil {
+		return Iter[A]{}
+	}
+	return Iter[A]{pool: p, i: len(p.entities)}
+}
+
+// Next advances to the next matching entity, reporting false when done
+func (it *Iter[A]) Next() bool {
+	if it.pool == nil {
+		return false
+	}
+	// Walking backwards means a removal only ever moves an entity that
+	// was already visited into the current slot
+	it.i = min(it.i-1, len(it.pool.entities)-1)
+	if it.i < 0 {
+		return false
+	}
+	it.entity = it.pool.entities[it.i]
+	it.list = it.pool.components[it.i]
+	return true
+}
+
+// Entity returns the current entity, whose Transform every query can use
+func (it *Iter[A]) Entity() *Entity {
+	return it.entity
+}
+
+// Get returns the current entity's first component of the type
+func (it *Iter[A]) Get() A {
+	return it.list[0].(A)
+}
+
+// Len returns how many components of the type the current entity has
+func (it *Iter[A]) Len() int {
+	return len(it.list)
+}
+
+// At returns the current entity's i-th component of the type
+func (it *Iter[A]) At(i int) A {
+	return it.list[i].(A)
+}
+
+// Iter2 walks the entities of a scene that have components of both types
+type Iter2[A, B Component] struct {
+	lead    *pool
+	other   *pool
+	swapped bool // The lead pool holds B rather than A
+	i       int
+	entity  *Entity
+	a       A
+	b       B
+}
+
+// Query2 returns an iterator over the entities with components of types A
+// and B. It walks whichever type is rarer and looks up the other.
+func Query2[A, B Component](s *Scene) Iter2[A, B] {
+	pa, pb := s.poolOf(keyOf[A]()), s.poolOf(keyOf[B]())
+	if pa == nil || pb == nil {
+		return Iter2[A, B]{}
+	}
+	if len(pb.entities) < len(pa.entities) {
+		return Iter2[A, B]{lead: pb, other: pa, swapped: true, i: len(pb.entities)}
+	}
+	return Iter2[A, B]{lead: pa, other: pb, i: len(pa.entities)}
+}
+
+// Next advances to the next matching entity, reporting false when done
+func (it *Iter2[A, B]) Next() bool {
+	if it.lead == nil {
+		return false
+	}
+	for {
+		it.i = min(it.i-1, len(it.lead.entities)-1)
+		if it.i < 0 {
+			return false
+		}
+		entity := it.lead.entities[it.i]
+		j, exists := it.other.index(entity.ID)
+		if !exists {
+			continue
+		}
+
+		it.entity = entity
+		if it.swapped {
+			it.a = it.other.components[j][0].(A)
+			it.b = it.lead.components[it.i][0].(B)
+		} else {
+			it.a = it.lead.components[it.i][0].(A)
+			it.b = it.other.components[j][0].(B)
+		}
+		return true
+	}
+}
+
+// Entity returns the current entity
+func (it *Iter2[A, B]) Entity() *Entity {
+	return it.entity
+}
+
+// Get returns the current entity's first component of each type
+func (it *Iter2[A, B]) Get() (A, B) {
+	return it.a, it.b
+}
+
+// Count returns how many entities in a scene have a component of type A
+func Count[A Component](s *Scene) int {
+	if p := s.poolOf(keyOf[A]()); p != nil {
+		return len(p.entities)
+	}
+	return 0
+}
+
+// poolOf returns the pool of a component type, or nil before the scene
+// has had one
+func (s *Scene) poolOf(key componentKey) *pool {
+	s.poolsMu.RLock()
+	defer s.poolsMu.RUnlock()
+	return s.pools[key]
+}
+
+// poolFor returns the pool of a component type, creating it on first use
+func (s *Scene) poolFor(key componentKey) *pool {
+	s.poolsMu.Lock()
+	defer s.poolsMu.Unlock()
+	p := s.pools[key]
+	if p == nil {
+		p = &pool{}
+		s.pools[key] = p
+	}
+	return p
+}
@@ -0,0 +1,357 @@
+package scene
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// newQueryScene fills a scene from a map of entity names to the
+// components they get: m for a mesh, s for a script and c for a camera
+func newQueryScene(entities map[string]string) *Scene {
+	s := NewScene("query")
+	for name, components := range entities {
+		entity := s.CreateEntity(name)
+		for _, c := range components {
+			switch c {
+			case 'm':
+				entity.AddComponent(NewMeshComponent(name))
+			case 's':
+				entity.AddComponent(NewScriptComponent(name))
+			case 'c':
+				entity.AddComponent(NewCameraComponent(45, 0.1, 100, 1))
+			}
+		}
+	}
+	return s
+}
+
+// visit runs a query, reporting each entity it finds by name in order
+func visit[A Component](s *Scene) []string {
+	var names []string
+	for q := Query[A](s); q.Next(); {
+		names = append(names, fmt.Sprintf("%s:%d", q.Entity().Name, q.Len()))
+	}
+	return names
+}
+
+func visit2[A, B Component](s *Scene) []string {
+	var names []string
+	for q := Query2[A, B](s); q.Next(); {
+		names = append(names, q.Entity().Name)
+	}
+	return names
+}
+
+func sorted(names []string) []string {
+	sort.Strings(names)
+	return names
+}
+
+func TestQuery(t *testing.T) {
+	s := newQueryScene(map[string]string{
+		"rock":   "m",
+		"player": "ms",
+		"boss":   "smm",
+		"ghost":  "s",
+		"empty":  "",
+		"gone":   "ms",
+	})
+	s.RemoveEntity(s.Find("gone").ID)
+	Remove[*ScriptComponent](s.Find("ghost"))
+	s.Find("rock").SetActive(false)
+
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{"meshes", visit[*MeshComponent](s), []string{"boss:2", "player:1", "rock:1"}},
+		{"scripts", visit[*ScriptComponent](s), []string{"boss:1", "player:1"}},
+		{"no such type", visit[*CameraComponent](s), nil},
+		{"meshes and scripts", visit2[*MeshComponent, *ScriptComponent](s), []string{"boss", "player"}},
+		{"scripts and meshes", visit2[*ScriptComponent, *MeshComponent](s), []string{"boss", "player"}},
+		{"one type missing", visit2[*MeshComponent, *CameraComponent](s), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sorted(tt.got); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("visited %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if n := Count[*MeshComponent](s); n != 3 {
+		t.Errorf("Count = %d, want 3", n)
+	}
+}
+
+func TestQuery2Components(t *testing.T) {
+	s := newQueryScene(map[string]string{"a": "ms", "b": "s", "c": "m"})
+	for q := Query2[*ScriptComponent, *MeshComponent](s); q.Next(); {
+		script, mesh := q.Get()
+		if script.Name != "a" || mesh.MeshType != "a" {
+			t.Errorf("Get = %q, %q, want the components of a", script.Name, mesh.MeshType)
+		}
+	}
+}
+
+func TestQuery2LeadsWithSmallerPool(t *testing.T) {
+	// Every entity has a mesh, one in four a script
+	entities := make(map[string]string)
+	for i := 0; i < 12; i++ {
+		entities[fmt.Sprint(i)] = "m"
+		if i%4 == 0 {
+			entities[fmt.Sprint(i)] += "s"
+		}
+	}
+	s := newQueryScene(entities)
+	meshes, scripts := s.poolOf(keyOf[*MeshComponent]()), s.poolOf(keyOf[*ScriptComponent]())
+
+	tests := []struct {
+		name    string
+		query   func() (lead *pool, swapped bool)
+		lead    *pool
+		swapped bool
+	}{
+		{"rarer second", func() (*pool, bool) {
+			q := Query2[*MeshComponent, *ScriptComponent](s)
+			return q.lead, q.swapped
+		}, scripts, true},
+		{"rarer first", func() (*pool, bool) {
+			q := Query2[*ScriptComponent, *MeshComponent](s)
+			return q.lead, q.swapped
+		}, scripts, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lead, swapped := tt.query()
+			if lead != tt.lead || swapped != tt.swapped {
+				t.Errorf("lead is the mesh pool: %v, swapped %v; want %v, %v",
+					lead == meshes, swapped, tt.lead == meshes, tt.swapped)
+			}
+		})
+	}
+	if got := sorted(visit2[*MeshComponent, *ScriptComponent](s)); !reflect.DeepEqual(got, []string{"0", "4", "8"}) {
+		t.Errorf("visited %q", got)
+	}
+}
+
+// TestQueryMutation changes the scene while walking it, checking no entity
+// is skipped or visited twice
+func TestQueryMutation(t *testing.T) {
+	const entities = 8
+	tests := []struct {
+		name string
+		// change runs at the n-th entity visited
+		change func(s *Scene, current *Entity, n int)
+		// want is how many entities are visited, each once
+		want int
+		// left is how many entities still match after FlushDestroyed
+		left int
+	}{
+		{"nothing", func(s *Scene, current *Entity, n int) {}, entities, entities},
+		{"remove current components", func(s *Scene, current *Entity, n int) {
+			Remove[*MeshComponent](current)
+			Remove[*ScriptComponent](current)
+		}, entities, 0},
+		{"remove current entity", func(s *Scene, current *Entity, n int) {
+			s.RemoveEntity(current.ID)
+		}, entities, 0},
+		{"destroy current entity", func(s *Scene, current *Entity, n int) {
+			current.Destroy()
+		}, entities, 0},
+		{"remove every other entity", func(s *Scene, current *Entity, n int) {
+			if n%2 == 1 {
+				s.RemoveEntity(current.ID)
+			}
+		}, entities, entities / 2},
+		{"remove a visited entity", func(s *Scene, current *Entity, n int) {
+			// Entities are visited last created first
+			if n == 3 {
+				s.RemoveEntity(s.Find("e5").ID)
+			}
+		}, entities, entities - 1},
+		{"add to current entity", func(s *Scene, current *Entity, n int) {
+			current.AddComponent(NewMeshComponent("extra"))
+		}, entities, entities},
+		{"create an entity", func(s *Scene, current *Entity, n int) {
+			// Entities created mid-walk are not visited
+			if n == 0 {
+				created := s.CreateEntity("new")
+				created.AddComponent(NewMeshComponent("new"))
+				created.AddComponent(NewScriptComponent("new"))
+			}
+		}, entities, entities + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newScene := func() *Scene {
+				s := NewScene("mutation")
+				for i := 0; i < entities; i++ {
+					name := fmt.Sprintf("e%d", i)
+					e := s.CreateEntity(name)
+					e.AddComponent(NewMeshComponent(name))
+					e.AddComponent(NewScriptComponent(name))
+				}
+				return s
+			}
+			check := func(t *testing.T, s *Scene, visited []string) {
+				seen := make(map[string]bool)
+				for _, name := range visited {
+					if seen[name] {
+						t.Errorf("visited %s twice", name)
+					}
+					seen[name] = true
+				}
+				if len(visited) != tt.want {
+					t.Errorf("visited %d entities %q, want %d", len(visited), visited, tt.want)
+				}
+				s.FlushDestroyed()
+				if n := len(visit2[*MeshComponent, *ScriptComponent](s)); n != tt.left {
+					t.Errorf("%d entities left, want %d", n, tt.left)
+				}
+			}
+
+			t.Run("Query", func(t *testing.T) {
+				s := newScene()
+				var visited []string
+				for q := Query[*MeshComponent](s); q.Next(); {
+					visited = append(visited, q.Entity().Name)
+					tt.change(s, q.Entity(), len(visited)-1)
+				}
+				check(t, s, visited)
+			})
+			t.Run("Query2", func(t *testing.T) {
+				s := newScene()
+				var visited []string
+				for q := Query2[*MeshComponent, *ScriptComponent](s); q.Next(); {
+					visited = append(visited, q.Entity().Name)
+					tt.change(s, q.Entity(), len(visited)-1)
+				}
+				check(t, s, visited)
+			})
+		})
+	}
+}
+
+func TestQueryWhilePoolsGrow(t *testing.T) {
+	s := newQueryScene(map[string]string{"rock": "m"})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// Each raw type name gets a pool of its own
+		for i := 0; i < 200; i++ {
+			s.CreateEntity("loaded").AddComponent(&RawComponent{TypeName: fmt.Sprint("Loaded", i)})
+		}
+	}()
+	for i := 0; i < 200; i++ {
+		visit[*MeshComponent](s)
+		Count[*ScriptComponent](s)
+	}
+	wg.Wait()
+}
+
+// benchmarkEntities is the scene size the query benchmarks run at
+const benchmarkEntities = 10000
+
+// newBenchmarkScene fills a scene where every entity has a mesh, one in
+// four a script and one in a hundred a camera
+func newBenchmarkScene() *Scene {
+	s := NewSceneManager().CreateScene("benchmark")
+	for i := 0; i < benchmarkEntities; i++ {
+		entity := s.CreateEntity("entity")
+		entity.AddComponent(NewMeshComponent("cube"))
+		if i%4 == 0 {
+			entity.AddComponent(NewScriptComponent("benchmark"))
+		}
+		if i%100 == 0 {
+			entity.AddComponent(NewCameraComponent(45, 0.1, 100, 16.0/9.0))
+		}
+	}
+	return s
+}
+
+func BenchmarkFilterMeshes(b *testing.B) {
+	s := newBenchmarkScene()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		visible := 0
+		for _, entity := range s.GetEntities() {
+			if mesh, ok := entity.GetComponent("Mesh").(*MeshComponent); ok && mesh.Visible {
+				visible++
+			}
+		}
+	}
+}
+
+func BenchmarkQueryMeshes(b *testing.B) {
+	s := newBenchmarkScene()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		visible := 0
+		meshes := Query[*MeshComponent](s)
+		for meshes.Next() {
+			if meshes.Get().Visible {
+				visible++
+			}
+		}
+	}
+}
+
+func BenchmarkFilterCameras(b *testing.B) {
+	s := newBenchmarkScene()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		found := 0
+		for _, entity := range s.GetEntities() {
+			if _, ok := entity.GetComponent("Camera").(*CameraComponent); ok {
+				found++
+			}
+		}
+	}
+}
+
+func BenchmarkQueryCameras(b *testing.B) {
+	s := newBenchmarkScene()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		found := 0
+		cameras := Query[*CameraComponent](s)
+		for cameras.Next() {
+			found++
+		}
+	}
+}
+
+func BenchmarkFilterMeshScripts(b *testing.B) {
+	s := newBenchmarkScene()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		found := 0
+		for _, entity := range s.GetEntities() {
+			_, hasMesh := entity.GetComponent("Mesh").(*MeshComponent)
+			_, hasScript := entity.GetComponent("Script").(*ScriptComponent)
+			if hasMesh && hasScript {
+				found++
+			}
+		}
+	}
+}
+
+func BenchmarkQuery2MeshScripts(b *testing.B) {
+	s := newBenchmarkScene()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		found := 0
+		q := Query2[*MeshComponent, *ScriptComponent](s)
+		for q.Next() {
+			found++
+		}
+	}
+}
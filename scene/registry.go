@@ -0,0 +1,125 @@
+package scene
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Scene files store each component as its type name and its JSON
+// encoding. The registry maps type names back to Go types when loading, so
+// every component type that is saved must be registered. Components are
+// encoded with encoding/json, so exported fields are saved unless tagged
+// `json:"-"`, and a component can take over with json.Marshaler and
+// json.Unmarshaler.
+var registry = struct {
+	sync.RWMutex
+	factories  map[string]func() Component
+	migrations map[int]Migration
+}{
+	factories:  make(map[string]func() Component),
+	migrations: make(map[int]Migration),
+}
+
+// RegisterComponent makes a component type loadable under the name its
+// GetType returns. The factory returns the value fields are decoded into,
+// so fields missing from a file keep the factory's defaults.
+func RegisterComponent(name string, factory func() Component) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.factories[name] = factory
+}
+
+// RegisteredComponents returns the names of all registered component types
+func RegisteredComponents() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	names := make([]string, 0, len(registry.factories))
+	for name := range registry.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newComponent creates an empty component of a registered type
+func newComponent(name string) (Component, bool) {
+	registry.RLock()
+	factory, exists := registry.factories[name]
+	registry.RUnlock()
+	if !exists {
+		return nil, false
+	}
+	return factory(), true
+}
+
+// Migration upgrades a scene file's document, decoded into generic JSON
+// values, from the version it was registered for to the next one. It must
+// update the document in place, apart from the version field which is set
+// for it.
+type Migration func(document map[string]any) error
+
+// RegisterMigration sets the migration that upgrades files written in
+// version from to version from+1
+func RegisterMigration(from int, migrate Migration) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.migrations[from] = migrate
+}
+
+// migrate brings a generic document up to the current version
+func migrate(document map[string]any) error {
+	version, ok := document["version"].(float64)
+	if !ok || version != float64(int(version)) || version < 1 {
+		return fmt.Errorf("invalid scene format version %v", document["version"])
+	}
+	if int(version) > currentVersion {
+		return fmt.Errorf("scene format version %d is newer than the supported version %d", int(version), currentVersion)
+	}
+
+	for v := int(version); v < currentVersion; v++ {
+		registry.RLock()
+		migration, exists := registry.migrations[v]
+		registry.RUnlock()
+		if !exists {
+			return fmt.Errorf("no migration from scene format version %d", v)
+		}
+		if err := migration(document); err != nil {
+			return fmt.Errorf("failed to migrate scene from version %d: %w", v, err)
+		}
+		document["version"] = v + 1
+	}
+	return nil
+}
+
+// RawComponent holds a component whose type is not registered, so that
+// loading and saving a scene keeps it intact
+type RawComponent struct {
+	TypeName string
+	Data     json.RawMessage
+}
+
+// GetType returns the type name the component was saved under
+func (r *RawComponent) GetType() string {
+	return r.TypeName
+}
+
+// Update updates the raw component
+func (r *RawComponent) Update(deltaTime float32) {
+	// Raw components only carry data
+}
+
+func init() {
+	RegisterComponent("Mesh", func() Component { return NewMeshComponent("") })
+	RegisterComponent("Camera", func() Component { return &CameraComponent{} })
+	RegisterComponent("Light", func() Component { return NewLightComponent("") })
+	RegisterComponent("Script", func() Component { return NewScriptComponent("") })
+	RegisterComponent("Sprite", func() Component { return NewSpriteComponent("") })
+	RegisterComponent("SpriteAnimation", func() Component { return NewSpriteAnimationComponent() })
+	RegisterComponent("Tilemap", func() Component { return NewTilemapComponent("") })
+	RegisterComponent("TileObject", func() Component { return &TileObjectComponent{} })
+	RegisterComponent("Text", func() Component { return NewTextComponent("", 1) })
+	RegisterComponent(prefabInstanceType, func() Component { return &PrefabInstanceComponent{} })
+}
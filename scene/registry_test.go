@@ -0,0 +1,94 @@
+package scene
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// registerTemporarily registers a component type, returning the function
+// that puts back whatever was registered under the name before:
+//
+//	defer registerTemporarily("Weapon", factory)()
+func registerTemporarily(name string, factory func() Component) func() {
+	registry.Lock()
+	previous, existed := registry.factories[name]
+	registry.Unlock()
+	RegisterComponent(name, factory)
+	return func() {
+		registry.Lock()
+		defer registry.Unlock()
+		if existed {
+			registry.factories[name] = previous
+		} else {
+			delete(registry.factories, name)
+		}
+	}
+}
+
+func TestUnknownComponents(t *testing.T) {
+	const file = `{
+  "format": "bifrost-scene",
+  "version": 1,
+  "name": "Unknown",
+  "entities": [
+    {
+      "id": 1,
+      "name": "Crate",
+      "active": true,
+      "transform": {"position": [0, 0, 0], "rotation": [0, 0, 0], "scale": [1, 1, 1]},
+      "components": [
+        {"type": "Loot", "data": {"gold": 5}},
+        {"type": "Mesh", "data": {"MeshType": "cube"}},
+        {"type": "Breakable", "data": {"health": 3}}
+      ]
+    }
+  ]
+}`
+	s := NewScene("Unknown")
+	if err := s.ReadJSON(strings.NewReader(file)); err != nil {
+		t.Fatal(err)
+	}
+	crate := s.Find("Crate")
+	if crate == nil {
+		t.Fatal("crate not loaded")
+	}
+
+	// Each unknown type keeps its own name
+	for _, name := range []string{"Loot", "Breakable"} {
+		raw, ok := crate.GetComponent(name).(*RawComponent)
+		if !ok || raw.TypeName != name {
+			t.Errorf("GetComponent(%q) = %v", name, crate.GetComponent(name))
+		}
+		if got := crate.GetComponents(name); len(got) != 1 {
+			t.Errorf("GetComponents(%q) returned %d components", name, len(got))
+		}
+	}
+
+	crate.RemoveComponent("Loot")
+	if crate.HasComponent("Loot") || !crate.HasComponent("Breakable") || !crate.HasComponent("Mesh") {
+		t.Fatalf("components after removing Loot = %v", crate.AllComponents())
+	}
+
+	var saved bytes.Buffer
+	if err := s.WriteJSON(&saved); err != nil {
+		t.Fatal(err)
+	}
+	var document sceneDocument
+	if err := json.Unmarshal(saved.Bytes(), &document); err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, c := range document.Entities[0].Components {
+		types = append(types, c.Type)
+	}
+	if strings.Join(types, ",") != "Mesh,Breakable" {
+		t.Errorf("saved components %v, want [Mesh Breakable]", types)
+	}
+	var data bytes.Buffer
+	json.Compact(&data, document.Entities[0].Components[1].Data)
+	if data.String() != `{"health":3}` {
+		t.Errorf("saved Breakable data %s", data.String())
+	}
+}
@@ -5,30 +5,59 @@ import (
 	"sync"
 )
 
-// SceneManager manages all scenes in the engine
+// SceneManager manages all scenes in the engine. It holds scenes by name,
+// and of those the loaded ones are updated and drawn together; the active
+// one is where new entities go and gives the environment.
 type SceneManager struct {
 	scenes       map[string]*Scene
 	activeScene  *Scene
+	// loaded are the scenes updated and drawn, in the order they loaded
+	loaded       []*Scene
+	persistent   *Scene
+	loads        []*SceneLoad
+	listeners    sceneListeners
 	mu           sync.RWMutex
 }
 
 // Scene represents a game scene with entities
 type Scene struct {
 	name      string
-	entities  map[uint64]*Entity
+	entities  map[EntityID]*Entity
 	systems   []System
 	active    bool
-	nextID    uint64
+	// generations holds each slot's current generation and free the slots
+	// of removed entities, waiting for reuse
+	generations []uint32
+	free      []uint32
+	pending   []EntityID
+	pendingMu sync.Mutex
+	observers observers
+	environment Environment
+	// pools hold each component type's sparse set for queries. The map
+	// has its own lock, as systems add components while Update holds mu.
+	pools     map[componentKey]*pool
+	poolsMu   sync.RWMutex
 	mu        sync.RWMutex
 }
 
-// Entity represents a game object
+// Entity represents a game object. Set Active with SetActive so components
+// hear about it.
 type Entity struct {
-	ID         uint64
+	ID         EntityID
 	Name       string
 	Active     bool
+	// Components indexes the first of the entity's components of each type
+	// name for the string API; Get, GetAll, Add, Has and Remove look them
+	// up by Go type
 	Components map[string]Component
 	Transform  *Transform
+	typed      map[componentKey][]Component
+	order      []Component
+	scene      *Scene
+	parent     *Entity
+	children   []*Entity
+	// leaving is set while RemoveEntity runs the entity's destroy hooks
+	leaving    bool
 }
 
 // Component interface for all components
@@ -55,34 +84,58 @@ func (sm *SceneManager) CreateScene(name string) *Scene {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 	
-	scene := &Scene{
+	scene := NewScene(name)
+	sm.scenes[name] = scene
+	return scene
+}
+
+// NewScene creates an empty scene that no manager holds, such as one built
+// only to be saved
+func NewScene(name string) *Scene {
+	return &Scene{
 		name:     name,
-		entities: make(map[uint64]*Entity),
+		entities: make(map[EntityID]*Entity),
 		systems:  []System{},
 		active:   false,
-		nextID:   1,
+		environment: DefaultEnvironment(),
+		pools:    make(map[componentKey]*pool),
 	}
-	
-	sm.scenes[name] = scene
-	return scene
 }
 
-// SetActiveScene sets the active scene
+// SetActiveScene sets the active scene. A scene that is not loaded
+// replaces the loaded ones, apart from the persistent scene; they stay in
+// the manager to be activated again, unlike scenes replaced by OpenScene.
 func (sm *SceneManager) SetActiveScene(name string) error {
 	sm.mu.Lock()
-	defer sm.mu.Unlock()
-	
 	scene, exists := sm.scenes[name]
 	if !exists {
+		sm.mu.Unlock()
 		return fmt.Errorf("scene '%s' not found", name)
 	}
 	
-	if sm.activeScene != nil {
-		sm.activeScene.active = false
+	previous := sm.activeScene
+	loaded := sm.isLoaded(scene)
+	var replaced []*Scene
+	if !loaded {
+		for _, s := range sm.loaded {
+			if s != sm.persistent {
+				replaced = append(replaced, s)
+			}
+		}
+		sm.loaded = append(sm.kept(), scene)
 	}
+	sm.activate(scene)
+	sm.mu.Unlock()
 	
-	sm.activeScene = scene
-	scene.active = true
+	if !loaded {
+		sm.emit(SceneEvent{Kind: SceneLoaded, Scene: scene, Mode: LoadSingle})
+	}
+	if scene != previous {
+		sm.emit(SceneEvent{Kind: ActiveSceneChanged, Scene: scene, Previous: previous})
+	}
+	for _, s := range replaced {
+		sm.emit(SceneEvent{Kind: SceneUnloaded, Scene: s})
+	}
 	return nil
 }
 
@@ -93,41 +146,79 @@ func (sm *SceneManager) GetActiveScene() *Scene {
 	return sm.activeScene
 }
 
-// Update updates the active scene
+// Update adds the scenes finished loading in the background, then updates
+// every loaded scene
 func (sm *SceneManager) Update(deltaTime float32) {
-	if sm.activeScene != nil {
-		sm.activeScene.Update(deltaTime)
+	sm.ProcessLoads()
+	for _, scene := range sm.LoadedScenes() {
+		scene.Update(deltaTime)
 	}
 }
 
 // CreateEntity creates a new entity in the scene
 func (s *Scene) CreateEntity(name string) *Entity {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	
 	entity := &Entity{
-		ID:         s.nextID,
+		ID:         s.allocateID(),
 		Name:       name,
 		Active:     true,
 		Components: make(map[string]Component),
 		Transform:  NewTransform(),
+		typed:      make(map[componentKey][]Component),
+		scene:      s,
 	}
+	s.entities[entity.ID] = entity
+	s.mu.Unlock()
 	
-	s.entities[s.nextID] = entity
-	s.nextID++
-	
+	s.notify(Event{Kind: EntityCreated, Entity: entity})
 	return entity
 }
 
-// RemoveEntity removes an entity from the scene
-func (s *Scene) RemoveEntity(id uint64) {
+// RemoveEntity removes an entity and all of its descendants from the scene
+// immediately, invalidating their handles. Use Destroy while iterating.
+func (s *Scene) RemoveEntity(id EntityID) {
+	entity := s.GetEntity(id)
+	if entity == nil || entity.leaving {
+		return
+	}
+	
+	// Hooks and observers see the entities whole, with the scene unlocked.
+	// Entities already on their way out are left to the call removing them.
+	var removed []*Entity
+	entity.Walk(func(e *Entity) bool {
+		if !e.leaving {
+			e.leaving = true
+			removed = append(removed, e)
+		}
+		return true
+	})
+	for _, e := range removed {
+		if e.scene == s {
+			e.destroying()
+		}
+	}
+	
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	delete(s.entities, id)
+	
+	entity.unlink()
+	entity.Transform.SetParent(nil)
+	for _, e := range removed {
+		e.leaving = false
+		if s.entities[e.ID] != e {
+			continue // A hook removed it already
+		}
+		for key := range e.typed {
+			s.poolOf(key).remove(e.ID)
+		}
+		e.scene = nil
+		delete(s.entities, e.ID)
+		s.releaseID(e.ID)
+	}
 }
 
-// GetEntity retrieves an entity by ID
-func (s *Scene) GetEntity(id uint64) *Entity {
+// GetEntity retrieves an entity by ID, or nil once it has been removed
+func (s *Scene) GetEntity(id EntityID) *Entity {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	return s.entities[id]
@@ -159,8 +250,24 @@ func (s *Scene) GetSystems() []System {
 	return s.systems
 }
 
-// Update updates all systems and entities in the scene
+// SetEnvironment sets the scene's skybox and background color
+func (s *Scene) SetEnvironment(environment Environment) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.environment = environment
+}
+
+// GetEnvironment returns the scene's skybox and background color
+func (s *Scene) GetEnvironment() Environment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.environment
+}
+
+// Update updates all systems and entities in the scene, then removes the
+// entities destroyed meanwhile
 func (s *Scene) Update(deltaTime float32) {
+	defer s.FlushDestroyed()
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	
@@ -172,25 +279,54 @@ func (s *Scene) Update(deltaTime float32) {
 	// Update all entity components
 	for _, entity := range s.entities {
 		if entity.Active {
-			for _, component := range entity.Components {
+			for _, component := range entity.AllComponents() {
 				component.Update(deltaTime)
 			}
 		}
 	}
 }
 
-// AddComponent adds a component to the entity
+// AddComponent adds a component to the entity after any others of its type
 func (e *Entity) AddComponent(component Component) {
-	e.Components[component.GetType()] = component
+	e.attach(component)
 }
 
-// GetComponent retrieves a component by type
+// GetComponent retrieves the first component of a type
 func (e *Entity) GetComponent(componentType string) Component {
 	return e.Components[componentType]
 }
 
+// GetComponents retrieves all components of a type in the order they were
+// added
+func (e *Entity) GetComponents(componentType string) []Component {
+	first, exists := e.Components[componentType]
+	if !exists {
+		return nil
+	}
+	return append([]Component(nil), e.typed[keyFor(first)]...)
+}
+
+// AllComponents returns every component of the entity in the order they
+// were added. The slice must not be modified.
+func (e *Entity) AllComponents() []Component {
+	return e.order
+}
+
 // HasComponent checks if entity has a component
 func (e *Entity) HasComponent(componentType string) bool {
 	_, exists := e.Components[componentType]
 	return exists
+}
+
+// RemoveComponent removes all components of a type
+func (e *Entity) RemoveComponent(componentType string) {
+	if first, exists := e.Components[componentType]; exists {
+		e.detachAll(keyFor(first))
+	}
+}
+
+// DetachComponent removes one component instance, reporting whether the
+// entity had it
+func (e *Entity) DetachComponent(component Component) bool {
+	return e.detach(component)
 }
\ No newline at end of file
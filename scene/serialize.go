@@ -0,0 +1,476 @@
+package scene
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+)
+
+// FormatVersion is the version of the scene file format written by this
+// build. Files from older versions are upgraded by registered migrations.
+const FormatVersion = 1
+
+// currentVersion is the version JSON files are migrated to before they are
+// decoded. It is FormatVersion but for tests, which raise it to run
+// migrations.
+var currentVersion = FormatVersion
+
+// formatName identifies scene files
+const formatName = "bifrost-scene"
+
+// A scene file is a JSON document:
+//
+//	{
+//	  "format": "bifrost-scene",
+//	  "version": 1,
+//	  "name": "Level 1",
+//	  "environment": {"skybox": "skies/day", "clearColor": [0.1, 0.1, 0.1, 1]},
+//	  "entities": [
+//	    {
+//	      "id": 1,
+//	      "name": "Player",
+//	      "active": true,
+//	      "transform": {"position": [0, 1, 0], "rotation": [0, 90, 0], "scale": [1, 1, 1]},
+//	      "components": [{"type": "Mesh", "data": {"MeshType": "cube", ...}}]
+//	    },
+//	    {"id": 2, "parent": 1, "name": "Arm", ...}
+//	  ]
+//	}
+//
+// Entity ids are local to the file and only link children to parents, which
+// always come first. Transforms are local to the parent with rotation in
+// degrees. Components keep their order and carry their encoding/json form
+// under data.
+
+type sceneDocument struct {
+	Format      string              `json:"format"`
+	Version     int                 `json:"version"`
+	Name        string              `json:"name"`
+	Environment environmentDocument `json:"environment"`
+	Entities    []entityDocument    `json:"entities"`
+}
+
+type environmentDocument struct {
+	Skybox     string     `json:"skybox,omitempty"`
+	ClearColor [4]float32 `json:"clearColor"`
+}
+
+type entityDocument struct {
+	ID         uint64              `json:"id"`
+	Parent     uint64              `json:"parent,omitempty"`
+	Name       string              `json:"name"`
+	Active     bool                `json:"active"`
+	Transform  transformDocument   `json:"transform"`
+	Components []componentDocument `json:"components,omitempty"`
+}
+
+type transformDocument struct {
+	Position [3]float32 `json:"position"`
+	Rotation [3]float32 `json:"rotation"`
+	Scale    [3]float32 `json:"scale"`
+}
+
+type componentDocument struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+
+	// component is set instead of Data for components read field by field
+	// from a binary scene file
+	component Component
+}
+
+// decode returns the document's component, raw if its type is not
+// registered
+func (c componentDocument) decode() (Component, error) {
+	if c.component != nil {
+		return c.component, nil
+	}
+	component, registered := newComponent(c.Type)
+	if !registered {
+		return &RawComponent{TypeName: c.Type, Data: c.Data}, nil
+	}
+	if err := json.Unmarshal(c.Data, component); err != nil {
+		return nil, err
+	}
+	return component, nil
+}
+
+// Name returns the scene's name
+func (s *Scene) Name() string {
+	return s.name
+}
+
+// WriteJSON writes the scene as a scene file
+func (s *Scene) WriteJSON(w io.Writer) error {
+	document, err := s.document()
+	if err != nil {
+		return err
+	}
+	return writeJSON(document, w)
+}
+
+func writeJSON(document *sceneDocument, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return fmt.Errorf("failed to write scene '%s': %w", document.Name, err)
+	}
+	return nil
+}
+
+// ReadJSON adds the entities of a JSON scene file to the scene and takes
+// on its environment
+func (s *Scene) ReadJSON(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read scene: %w", err)
+	}
+	document, err := decodeDocument(data)
+	if err != nil {
+		return err
+	}
+	return s.read(documentStream(document))
+}
+
+// read adds the entities of a stream to the scene and takes on its
+// environment
+func (s *Scene) read(stream *sceneStream) error {
+	if _, err := s.load(stream); err != nil {
+		return err
+	}
+	s.SetEnvironment(stream.header.Environment.environment())
+	return nil
+}
+
+// SaveFile writes the scene to a scene file, such as a project's .bifrost
+// file. Paths ending in BinaryExtension get the binary format.
+func (s *Scene) SaveFile(path string) error {
+	var buf bytes.Buffer
+	write := s.WriteJSON
+	if filepath.Ext(path) == BinaryExtension {
+		write = s.WriteBinary
+	}
+	if err := write(&buf); err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create scene directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to save scene: %w", err)
+	}
+	return nil
+}
+
+// LoadFile adds the entities of a JSON or binary scene file to the scene
+// and takes on its environment
+func (s *Scene) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open scene: %w", err)
+	}
+	defer file.Close()
+
+	stream, err := openScene(file)
+	if err == nil {
+		err = s.read(stream)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadScene creates a scene from a JSON or binary scene file, named as the
+// file says. The scene is held but not loaded; see OpenScene.
+func (sm *SceneManager) LoadScene(path string) (*Scene, error) {
+	scene, err := readSceneFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.scenes[scene.name] = scene
+	return scene, nil
+}
+
+// readSceneFile creates a scene from a scene file
+func readSceneFile(path string) (*Scene, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open scene: %w", err)
+	}
+	defer file.Close()
+
+	stream, err := openScene(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	scene := NewScene(stream.header.Name)
+	if err := scene.read(stream); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return scene, nil
+}
+
+// decodeDocument parses a scene file, migrating it to the current version
+func decodeDocument(data []byte) (*sceneDocument, error) {
+	var document sceneDocument
+	if err := decodeVersioned(data, formatName, "scene", &document); err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// decodeVersioned checks a file's format, migrates it to the current
+// version and decodes it into document
+func decodeVersioned(data []byte, format, kind string, document any) error {
+	var generic map[string]any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", kind, err)
+	}
+	if generic["format"] != format {
+		return fmt.Errorf("not a %s file", kind)
+	}
+	if generic["version"] != float64(currentVersion) {
+		if err := migrate(generic); err != nil {
+			return err
+		}
+		var err error
+		if data, err = json.Marshal(generic); err != nil {
+			return fmt.Errorf("failed to encode migrated %s: %w", kind, err)
+		}
+	}
+
+	if err := json.Unmarshal(data, document); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", kind, err)
+	}
+	return nil
+}
+
+// document captures the scene, parents before their children
+func (s *Scene) document() (*sceneDocument, error) {
+	environment := s.GetEnvironment()
+	document := &sceneDocument{
+		Format:  formatName,
+		Version: FormatVersion,
+		Name:    s.name,
+		Environment: environmentDocument{
+			Skybox:     environment.Skybox,
+			ClearColor: environment.ClearColor,
+		},
+		Entities: []entityDocument{},
+	}
+
+	ids := make(map[*Entity]uint64)
+	var err error
+	for _, root := range s.Roots() {
+		root.Walk(func(e *Entity) bool {
+			if err != nil {
+				return false
+			}
+			ids[e] = uint64(len(ids) + 1)
+			var entity entityDocument
+			if entity, err = entityToDocument(e, ids[e], ids[e.parent]); err != nil {
+				return false
+			}
+			document.Entities = append(document.Entities, entity)
+			return true
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save scene '%s': %w", s.name, err)
+	}
+	return document, nil
+}
+
+func entityToDocument(e *Entity, id, parent uint64) (entityDocument, error) {
+	t := e.Transform
+	entity := entityDocument{
+		ID:     id,
+		Parent: parent,
+		Name:   e.Name,
+		Active: e.Active,
+		Transform: transformDocument{
+			Position: [3]float32{t.Position.X, t.Position.Y, t.Position.Z},
+			Rotation: [3]float32{t.Rotation.X, t.Rotation.Y, t.Rotation.Z},
+			Scale:    [3]float32{t.Scale.X, t.Scale.Y, t.Scale.Z},
+		},
+	}
+
+	for _, component := range e.order {
+		if raw, ok := component.(*RawComponent); ok {
+			entity.Components = append(entity.Components, componentDocument{Type: raw.TypeName, Data: raw.Data})
+			continue
+		}
+		name := component.GetType()
+		if _, registered := newComponent(name); !registered {
+			return entity, fmt.Errorf("component type '%s' on entity '%s' is not registered", name, e.Name)
+		}
+		data, err := json.Marshal(component)
+		if err != nil {
+			return entity, fmt.Errorf("failed to encode %s component of entity '%s': %w", name, e.Name, err)
+		}
+		entity.Components = append(entity.Components, componentDocument{Type: name, Data: data})
+	}
+	return entity, nil
+}
+
+// loader creates a scene file's entities one at a time, so files streamed
+// from disk never need to be held whole
+type loader struct {
+	scene   *Scene
+	created map[uint64]*Entity
+	roots   []*Entity
+}
+
+func newLoader(s *Scene) *loader {
+	return &loader{scene: s, created: make(map[uint64]*Entity)}
+}
+
+// add creates an entity with its components under its parent
+func (l *loader) add(doc entityDocument) error {
+	if _, exists := l.created[doc.ID]; exists || doc.ID == 0 {
+		return fmt.Errorf("invalid entity id %d", doc.ID)
+	}
+	var parent *Entity
+	if doc.Parent != 0 {
+		if parent = l.created[doc.Parent]; parent == nil {
+			return fmt.Errorf("entity '%s' has parent %d, which does not come before it", doc.Name, doc.Parent)
+		}
+	}
+
+	entity := l.scene.CreateEntity(doc.Name)
+	l.created[doc.ID] = entity
+	if parent != nil {
+		if err := l.scene.SetParentKeepLocal(entity, parent); err != nil {
+			return err
+		}
+	} else {
+		l.roots = append(l.roots, entity)
+	}
+
+	t := doc.Transform
+	entity.Transform.SetPosition(vector3(t.Position))
+	entity.Transform.SetRotation(vector3(t.Rotation))
+	entity.Transform.SetScale(vector3(t.Scale))
+
+	for _, c := range doc.Components {
+		component, err := c.decode()
+		if err != nil {
+			return fmt.Errorf("failed to decode %s component of entity '%s': %w", c.Type, doc.Name, err)
+		}
+		entity.AddComponent(component)
+	}
+	// Components are enabled as they are added, and hear about it here if
+	// the entity was saved inactive
+	entity.SetActive(doc.Active)
+	return nil
+}
+
+// abort removes the entities created so far
+func (l *loader) abort() {
+	for _, root := range l.roots {
+		l.scene.RemoveEntity(root.ID)
+	}
+	l.roots = nil
+}
+
+// load creates the entities of a scene stream in the scene, returning the
+// roots it created. On failure the entities created so far are removed.
+func (s *Scene) load(stream *sceneStream) ([]*Entity, error) {
+	l := newLoader(s)
+	for {
+		doc, ok, err := stream.next()
+		if err == nil && ok {
+			err = l.add(doc)
+		}
+		if err != nil {
+			l.abort()
+			return nil, err
+		}
+		if !ok {
+			if err := syncPrefabInstances(l.roots); err != nil {
+				l.abort()
+				return nil, err
+			}
+			return l.roots, nil
+		}
+	}
+}
+
+// sceneStream is an opened scene file: its header, and its entities to be
+// read in order
+type sceneStream struct {
+	header *sceneDocument // With no entities
+	next   func() (entityDocument, bool, error)
+}
+
+// openScene opens a JSON or binary scene file, telling them apart by the
+// binary format's magic
+func openScene(r io.Reader) (*sceneStream, error) {
+	buffered := bufio.NewReader(r)
+	if magic, err := buffered.Peek(len(binaryMagic)); err == nil && string(magic) == binaryMagic {
+		return openBinary(buffered)
+	}
+	data, err := io.ReadAll(buffered)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scene: %w", err)
+	}
+	document, err := decodeDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	return documentStream(document), nil
+}
+
+// documentStream streams the entities of a decoded document
+func documentStream(document *sceneDocument) *sceneStream {
+	entities := document.Entities
+	header := *document
+	header.Entities = nil
+	return &sceneStream{
+		header: &header,
+		next: func() (entityDocument, bool, error) {
+			if len(entities) == 0 {
+				return entityDocument{}, false, nil
+			}
+			entity := entities[0]
+			entities = entities[1:]
+			return entity, true, nil
+		},
+	}
+}
+
+// readAll collects a stream back into a whole document
+func (stream *sceneStream) readAll() (*sceneDocument, error) {
+	document := *stream.header
+	document.Entities = []entityDocument{}
+	for {
+		entity, ok, err := stream.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return &document, nil
+		}
+		document.Entities = append(document.Entities, entity)
+	}
+}
+
+func (e environmentDocument) environment() Environment {
+	return Environment{Skybox: e.Skybox, ClearColor: e.ClearColor}
+}
+
+func vector3(v [3]float32) bmath.Vector3 {
+	return bmath.NewVector3(v[0], v[1], v[2])
+}
@@ -0,0 +1,238 @@
+package scene
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// withVersion pretends files are migrated to version, with no migrations
+// registered, returning the function that restores the real ones
+func withVersion(version int) func() {
+	registry.Lock()
+	migrations := registry.migrations
+	registry.migrations = make(map[int]Migration)
+	registry.Unlock()
+	previous := currentVersion
+	currentVersion = version
+
+	return func() {
+		registry.Lock()
+		registry.migrations = migrations
+		registry.Unlock()
+		currentVersion = previous
+	}
+}
+
+// versionedScene is a scene file of the given version, holding the
+// version field's JSON as is. Version 1 calls a component's type "kind",
+// and versions before 3 call active "enabled".
+func versionedScene(version string) string {
+	field := `"version": ` + version + `,`
+	if version == "" {
+		field = ""
+	}
+	typeKey := "type"
+	if version == "1" {
+		typeKey = "kind"
+	}
+	return `{
+  "format": "bifrost-scene",
+  ` + field + `
+  "name": "Old",
+  "entities": [
+    {
+      "id": 1,
+      "name": "Crate",
+      "enabled": false,
+      "transform": {"position": [1, 2, 3], "rotation": [0, 0, 0], "scale": [1, 1, 1]},
+      "components": [{"` + typeKey + `": "Mesh", "data": {"MeshType": "crate"}}]
+    }
+  ]
+}`
+}
+
+// registerTestMigrations registers the migrations from version 1 to 3,
+// logging the versions they upgrade from
+func registerTestMigrations(log *[]int) {
+	entities := func(document map[string]any) ([]map[string]any, error) {
+		list, ok := document["entities"].([]any)
+		if !ok {
+			return nil, errors.New("entities is not a list")
+		}
+		var result []map[string]any
+		for _, e := range list {
+			entity, ok := e.(map[string]any)
+			if !ok {
+				return nil, errors.New("entity is not an object")
+			}
+			result = append(result, entity)
+		}
+		return result, nil
+	}
+
+	RegisterMigration(1, func(document map[string]any) error {
+		*log = append(*log, 1)
+		list, err := entities(document)
+		for _, entity := range list {
+			for _, c := range entity["components"].([]any) {
+				component := c.(map[string]any)
+				component["type"] = component["kind"]
+				delete(component, "kind")
+			}
+		}
+		return err
+	})
+	RegisterMigration(2, func(document map[string]any) error {
+		*log = append(*log, 2)
+		list, err := entities(document)
+		for _, entity := range list {
+			entity["active"] = entity["enabled"]
+			delete(entity, "enabled")
+		}
+		return err
+	})
+}
+
+func TestMigrations(t *testing.T) {
+	tests := []struct {
+		version string
+		ran     []int
+	}{
+		{"1", []int{1, 2}},
+		{"2", []int{2}},
+		{"3", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run("version "+tt.version, func(t *testing.T) {
+			defer withVersion(3)()
+			var ran []int
+			registerTestMigrations(&ran)
+
+			file := versionedScene(tt.version)
+			if tt.version == "3" {
+				// Files already at the current version are taken as they are
+				file = strings.Replace(file, `"enabled"`, `"active"`, 1)
+			}
+			s := NewScene("Old")
+			if err := s.ReadJSON(strings.NewReader(file)); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(ran, tt.ran) {
+				t.Errorf("migrations from versions %v ran, want %v", ran, tt.ran)
+			}
+			crate := s.Find("Crate")
+			if crate == nil {
+				t.Fatal("crate not loaded")
+			}
+			if mesh, ok := Get[*MeshComponent](crate); !ok || mesh.MeshType != "crate" {
+				t.Errorf("mesh = %v", crate.AllComponents())
+			}
+			if crate.Active {
+				t.Error("crate saved as disabled loaded active")
+			}
+		})
+	}
+}
+
+func TestMigrationErrors(t *testing.T) {
+	failing := func(document map[string]any) error { return errors.New("broken") }
+	tests := []struct {
+		name    string
+		version string
+		// migrations replaces the test migrations when set
+		migrations map[int]Migration
+		want       string
+	}{
+		{"newer", "4", nil, "scene format version 4 is newer than the supported version 3"},
+		{"missing", "", nil, "invalid scene format version <nil>"},
+		{"fractional", "1.5", nil, "invalid scene format version 1.5"},
+		{"string", `"1"`, nil, "invalid scene format version 1"},
+		{"zero", "0", nil, "invalid scene format version 0"},
+		{"negative", "-2", nil, "invalid scene format version -2"},
+		{"no migration", "1", map[int]Migration{2: failing}, "no migration from scene format version 1"},
+		{"migration fails", "1", map[int]Migration{1: failing}, "failed to migrate scene from version 1: broken"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer withVersion(3)()
+			var ran []int
+			if tt.migrations == nil {
+				registerTestMigrations(&ran)
+			}
+			for from, migration := range tt.migrations {
+				RegisterMigration(from, migration)
+			}
+
+			s := NewScene("Old")
+			err := s.ReadJSON(strings.NewReader(versionedScene(tt.version)))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+			if len(s.GetEntities()) != 0 {
+				t.Error("entities loaded from a file that failed")
+			}
+		})
+	}
+
+	// Without raising the version, anything past FormatVersion is too new
+	s := NewScene("New")
+	err := s.ReadJSON(strings.NewReader(versionedScene(fmt.Sprint(FormatVersion + 1))))
+	if err == nil || !strings.Contains(err.Error(), "newer than the supported version") {
+		t.Errorf("error = %v, want the file reported as too new", err)
+	}
+}
+
+func TestLoadInactiveEntity(t *testing.T) {
+	var log []string
+	defer registerTemporarily("Hooked", func() Component { return &hooked{name: "loaded", log: &log} })()
+
+	s := NewScene("Saved")
+	for _, active := range []bool{true, false} {
+		e := s.CreateEntity(fmt.Sprint(active))
+		e.AddComponent(&hooked{name: "saved", log: new([]string)})
+		e.SetActive(active)
+	}
+
+	tests := []struct {
+		format string
+		write  func(s *Scene, buf *bytes.Buffer) error
+		read   func(s *Scene, buf *bytes.Buffer) error
+	}{
+		{"JSON",
+			func(s *Scene, buf *bytes.Buffer) error { return s.WriteJSON(buf) },
+			func(s *Scene, buf *bytes.Buffer) error { return s.ReadJSON(buf) }},
+		{"binary",
+			func(s *Scene, buf *bytes.Buffer) error { return s.WriteBinary(buf) },
+			func(s *Scene, buf *bytes.Buffer) error { return s.ReadBinary(bytes.NewReader(buf.Bytes())) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.write(s, &buf); err != nil {
+				t.Fatal(err)
+			}
+			loaded := NewScene("Loaded")
+			log = nil
+			if err := tt.read(loaded, &buf); err != nil {
+				t.Fatal(err)
+			}
+
+			if loaded.Find("true") == nil || !loaded.Find("true").Active || loaded.Find("false").Active {
+				t.Fatal("entities did not keep their active state")
+			}
+			// Both entities' components are enabled as they are added, and
+			// the inactive one's are disabled once it is deactivated
+			want := []string{"loaded:OnAdd", "loaded:OnEnable", "loaded:OnAdd", "loaded:OnEnable", "loaded:OnDisable"}
+			if !reflect.DeepEqual(log, want) {
+				t.Errorf("hooks = %q, want %q", log, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package scene
+
+import "reflect"
+
+// componentKey identifies a component by its Go type, so the typed
+// accessors cannot be misspelt the way type name strings can. Raw
+// components all share one Go type, so they are told apart by the name
+// they were saved under as well.
+type componentKey struct {
+	typ  reflect.Type
+	name string // Only set for raw components
+}
+
+// keyOf returns the key of a component type parameter
+func keyOf[T Component]() componentKey {
+	return componentKey{typ: reflect.TypeOf((*T)(nil)).Elem()}
+}
+
+// keyFor returns the key of a component
+func keyFor(component Component) componentKey {
+	if raw, ok := component.(*RawComponent); ok {
+		return componentKey{typ: reflect.TypeOf(raw), name: raw.TypeName}
+	}
+	return componentKey{typ: reflect.TypeOf(component)}
+}
+
+// Add attaches a component to an entity after any others of its type
+func Add[T Component](e *Entity, component T) T {
+	e.AddComponent(component)
+	return component
+}
+
+// Get returns the first of an entity's components of type T
+func Get[T Component](e *Entity) (T, bool) {
+	var component T
+	list := e.typed[keyOf[T]()]
+	if len(list) == 0 {
+		return component, false
+	}
+	component, ok := list[0].(T)
+	return component, ok
+}
+
+// GetAll returns all of an entity's components of type T in the order
+// they were added
+func GetAll[T Component](e *Entity) []T {
+	list := e.typed[keyOf[T]()]
+	if len(list) == 0 {
+		return nil
+	}
+	components := make([]T, len(list))
+	for i, component := range list {
+		components[i] = component.(T)
+	}
+	return components
+}
+
+// Has reports whether an entity has a component of type T
+func Has[T Component](e *Entity) bool {
+	return len(e.typed[keyOf[T]()]) > 0
+}
+
+// Remove detaches all of an entity's components of type T, returning how
+// many there were
+func Remove[T Component](e *Entity) int {
+	return e.detachAll(keyOf[T]())
+}
+
+// attach appends a component to those of its Go type. A type name belongs
+// to one Go type at a time, so components of another type answering to
+// the same name are dropped first.
+func (e *Entity) attach(component Component) {
+	if !e.Alive() {
+		return
+	}
+	key := keyFor(component)
+	name := component.GetType()
+	if previous, exists := e.Components[name]; exists && keyFor(previous) != key {
+		e.detachAll(keyFor(previous))
+	}
+
+	list := append(e.typed[key], component)
+	e.typed[key] = list
+	e.order = append(e.order, component)
+	e.Components[name] = list[0]
+	e.scene.poolFor(key).set(e, list)
+	e.added(component)
+}
+
+// detach removes one component instance, reporting whether the entity
+// had it
+func (e *Entity) detach(component Component) bool {
+	if !e.Alive() {
+		return false
+	}
+	key := keyFor(component)
+	i := indexOf(e.typed[key], component)
+	if i < 0 {
+		return false
+	}
+	e.detachAt(key, i)
+	return true
+}
+
+// detachAll removes every component of a Go type, returning how many
+// there were. Components are removed by position, so value types that
+// cannot be compared are removed like any other.
+func (e *Entity) detachAll(key componentKey) int {
+	count := len(e.typed[key])
+	for n := count; n > 0 && e.Alive() && len(e.typed[key]) > 0; n-- {
+		e.detachAt(key, 0)
+	}
+	return count
+}
+
+// detachAt removes the i-th component of a Go type
+func (e *Entity) detachAt(key componentKey, i int) {
+	component := e.typed[key][i]
+	before := len(e.typed[key])
+	e.removing(component)
+	if !e.Alive() {
+		return
+	}
+	// The hooks may have detached it themselves or changed the list
+	list := e.typed[key]
+	if len(list) != before || !sameInstance(list[i], component) {
+		if i = indexOf(list, component); i < 0 {
+			return
+		}
+	}
+	j := e.orderIndex(key, i)
+
+	// Copy rather than shift in place, since iterators may hold the old
+	// slice
+	list = append(append([]Component(nil), list[:i]...), list[i+1:]...)
+	if j >= 0 {
+		e.order = append(append([]Component(nil), e.order[:j]...), e.order[j+1:]...)
+	}
+
+	name := component.GetType()
+	if len(list) == 0 {
+		delete(e.typed, key)
+		delete(e.Components, name)
+	} else {
+		e.typed[key] = list
+		e.Components[name] = list[0]
+	}
+	if len(list) == 0 {
+		e.scene.poolOf(key).remove(e.ID)
+	} else {
+		e.scene.poolOf(key).set(e, list)
+	}
+	e.scene.notify(Event{Kind: ComponentRemoved, Entity: e, Component: component})
+}
+
+// orderIndex finds the i-th component of a Go type in the entity's
+// insertion order; both lists keep components of a type in the same order
+func (e *Entity) orderIndex(key componentKey, i int) int {
+	for j, component := range e.order {
+		if keyFor(component) != key {
+			continue
+		}
+		if i == 0 {
+			return j
+		}
+		i--
+	}
+	return -1
+}
+
+func indexOf(list []Component, component Component) int {
+	for i, c := range list {
+		if sameInstance(c, component) {
+			return i
+		}
+	}
+	return -1
+}
+
+// sameInstance reports whether two components are the same instance.
+// Pointers compare by identity; value types have no identity, so equal
+// values count as the same, compared deeply when == would panic.
+func sameInstance(a, b Component) bool {
+	key := reflect.TypeOf(a)
+	if key != reflect.TypeOf(b) {
+		return false
+	}
+	if key.Comparable() {
+		return a == b
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,121 @@
+package scene
+
+import "testing"
+
+// pathComponent is a value-type component holding a slice, which makes
+// it impossible to compare with ==
+type pathComponent struct {
+	Points  []float32
+	removed *[]float32 // First point of every removed path
+}
+
+func (p pathComponent) GetType() string          { return "Path" }
+func (p pathComponent) Update(deltaTime float32) {}
+
+func (p pathComponent) OnRemove(entity *Entity) {
+	if p.removed != nil {
+		*p.removed = append(*p.removed, p.Points[0])
+	}
+}
+
+// tagComponent is a comparable value-type component
+type tagComponent struct {
+	Tag string
+}
+
+func (t tagComponent) GetType() string          { return "Tag" }
+func (t tagComponent) Update(deltaTime float32) {}
+
+func TestRemoveValueComponents(t *testing.T) {
+	tests := []struct {
+		name   string
+		remove func(e *Entity) int
+	}{
+		{"RemoveComponent", func(e *Entity) int {
+			n := len(e.GetComponents("Path"))
+			e.RemoveComponent("Path")
+			return n
+		}},
+		{"Remove", func(e *Entity) int { return Remove[pathComponent](e) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScene("values")
+			e := s.CreateEntity("walker")
+			var removed []float32
+			e.AddComponent(pathComponent{Points: []float32{1, 2}, removed: &removed})
+			e.AddComponent(tagComponent{Tag: "npc"})
+			e.AddComponent(pathComponent{Points: []float32{3}, removed: &removed})
+
+			if n := tt.remove(e); n != 2 {
+				t.Errorf("removed %d paths, want 2", n)
+			}
+			if e.HasComponent("Path") || Has[pathComponent](e) {
+				t.Error("paths left on the entity")
+			}
+			if paths := Query[pathComponent](s); paths.Next() {
+				t.Error("paths left in the query pool")
+			}
+			if len(removed) != 2 || removed[0] != 1 || removed[1] != 3 {
+				t.Errorf("OnRemove ran for %v, want [1 3]", removed)
+			}
+			if order := e.AllComponents(); len(order) != 1 || order[0] != (tagComponent{Tag: "npc"}) {
+				t.Errorf("components left = %v", order)
+			}
+		})
+	}
+}
+
+func TestDetachValueComponent(t *testing.T) {
+	s := NewScene("values")
+	e := s.CreateEntity("walker")
+	first := pathComponent{Points: []float32{1}}
+	second := pathComponent{Points: []float32{2}}
+	e.AddComponent(first)
+	e.AddComponent(tagComponent{Tag: "a"})
+	e.AddComponent(second)
+	e.AddComponent(tagComponent{Tag: "b"})
+
+	if !e.DetachComponent(second) {
+		t.Fatal("second path not detached")
+	}
+	if e.DetachComponent(pathComponent{Points: []float32{5}}) {
+		t.Error("detached a path the entity does not have")
+	}
+	if !e.DetachComponent(tagComponent{Tag: "a"}) {
+		t.Error("comparable value not detached")
+	}
+
+	paths := GetAll[pathComponent](e)
+	if len(paths) != 1 || paths[0].Points[0] != 1 {
+		t.Errorf("paths = %v, want the first one", paths)
+	}
+	order := e.AllComponents()
+	if len(order) != 2 || order[1] != (tagComponent{Tag: "b"}) {
+		t.Errorf("order = %v", order)
+	}
+	if p, ok := order[0].(pathComponent); !ok || p.Points[0] != 1 {
+		t.Errorf("order = %v", order)
+	}
+}
+
+func TestDetachPointerIdentity(t *testing.T) {
+	s := NewScene("pointers")
+	e := s.CreateEntity("mesh")
+	a := NewMeshComponent("cube")
+	b := NewMeshComponent("cube")
+	e.AddComponent(a)
+	e.AddComponent(b)
+
+	// Equal contents do not make two pointers the same component
+	if e.DetachComponent(NewMeshComponent("cube")) {
+		t.Error("detached a copy")
+	}
+	if !e.DetachComponent(b) {
+		t.Fatal("b not detached")
+	}
+	if meshes := GetAll[*MeshComponent](e); len(meshes) != 1 || meshes[0] != a {
+		t.Errorf("meshes = %v, want a", meshes)
+	}
+}
@@ -5,6 +5,9 @@ import (
 
 	"github.com/inkyblackness/imgui-go/v4"
 	bmath "github.com/javanhut/BifrostEngine/m/v2/math"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/backend"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/profiler"
+	"github.com/javanhut/BifrostEngine/m/v2/scene"
 )
 
 type SceneObject struct {
@@ -27,11 +30,17 @@ type Editor struct {
 	showStats       bool
 	showToolbar     bool
 	showProjectPanel bool
+	showEnvironment bool
 	cameraPosition  bmath.Vector3
 	fps            float32
 	grid           *Grid
 	projectManager *ProjectManager
 	currentTool    string
+	objectsDrawn   int
+	objectsCulled  int
+	viewMode       backend.ViewMode
+	frameProfile   []profiler.Stat
+	environment    scene.Environment
 }
 
 func NewEditor() *Editor {
@@ -58,10 +67,12 @@ func NewEditor() *Editor {
 		grid:          NewGrid(),
 		projectManager: NewProjectManager(),
 		currentTool:   "select",
+		environment:   scene.DefaultEnvironment(),
 	}
 	
-	// Create default project
+	// Create default project, saved with the editor's objects
 	e.projectManager.CreateProject("Untitled Project")
+	e.projectManager.SetSceneProvider(e.BuildScene)
 	return e
 }
 
@@ -77,7 +88,9 @@ func (e *Editor) Render() {
 				e.showProjectPanel = true
 			}
 			if imgui.MenuItem("Save Project") {
-				e.projectManager.SaveCurrentProject()
+				if err := e.projectManager.SaveCurrentProject(); err != nil {
+					fmt.Printf("Save Project: %v\n", err)
+				}
 			}
 			imgui.EndMenu()
 		}
@@ -92,6 +105,12 @@ func (e *Editor) Render() {
 			if imgui.MenuItem("Cylinder") {
 				e.AddObjectWithType("cylinder")
 			}
+			if imgui.MenuItem("Cone") {
+				e.AddObjectWithType("cone")
+			}
+			if imgui.MenuItem("Torus") {
+				e.AddObjectWithType("torus")
+			}
 			if imgui.MenuItem("Plane") {
 				e.AddObjectWithType("plane")
 			}
@@ -105,6 +124,17 @@ func (e *Editor) Render() {
 			if imgui.MenuItem("Toggle Grid") {
 				e.grid.Visible = !e.grid.Visible
 			}
+			if imgui.BeginMenu("View Mode") {
+				for mode := backend.ViewShaded; mode <= backend.ViewOverdraw; mode++ {
+					if imgui.MenuItemV(mode.String(), "", e.viewMode == mode, true) {
+						e.viewMode = mode
+					}
+				}
+				imgui.EndMenu()
+			}
+			if imgui.MenuItem("Environment") {
+				e.showEnvironment = true
+			}
 			imgui.EndMenu()
 		}
 		
@@ -116,6 +146,10 @@ func (e *Editor) Render() {
 		e.renderProjectPanel()
 	}
 	
+	if e.showEnvironment {
+		e.renderEnvironmentPanel()
+	}
+	
 	// Status window
 	e.renderStatusWindow()
 }
@@ -141,6 +175,27 @@ func (e *Editor) renderProjectPanel() {
 	imgui.End()
 }
 
+func (e *Editor) renderEnvironmentPanel() {
+	if imgui.Begin("Environment") {
+		imgui.Text("Skybox: cubemap face directory or panorama")
+		imgui.InputText("Skybox", &e.environment.Skybox)
+		
+		clearColor := [3]float32{e.environment.ClearColor[0], e.environment.ClearColor[1], e.environment.ClearColor[2]}
+		if imgui.ColorEdit3("Background", &clearColor) {
+			e.environment.ClearColor = [4]float32{clearColor[0], clearColor[1], clearColor[2], 1.0}
+		}
+		
+		if imgui.Button("Clear Skybox") {
+			e.environment.Skybox = ""
+		}
+		imgui.SameLine()
+		if imgui.Button("Close") {
+			e.showEnvironment = false
+		}
+	}
+	imgui.End()
+}
+
 func (e *Editor) renderStatusWindow() {
 	if imgui.Begin("Status") {
 		imgui.Text(fmt.Sprintf("Objects: %d", len(e.sceneObjects)))
@@ -150,6 +205,7 @@ func (e *Editor) renderStatusWindow() {
 			imgui.Text(fmt.Sprintf("Position: (%.1f, %.1f, %.1f)", obj.Position.X, obj.Position.Y, obj.Position.Z))
 		}
 		imgui.Text(fmt.Sprintf("Grid: %v", e.grid.Visible))
+		imgui.Text(fmt.Sprintf("Drawn: %d  Culled: %d", e.objectsDrawn, e.objectsCulled))
 	}
 	imgui.End()
 }
@@ -228,6 +284,71 @@ func (e *Editor) SetSelectedObject(index int) {
 	}
 }
 
+// SetRenderStats records how many objects the renderer drew and culled
+func (e *Editor) SetRenderStats(drawn, culled int) {
+	e.objectsDrawn = drawn
+	e.objectsCulled = culled
+}
+
+func (e *Editor) GetRenderStats() (drawn, culled int) {
+	return e.objectsDrawn, e.objectsCulled
+}
+
+// SetFrameProfile records the profiler timings shown in the stats panel
+func (e *Editor) SetFrameProfile(stats []profiler.Stat) {
+	e.frameProfile = stats
+}
+
+func (e *Editor) GetFrameProfile() []profiler.Stat {
+	return e.frameProfile
+}
+
+// SetViewMode sets how the editor viewport shades the scene
+func (e *Editor) SetViewMode(mode backend.ViewMode) {
+	e.viewMode = mode
+}
+
+// SetEnvironment assigns the skybox and background of the edited scene
+func (e *Editor) SetEnvironment(environment scene.Environment) {
+	e.environment = environment
+}
+
+// GetEnvironment returns the environment assigned in the editor; the
+// application applies it to the scene or renderer
+func (e *Editor) GetEnvironment() scene.Environment {
+	return e.environment
+}
+
+// BuildScene creates a scene holding the editor's objects as mesh entities
+// and its environment, named after the current project
+func (e *Editor) BuildScene() *scene.Scene {
+	name := "Untitled"
+	if project := e.projectManager.GetCurrentProject(); project != nil {
+		name = project.Name
+	}
+	
+	s := scene.NewScene(name)
+	s.SetEnvironment(e.environment)
+	for _, obj := range e.sceneObjects {
+		entity := s.CreateEntity(obj.Name)
+		entity.Transform.SetPosition(obj.Position)
+		entity.Transform.SetRotation(obj.Rotation)
+		entity.Transform.SetScale(obj.Scale)
+		
+		mesh := scene.NewMeshComponent(obj.Type)
+		mesh.Color = obj.Color
+		mesh.Visible = obj.Visible
+		entity.AddComponent(mesh)
+	}
+	return s
+}
+
+// GetViewMode returns the view mode of the editor viewport; the
+// application applies it to the renderer before drawing the scene
+func (e *Editor) GetViewMode() backend.ViewMode {
+	return e.viewMode
+}
+
 func (e *Editor) GetProjectManager() *ProjectManager {
 	return e.projectManager
 }
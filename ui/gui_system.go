@@ -2,9 +2,15 @@ package ui
 
 import (
 	"fmt"
+	"time"
 	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/backend"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/text"
 )
 
+// maxProfileRows limits the profiler timings listed in the stats table
+const maxProfileRows = 10
+
 type GUISystem struct {
 	shader      uint32
 	vao         uint32
@@ -208,14 +214,14 @@ func (gui *GUISystem) renderMenuBar() {
 
 func (gui *GUISystem) renderObjectMenu() {
 	// Dropdown background - wider to accommodate text
-	x, y := float32(85), float32(gui.windowHeight-30-175)
-	width, height := float32(160), float32(175)
+	x, y := float32(85), float32(gui.windowHeight-30-225)
+	width, height := float32(160), float32(225)
 	
 	gui.drawRect(x, y, width, height, [3]float32{0.15, 0.15, 0.15})
 	gui.drawRectOutline(x, y, width, height, [3]float32{0.5, 0.5, 0.5})
 
 	// Menu items
-	items := []string{"Add Cube", "Add Sphere", "Add Cylinder", "Add Plane", "Add Triangle", "Add Pyramid", "Add Light"}
+	items := []string{"Add Cube", "Add Sphere", "Add Cylinder", "Add Cone", "Add Torus", "Add Plane", "Add Triangle", "Add Pyramid", "Add Light"}
 	itemHeight := float32(25)
 
 	for i, item := range items {
@@ -237,6 +243,10 @@ func (gui *GUISystem) renderObjectMenu() {
 					objectType = "sphere"
 				case "Add Cylinder":
 					objectType = "cylinder"
+				case "Add Cone":
+					objectType = "cone"
+				case "Add Torus":
+					objectType = "torus"
 				case "Add Plane":
 					objectType = "plane"
 				case "Add Triangle":
@@ -354,6 +364,16 @@ func (gui *GUISystem) RenderText(x, y float32, text string, scale float32, color
 	gui.renderText(x, y, text, scale, color)
 }
 
+// SetFont changes the font all GUI text is drawn with
+func (gui *GUISystem) SetFont(font *text.Font) {
+	gui.textRenderer.SetFont(font)
+}
+
+// MeasureText returns the size of text drawn with RenderText at a scale
+func (gui *GUISystem) MeasureText(str string, scale float32) (float32, float32) {
+	return gui.textRenderer.MeasureText(str, scale)
+}
+
 func (gui *GUISystem) DrawRect(x, y, width, height float32, color [3]float32) {
 	gui.drawRect(x, y, width, height, color)
 }
@@ -394,14 +414,14 @@ func (gui *GUISystem) closeOtherMenus(except string) {
 
 func (gui *GUISystem) renderViewMenu() {
 	// Dropdown background
-	x, y := float32(195), float32(gui.windowHeight-30-125)
-	width, height := float32(120), float32(125)
+	x, y := float32(195), float32(gui.windowHeight-30-150)
+	width, height := float32(120), float32(150)
 	
 	gui.drawRect(x, y, width, height, [3]float32{0.15, 0.15, 0.15})
 	gui.drawRectOutline(x, y, width, height, [3]float32{0.5, 0.5, 0.5})
 
 	// Menu items
-	items := []string{"Toggle Grid", "Wireframe", "Fullscreen", "Reset Camera", "Stats"}
+	items := []string{"Toggle Grid", "Wireframe", "View Mode", "Fullscreen", "Reset Camera", "Stats"}
 	itemHeight := float32(25)
 
 	for i, item := range items {
@@ -420,7 +440,15 @@ func (gui *GUISystem) renderViewMenu() {
 					grid.Visible = !grid.Visible
 					fmt.Printf("Grid: %v\n", grid.Visible)
 				case "Wireframe":
-					fmt.Println("Wireframe mode not yet implemented")
+					if gui.editor.GetViewMode() == backend.ViewWireframe {
+						gui.editor.SetViewMode(backend.ViewShaded)
+					} else {
+						gui.editor.SetViewMode(backend.ViewWireframe)
+					}
+					fmt.Printf("View mode: %v\n", gui.editor.GetViewMode())
+				case "View Mode":
+					gui.editor.SetViewMode(gui.editor.GetViewMode().Next())
+					fmt.Printf("View mode: %v\n", gui.editor.GetViewMode())
 				case "Fullscreen":
 					fmt.Println("Fullscreen mode not yet implemented")
 				case "Reset Camera":
@@ -479,7 +507,14 @@ func (gui *GUISystem) renderStatsTable() {
 		
 		// Table properties - positioned in bottom-right  
 		tableWidth := float32(280)
-		tableHeight := float32(180) // Increased height to fit all content
+		profile := gui.editor.GetFrameProfile()
+		if len(profile) > maxProfileRows {
+			profile = profile[:maxProfileRows]
+		}
+		tableHeight := float32(222) // Increased height to fit all content
+		if len(profile) > 0 {
+			tableHeight += float32(len(profile)+1)*14 + 4
+		}
 		x := float32(gui.windowWidth) - tableWidth - 10
 		y := float32(gui.windowHeight) - tableHeight - 10
 		
@@ -524,6 +559,30 @@ func (gui *GUISystem) renderStatsTable() {
 		gui.renderText(x+15, currentY, fmt.Sprintf("Y: %.1f°", obj.Rotation.Y), textScale, valueColor)
 		currentY -= rowHeight
 		gui.renderText(x+15, currentY, fmt.Sprintf("Z: %.1f°", obj.Rotation.Z), textScale, valueColor)
+		currentY -= 4
+		
+		// Renderer section
+		drawn, culled := gui.editor.GetRenderStats()
+		currentY -= rowHeight
+		gui.renderText(x+5, currentY, "Renderer:", textScale, labelColor)
+		currentY -= rowHeight
+		gui.renderText(x+15, currentY, fmt.Sprintf("Drawn: %d  Culled: %d", drawn, culled), textScale, valueColor)
+		currentY -= rowHeight
+		gui.renderText(x+15, currentY, fmt.Sprintf("View: %v", gui.editor.GetViewMode()), textScale, valueColor)
+		
+		// Profiler section, averaged over the profiler window
+		if len(profile) > 0 {
+			currentY -= 4
+			currentY -= rowHeight
+			gui.renderText(x+5, currentY, "Frame (avg ms):", textScale, labelColor)
+			for _, stat := range profile {
+				currentY -= rowHeight
+				indent := float32(15 + stat.Depth*10)
+				text := fmt.Sprintf("%v %s: %.2f (max %.2f)", stat.Kind, stat.Name, stat.Milliseconds(),
+					float64(stat.Max)/float64(time.Millisecond))
+				gui.renderText(x+indent, currentY, text, textScale, valueColor)
+			}
+		}
 	}
 }
 
@@ -2,7 +2,9 @@ package ui
 
 import (
 	"fmt"
+	"path/filepath"
 	"time"
+	"github.com/javanhut/BifrostEngine/m/v2/scene"
 )
 
 type Project struct {
@@ -13,10 +15,16 @@ type Project struct {
 	SceneFile    string
 }
 
+// ScenePath returns where the project's scene file is saved
+func (p *Project) ScenePath() string {
+	return filepath.Join(p.Path, p.SceneFile)
+}
+
 type ProjectManager struct {
 	projects       []Project
 	currentProject *Project
 	recentProjects []string
+	sceneProvider  func() *scene.Scene
 }
 
 func NewProjectManager() *ProjectManager {
@@ -92,7 +100,19 @@ func (pm *ProjectManager) SaveCurrentProject() error {
 		return fmt.Errorf("no project is currently loaded")
 	}
 	
+	if pm.sceneProvider == nil {
+		return fmt.Errorf("project '%s' has no scene to save", pm.currentProject.Name)
+	}
+	
+	if err := pm.sceneProvider().SaveFile(pm.currentProject.ScenePath()); err != nil {
+		return fmt.Errorf("failed to save project '%s': %w", pm.currentProject.Name, err)
+	}
 	pm.currentProject.LastModified = time.Now()
-	// TODO: Implement actual file saving
 	return nil
+}
+
+// SetSceneProvider sets the function that builds the scene saved with the
+// current project
+func (pm *ProjectManager) SetSceneProvider(provider func() *scene.Scene) {
+	pm.sceneProvider = provider
 }
\ No newline at end of file
@@ -1,86 +1,39 @@
 package ui
 
 import (
+	"math"
+
 	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/javanhut/BifrostEngine/m/v2/renderer/text"
 )
 
+// emPerScale turns the GUI's text scale, which used to count 8 pixel
+// bitmap cells, into the em size giving about the same capital height
+const emPerScale = 10.0
+
+// printableASCII is rasterized up front when a face is created
+const printableASCII = " !\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~"
+
 type TextRenderer struct {
-	shader  uint32
-	vao     uint32
-	vbo     uint32
-	texture uint32
+	shader uint32
+	vao    uint32
+	vbo    uint32
+	font   *text.Font
+	faces  map[int]*guiFace
 }
 
-// Simple 8x8 bitmap font data for ASCII characters 32-126
-var fontData = map[rune][8]byte{
-	' ': {0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
-	'A': {0x18, 0x3C, 0x66, 0x66, 0x7E, 0x66, 0x66, 0x00},
-	'B': {0x7C, 0x66, 0x66, 0x7C, 0x66, 0x66, 0x7C, 0x00},
-	'C': {0x3C, 0x66, 0x60, 0x60, 0x60, 0x66, 0x3C, 0x00},
-	'D': {0x78, 0x6C, 0x66, 0x66, 0x66, 0x6C, 0x78, 0x00},
-	'E': {0x7E, 0x60, 0x60, 0x78, 0x60, 0x60, 0x7E, 0x00},
-	'F': {0x7E, 0x60, 0x60, 0x78, 0x60, 0x60, 0x60, 0x00},
-	'G': {0x3C, 0x66, 0x60, 0x6E, 0x66, 0x66, 0x3C, 0x00},
-	'H': {0x66, 0x66, 0x66, 0x7E, 0x66, 0x66, 0x66, 0x00},
-	'I': {0x3C, 0x18, 0x18, 0x18, 0x18, 0x18, 0x3C, 0x00},
-	'J': {0x1E, 0x0C, 0x0C, 0x0C, 0x0C, 0x6C, 0x38, 0x00},
-	'K': {0x66, 0x6C, 0x78, 0x70, 0x78, 0x6C, 0x66, 0x00},
-	'L': {0x60, 0x60, 0x60, 0x60, 0x60, 0x60, 0x7E, 0x00},
-	'M': {0x63, 0x77, 0x7F, 0x6B, 0x63, 0x63, 0x63, 0x00},
-	'N': {0x66, 0x76, 0x7E, 0x7E, 0x6E, 0x66, 0x66, 0x00},
-	'O': {0x3C, 0x66, 0x66, 0x66, 0x66, 0x66, 0x3C, 0x00},
-	'P': {0x7C, 0x66, 0x66, 0x7C, 0x60, 0x60, 0x60, 0x00},
-	'Q': {0x3C, 0x66, 0x66, 0x66, 0x66, 0x3C, 0x0E, 0x00},
-	'R': {0x7C, 0x66, 0x66, 0x7C, 0x78, 0x6C, 0x66, 0x00},
-	'S': {0x3C, 0x66, 0x60, 0x3C, 0x06, 0x66, 0x3C, 0x00},
-	'T': {0x7E, 0x18, 0x18, 0x18, 0x18, 0x18, 0x18, 0x00},
-	'U': {0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x3C, 0x00},
-	'V': {0x66, 0x66, 0x66, 0x66, 0x66, 0x3C, 0x18, 0x00},
-	'W': {0x63, 0x63, 0x63, 0x6B, 0x7F, 0x77, 0x63, 0x00},
-	'X': {0x66, 0x66, 0x3C, 0x18, 0x3C, 0x66, 0x66, 0x00},
-	'Y': {0x66, 0x66, 0x66, 0x3C, 0x18, 0x18, 0x18, 0x00},
-	'Z': {0x7E, 0x06, 0x0C, 0x18, 0x30, 0x60, 0x7E, 0x00},
-	'a': {0x00, 0x00, 0x3C, 0x06, 0x3E, 0x66, 0x3E, 0x00},
-	'b': {0x60, 0x60, 0x7C, 0x66, 0x66, 0x66, 0x7C, 0x00},
-	'c': {0x00, 0x00, 0x3C, 0x60, 0x60, 0x60, 0x3C, 0x00},
-	'd': {0x06, 0x06, 0x3E, 0x66, 0x66, 0x66, 0x3E, 0x00},
-	'e': {0x00, 0x00, 0x3C, 0x66, 0x7E, 0x60, 0x3C, 0x00},
-	'f': {0x0E, 0x18, 0x18, 0x3E, 0x18, 0x18, 0x18, 0x00},
-	'g': {0x00, 0x00, 0x3E, 0x66, 0x66, 0x3E, 0x06, 0x7C},
-	'h': {0x60, 0x60, 0x7C, 0x66, 0x66, 0x66, 0x66, 0x00},
-	'i': {0x18, 0x00, 0x38, 0x18, 0x18, 0x18, 0x3C, 0x00},
-	'j': {0x06, 0x00, 0x0E, 0x06, 0x06, 0x06, 0x66, 0x3C},
-	'k': {0x60, 0x60, 0x66, 0x6C, 0x78, 0x6C, 0x66, 0x00},
-	'l': {0x38, 0x18, 0x18, 0x18, 0x18, 0x18, 0x3C, 0x00},
-	'm': {0x00, 0x00, 0x66, 0x7F, 0x7F, 0x6B, 0x63, 0x00},
-	'n': {0x00, 0x00, 0x7C, 0x66, 0x66, 0x66, 0x66, 0x00},
-	'o': {0x00, 0x00, 0x3C, 0x66, 0x66, 0x66, 0x3C, 0x00},
-	'p': {0x00, 0x00, 0x7C, 0x66, 0x66, 0x7C, 0x60, 0x60},
-	'q': {0x00, 0x00, 0x3E, 0x66, 0x66, 0x3E, 0x06, 0x06},
-	'r': {0x00, 0x00, 0x7C, 0x66, 0x60, 0x60, 0x60, 0x00},
-	's': {0x00, 0x00, 0x3E, 0x60, 0x3C, 0x06, 0x7C, 0x00},
-	't': {0x18, 0x18, 0x7E, 0x18, 0x18, 0x18, 0x0E, 0x00},
-	'u': {0x00, 0x00, 0x66, 0x66, 0x66, 0x66, 0x3E, 0x00},
-	'v': {0x00, 0x00, 0x66, 0x66, 0x66, 0x3C, 0x18, 0x00},
-	'w': {0x00, 0x00, 0x63, 0x6B, 0x7F, 0x3E, 0x36, 0x00},
-	'x': {0x00, 0x00, 0x66, 0x3C, 0x18, 0x3C, 0x66, 0x00},
-	'y': {0x00, 0x00, 0x66, 0x66, 0x66, 0x3E, 0x0C, 0x78},
-	'z': {0x00, 0x00, 0x7E, 0x0C, 0x18, 0x30, 0x7E, 0x00},
-	'0': {0x3C, 0x66, 0x6E, 0x76, 0x66, 0x66, 0x3C, 0x00},
-	'1': {0x18, 0x18, 0x38, 0x18, 0x18, 0x18, 0x7E, 0x00},
-	'2': {0x3C, 0x66, 0x06, 0x0C, 0x30, 0x60, 0x7E, 0x00},
-	'3': {0x3C, 0x66, 0x06, 0x1C, 0x06, 0x66, 0x3C, 0x00},
-	'4': {0x06, 0x0E, 0x1E, 0x66, 0x7F, 0x06, 0x06, 0x00},
-	'5': {0x7E, 0x60, 0x7C, 0x06, 0x06, 0x66, 0x3C, 0x00},
-	'6': {0x3C, 0x66, 0x60, 0x7C, 0x66, 0x66, 0x3C, 0x00},
-	'7': {0x7E, 0x66, 0x0C, 0x18, 0x18, 0x18, 0x18, 0x00},
-	'8': {0x3C, 0x66, 0x66, 0x3C, 0x66, 0x66, 0x3C, 0x00},
-	'9': {0x3C, 0x66, 0x66, 0x3E, 0x06, 0x66, 0x3C, 0x00},
-	':': {0x00, 0x00, 0x18, 0x00, 0x00, 0x18, 0x00, 0x00},
+// guiFace is a face at one pixel size with a texture per atlas page
+type guiFace struct {
+	face     *text.Face
+	textures []uint32
+	versions []int
 }
 
 func NewTextRenderer() *TextRenderer {
-	tr := &TextRenderer{}
+	tr := &TextRenderer{
+		font:  text.DefaultFont(),
+		faces: make(map[int]*guiFace),
+	}
 	tr.setupShaders()
 	tr.setupBuffers()
 	return tr
@@ -90,25 +43,28 @@ func (tr *TextRenderer) setupShaders() {
 	vertexShader := `
 #version 330 core
 layout (location = 0) in vec2 position;
-layout (location = 1) in vec3 color;
+layout (location = 1) in vec2 texCoord;
 
-out vec3 fragColor;
+out vec2 uv;
 
 uniform mat4 projection;
 
 void main() {
     gl_Position = projection * vec4(position, 0.0, 1.0);
-    fragColor = color;
+    uv = texCoord;
 }
 ` + "\x00"
 
 	fragmentShader := `
 #version 330 core
-in vec3 fragColor;
+in vec2 uv;
 out vec4 FragColor;
 
+uniform sampler2D glyphs;
+uniform vec3 color;
+
 void main() {
-    FragColor = vec4(fragColor, 1.0);
+    FragColor = vec4(color, texture(glyphs, uv).a);
 }
 ` + "\x00"
 
@@ -141,79 +97,146 @@ func (tr *TextRenderer) setupBuffers() {
 	gl.GenBuffers(1, &tr.vbo)
 }
 
-func (tr *TextRenderer) RenderText(text string, x, y float32, scale float32, color [3]float32, projection [16]float32) {
-	gl.UseProgram(tr.shader)
-	gl.BindVertexArray(tr.vao)
-
-	// Set projection matrix
-	projLocation := gl.GetUniformLocation(tr.shader, gl.Str("projection\x00"))
-	gl.UniformMatrix4fv(projLocation, 1, false, &projection[0])
-
-	charX := x
-	charSize := 8.0 * scale
+// SetFont switches to another font, dropping the faces of the old one
+func (tr *TextRenderer) SetFont(font *text.Font) {
+	tr.deleteFaces()
+	tr.font = font
+}
 
-	for _, char := range text {
-		if char == ' ' {
-			charX += charSize
-			continue
-		}
+// getFace returns the face for a text scale, creating it on first use
+func (tr *TextRenderer) getFace(scale float32) *guiFace {
+	size := int(math.Max(1, math.Round(float64(scale*emPerScale))))
+	if gf, exists := tr.faces[size]; exists {
+		return gf
+	}
 
-		bitmap, exists := fontData[char]
-		if !exists {
-			bitmap = fontData['?'] // Use ? for unknown characters
-		}
+	face, err := text.NewFace(tr.font, text.DefaultOptions(float32(size)))
+	if err != nil {
+		return nil
+	}
+	face.Preload(printableASCII)
+	gf := &guiFace{face: face}
+	tr.faces[size] = gf
+	return gf
+}
 
-		tr.renderChar(bitmap, charX, y, scale, color)
-		charX += charSize
+// MeasureText returns the width and height of text drawn at a scale
+func (tr *TextRenderer) MeasureText(str string, scale float32) (float32, float32) {
+	gf := tr.getFace(scale)
+	if gf == nil {
+		return 0, 0
 	}
+	return gf.face.Measure(str, 0)
 }
 
-func (tr *TextRenderer) renderChar(bitmap [8]byte, x, y, scale float32, color [3]float32) {
-	var vertices []float32
-	pixelSize := scale
-
-	// Flip the bitmap vertically by reading rows in reverse order
-	for row := 7; row >= 0; row-- {
-		for col := 0; col < 8; col++ {
-			bit := (bitmap[row] >> (7 - col)) & 1
-			if bit == 1 {
-				// Calculate pixel position
-				px := x + float32(col)*pixelSize
-				py := y + float32(7-row)*pixelSize
-
-				// Add quad vertices (2 triangles)
-				vertices = append(vertices,
-					// Triangle 1
-					px, py, color[0], color[1], color[2],
-					px+pixelSize, py, color[0], color[1], color[2],
-					px, py+pixelSize, color[0], color[1], color[2],
-					// Triangle 2
-					px+pixelSize, py, color[0], color[1], color[2],
-					px+pixelSize, py+pixelSize, color[0], color[1], color[2],
-					px, py+pixelSize, color[0], color[1], color[2],
-				)
+// RenderText draws text with its baseline a pixel scale above y, where the
+// old bitmap font's glyphs sat
+func (tr *TextRenderer) RenderText(str string, x, y float32, scale float32, color [3]float32, projection [16]float32) {
+	gf := tr.getFace(scale)
+	if gf == nil || str == "" {
+		return
+	}
+	layout := gf.face.Layout(str, 0, text.AlignLeft)
+	tr.upload(gf)
+
+	gl.UseProgram(tr.shader)
+	gl.BindVertexArray(tr.vao)
+
+	// Set projection matrix
+	projLocation := gl.GetUniformLocation(tr.shader, gl.Str("projection\x00"))
+	gl.UniformMatrix4fv(projLocation, 1, false, &projection[0])
+	gl.Uniform3f(gl.GetUniformLocation(tr.shader, gl.Str("color\x00")), color[0], color[1], color[2])
+	gl.Uniform1i(gl.GetUniformLocation(tr.shader, gl.Str("glyphs\x00")), 0)
+	gl.ActiveTexture(gl.TEXTURE0)
+
+	// Layout y runs down from the top of the line; the GUI's runs up.
+	// Whole pixel positions keep the glyph masks crisp.
+	left := float32(math.Round(float64(x)))
+	top := float32(math.Round(float64(y+scale))) + gf.face.Ascent()
+	for page, texture := range gf.textures {
+		var vertices []float32
+		size := float32(gf.face.Pages()[page].Image.Bounds().Dx())
+		for _, g := range layout.Glyphs {
+			if g.Page != page {
+				continue
 			}
+			x0 := left + g.X
+			x1 := x0 + float32(g.Source.Dx())
+			y0 := top - g.Y
+			y1 := y0 - float32(g.Source.Dy())
+			u0, u1 := float32(g.Source.Min.X)/size, float32(g.Source.Max.X)/size
+			v0, v1 := float32(g.Source.Min.Y)/size, float32(g.Source.Max.Y)/size
+			vertices = append(vertices,
+				// Triangle 1
+				x0, y1, u0, v1,
+				x1, y1, u1, v1,
+				x0, y0, u0, v0,
+				// Triangle 2
+				x1, y1, u1, v1,
+				x1, y0, u1, v0,
+				x0, y0, u0, v0,
+			)
+		}
+		if len(vertices) == 0 {
+			continue
 		}
-	}
 
-	if len(vertices) > 0 {
+		gl.BindTexture(gl.TEXTURE_2D, texture)
 		gl.BindBuffer(gl.ARRAY_BUFFER, tr.vbo)
 		gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.DYNAMIC_DRAW)
 
 		// Position attribute
-		gl.VertexAttribPointer(0, 2, gl.FLOAT, false, 5*4, gl.PtrOffset(0))
+		gl.VertexAttribPointer(0, 2, gl.FLOAT, false, 4*4, gl.PtrOffset(0))
 		gl.EnableVertexAttribArray(0)
 
-		// Color attribute
-		gl.VertexAttribPointer(1, 3, gl.FLOAT, false, 5*4, gl.PtrOffset(2*4))
+		// Texture coordinate attribute
+		gl.VertexAttribPointer(1, 2, gl.FLOAT, false, 4*4, gl.PtrOffset(2*4))
 		gl.EnableVertexAttribArray(1)
 
-		gl.DrawArrays(gl.TRIANGLES, 0, int32(len(vertices)/5))
+		gl.DrawArrays(gl.TRIANGLES, 0, int32(len(vertices)/4))
+	}
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+}
+
+// upload creates textures for new atlas pages and refreshes changed ones
+func (tr *TextRenderer) upload(gf *guiFace) {
+	for i, page := range gf.face.Pages() {
+		img := page.Image
+		width, height := int32(img.Bounds().Dx()), int32(img.Bounds().Dy())
+		if i == len(gf.textures) {
+			var texture uint32
+			gl.GenTextures(1, &texture)
+			gl.BindTexture(gl.TEXTURE_2D, texture)
+			gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA8, width, height, 0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(img.Pix))
+			gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+			gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+			gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+			gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+			gf.textures = append(gf.textures, texture)
+			gf.versions = append(gf.versions, page.Version)
+			continue
+		}
+		if gf.versions[i] != page.Version {
+			gl.BindTexture(gl.TEXTURE_2D, gf.textures[i])
+			gl.TexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, width, height, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(img.Pix))
+			gf.versions[i] = page.Version
+		}
+	}
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+}
+
+func (tr *TextRenderer) deleteFaces() {
+	for _, gf := range tr.faces {
+		if len(gf.textures) > 0 {
+			gl.DeleteTextures(int32(len(gf.textures)), &gf.textures[0])
+		}
 	}
+	tr.faces = make(map[int]*guiFace)
 }
 
 func (tr *TextRenderer) Cleanup() {
+	tr.deleteFaces()
 	gl.DeleteVertexArrays(1, &tr.vao)
 	gl.DeleteBuffers(1, &tr.vbo)
 	gl.DeleteProgram(tr.shader)
-}
\ No newline at end of file
+}